@@ -0,0 +1,138 @@
+// File: app/authn/authenticator.go
+
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/config"
+)
+
+// Nama backend yang bisa dipakai di AUTH_CHAIN_*
+const (
+	BackendLocal = "local"
+	BackendLDAP  = "ldap"
+)
+
+var (
+	// ErrInvalidCredentials: backend mengenali user tetapi password salah (dihitung sebagai login gagal)
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrUnavailable: backend tidak bisa dihubungi atau salah konfigurasi (tidak dihitung sebagai login gagal)
+	ErrUnavailable = errors.New("authentication backend unavailable")
+)
+
+// Authenticator memverifikasi password untuk user lokal yang sudah ditemukan.
+// Backend eksternal boleh memperbarui atribut user (nama, email) sebagai bagian dari verifikasi.
+// Login SSO (OIDC) berbasis redirect sehingga tidak lewat chain ini, melainkan /auth/oidc/*.
+type Authenticator interface {
+	Name() string
+	Authenticate(ctx context.Context, user *model.User, password string) error
+}
+
+// Chain mencoba authenticator secara berurutan; yang pertama berhasil menang.
+type Chain []Authenticator
+
+// Authenticate mengembalikan nama backend yang berhasil. Bila semua gagal, hasilnya
+// ErrInvalidCredentials bila ada backend yang menolak password, selain itu ErrUnavailable.
+func (chain Chain) Authenticate(ctx context.Context, user *model.User, password string) (string, error) {
+	rejected := false
+	for _, a := range chain {
+		err := a.Authenticate(ctx, user, password)
+		if err == nil {
+			return a.Name(), nil
+		}
+		if errors.Is(err, ErrInvalidCredentials) {
+			rejected = true
+			continue
+		}
+		fmt.Printf("Warning: %s authentication failed for user %s: %v\n", a.Name(), user.ID, err)
+	}
+	if rejected || len(chain) == 0 {
+		return "", ErrInvalidCredentials
+	}
+	return "", ErrUnavailable
+}
+
+// Router memilih chain berdasarkan domain identifier/email lalu role user
+type Router struct {
+	backends map[string]Authenticator
+	fallback Chain
+	byRole   map[string]Chain
+	byDomain map[string]Chain
+}
+
+// NewRouter menyusun chain dari konfigurasi. Nama backend yang tidak dikenal
+// mengembalikan error agar salah konfigurasi ketahuan saat start.
+func NewRouter(cfg config.AuthChainConfig, backends ...Authenticator) (*Router, error) {
+	r := &Router{
+		backends: make(map[string]Authenticator),
+		byRole:   make(map[string]Chain),
+		byDomain: make(map[string]Chain),
+	}
+	for _, b := range backends {
+		r.backends[b.Name()] = b
+	}
+
+	var err error
+	if r.fallback, err = r.build(cfg.Default); err != nil {
+		return nil, fmt.Errorf("AUTH_CHAIN_DEFAULT: %w", err)
+	}
+	if len(r.fallback) == 0 {
+		return nil, errors.New("AUTH_CHAIN_DEFAULT must list at least one backend")
+	}
+	for role, names := range cfg.ByRole {
+		if r.byRole[strings.ToLower(role)], err = r.build(names); err != nil {
+			return nil, fmt.Errorf("AUTH_CHAIN_ROLES (%s): %w", role, err)
+		}
+	}
+	for domain, names := range cfg.ByDomain {
+		if r.byDomain[strings.ToLower(domain)], err = r.build(names); err != nil {
+			return nil, fmt.Errorf("AUTH_CHAIN_DOMAINS (%s): %w", domain, err)
+		}
+	}
+	return r, nil
+}
+
+func (r *Router) build(names []string) (Chain, error) {
+	chain := make(Chain, 0, len(names))
+	for _, name := range names {
+		backend, ok := r.backends[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown authentication backend %q", name)
+		}
+		chain = append(chain, backend)
+	}
+	return chain, nil
+}
+
+// ChainFor: domain identifier ("budi@staff.univ.ac.id") -> domain email user -> role -> default
+func (r *Router) ChainFor(identifier string, user *model.User, roleName string) Chain {
+	for _, candidate := range []string{identifier, user.Email} {
+		if _, domain, found := strings.Cut(candidate, "@"); found {
+			if chain, ok := r.byDomain[strings.ToLower(strings.TrimSpace(domain))]; ok {
+				return chain
+			}
+		}
+	}
+	if chain, ok := r.byRole[strings.ToLower(roleName)]; ok {
+		return chain
+	}
+	return r.fallback
+}
+
+// DirectoryUsername mengembalikan "budi" untuk identifier "budi@staff.univ.ac.id" bila domain
+// tersebut punya aturan chain, sehingga staf bisa login dengan format akun direktori mereka.
+func (r *Router) DirectoryUsername(identifier string) (string, bool) {
+	local, domain, found := strings.Cut(identifier, "@")
+	if !found || local == "" {
+		return "", false
+	}
+	if _, ok := r.byDomain[strings.ToLower(strings.TrimSpace(domain))]; !ok {
+		return "", false
+	}
+	return local, true
+}
@@ -0,0 +1,169 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/config"
+)
+
+// stubAuthenticator mengembalikan err apa adanya dan mencatat apakah ia dipanggil
+type stubAuthenticator struct {
+	name   string
+	err    error
+	called bool
+}
+
+func (s *stubAuthenticator) Name() string { return s.name }
+
+func (s *stubAuthenticator) Authenticate(context.Context, *model.User, string) error {
+	s.called = true
+	return s.err
+}
+
+func chainNames(chain Chain) string {
+	names := make([]string, len(chain))
+	for i, a := range chain {
+		names[i] = a.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func newTestRouter(t *testing.T) *Router {
+	t.Helper()
+	router, err := NewRouter(config.AuthChainConfig{
+		Default: []string{BackendLocal},
+		ByRole: map[string][]string{
+			"Dosen Wali": {BackendLDAP, BackendLocal},
+		},
+		ByDomain: map[string][]string{
+			"staff.univ.ac.id": {BackendLDAP},
+		},
+	}, &stubAuthenticator{name: BackendLocal}, &stubAuthenticator{name: BackendLDAP})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router
+}
+
+func TestRouterChainFor(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		name       string
+		identifier string
+		email      string
+		role       string
+		want       string
+	}{
+		{name: "default", identifier: "2101001", email: "budi@student.univ.ac.id", role: "Mahasiswa", want: "local"},
+		{name: "role rule", identifier: "198001", email: "siti@univ.ac.id", role: "Dosen Wali", want: "ldap,local"},
+		{name: "role rule is case-insensitive", identifier: "198001", email: "", role: "dosen wali", want: "ldap,local"},
+		{name: "identifier domain", identifier: "budi@staff.univ.ac.id", email: "budi@student.univ.ac.id", role: "Mahasiswa", want: "ldap"},
+		{name: "identifier domain is case-insensitive", identifier: "budi@STAFF.Univ.ac.id", email: "", role: "Mahasiswa", want: "ldap"},
+		{name: "user email domain", identifier: "budi", email: "budi@staff.univ.ac.id", role: "Mahasiswa", want: "ldap"},
+		{name: "domain wins over role", identifier: "siti", email: "siti@staff.univ.ac.id", role: "Dosen Wali", want: "ldap"},
+		{name: "unknown domain falls back to role", identifier: "siti@other.ac.id", email: "siti@other.ac.id", role: "Dosen Wali", want: "ldap,local"},
+		{name: "unknown role falls back to default", identifier: "admin", email: "admin@univ.ac.id", role: "Admin", want: "local"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &model.User{Email: tt.email}
+			if got := chainNames(router.ChainFor(tt.identifier, user, tt.role)); got != tt.want {
+				t.Fatalf("ChainFor(%q, %q, %q) = %s, want %s", tt.identifier, tt.email, tt.role, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouterDirectoryUsername(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		identifier string
+		want       string
+		wantOK     bool
+	}{
+		{identifier: "budi@staff.univ.ac.id", want: "budi", wantOK: true},
+		{identifier: "budi@Staff.Univ.AC.ID", want: "budi", wantOK: true},
+		{identifier: "budi@student.univ.ac.id"},
+		{identifier: "budi"},
+		{identifier: "@staff.univ.ac.id"},
+	}
+	for _, tt := range tests {
+		got, ok := router.DirectoryUsername(tt.identifier)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("DirectoryUsername(%q) = (%q, %v), want (%q, %v)", tt.identifier, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestNewRouterRejectsInvalidConfig(t *testing.T) {
+	local := &stubAuthenticator{name: BackendLocal}
+
+	tests := []struct {
+		name string
+		cfg  config.AuthChainConfig
+	}{
+		{name: "empty default", cfg: config.AuthChainConfig{}},
+		{name: "unknown default backend", cfg: config.AuthChainConfig{Default: []string{"kerberos"}}},
+		{name: "unknown role backend", cfg: config.AuthChainConfig{
+			Default: []string{BackendLocal},
+			ByRole:  map[string][]string{"Admin": {BackendLDAP}},
+		}},
+		{name: "unknown domain backend", cfg: config.AuthChainConfig{
+			Default:  []string{BackendLocal},
+			ByDomain: map[string][]string{"staff.univ.ac.id": {BackendLDAP}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRouter(tt.cfg, local); err == nil {
+				t.Fatal("expected configuration error")
+			}
+		})
+	}
+}
+
+func TestChainAuthenticate(t *testing.T) {
+	errDown := errors.New("connection refused")
+
+	tests := []struct {
+		name     string
+		results  []error
+		want     string
+		wantErr  error
+		wantCall []bool
+	}{
+		{name: "first success wins", results: []error{nil, nil}, want: "a", wantCall: []bool{true, false}},
+		{name: "falls through rejection", results: []error{ErrInvalidCredentials, nil}, want: "b", wantCall: []bool{true, true}},
+		{name: "falls through unavailable backend", results: []error{errDown, nil}, want: "b", wantCall: []bool{true, true}},
+		{name: "any rejection means invalid credentials", results: []error{errDown, ErrInvalidCredentials}, wantErr: ErrInvalidCredentials},
+		{name: "only outages means unavailable", results: []error{errDown, errDown}, wantErr: ErrUnavailable},
+		{name: "empty chain", results: nil, wantErr: ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var chain Chain
+			var stubs []*stubAuthenticator
+			for i, err := range tt.results {
+				stub := &stubAuthenticator{name: string(rune('a' + i)), err: err}
+				stubs = append(stubs, stub)
+				chain = append(chain, stub)
+			}
+
+			got, err := chain.Authenticate(context.Background(), &model.User{}, "secret")
+			if got != tt.want || !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("Authenticate() = (%q, %v), want (%q, %v)", got, err, tt.want, tt.wantErr)
+			}
+			for i, want := range tt.wantCall {
+				if stubs[i].called != want {
+					t.Errorf("backend %s called = %v, want %v", stubs[i].name, stubs[i].called, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// File: app/authn/ldap.go
+
+package authn
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/config"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPAuthenticator memverifikasi password lewat bind ke direktori LDAP.
+// Entry dicari berdasarkan username lokal (uid), lalu nama lengkap dan email
+// dari direktori disinkronkan ke model.User setelah bind berhasil.
+type LDAPAuthenticator struct {
+	cfg      config.LDAPConfig
+	userRepo repository.UserRepository
+}
+
+func NewLDAPAuthenticator(cfg config.LDAPConfig, userRepo repository.UserRepository) *LDAPAuthenticator {
+	return &LDAPAuthenticator{cfg: cfg, userRepo: userRepo}
+}
+
+func (a *LDAPAuthenticator) Name() string {
+	return BackendLDAP
+}
+
+func (a *LDAPAuthenticator) Authenticate(ctx context.Context, user *model.User, password string) error {
+	// Bind dengan password kosong = unauthenticated bind yang "sukses" di banyak server
+	if password == "" {
+		return ErrInvalidCredentials
+	}
+	if a.cfg.URL == "" || a.cfg.BaseDN == "" {
+		return fmt.Errorf("%w: LDAP_URL and LDAP_BASE_DN are required", ErrUnavailable)
+	}
+
+	conn, err := a.dial()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer conn.Close()
+
+	// 1. Bind sebagai service account direktori (atau anonymous bila BindDN kosong)
+	if a.cfg.BindDN != "" {
+		if err := conn.Bind(a.cfg.BindDN, a.cfg.BindPassword); err != nil {
+			return fmt.Errorf("%w: service bind failed: %v", ErrUnavailable, err)
+		}
+	}
+
+	// 2. Cari entry user berdasarkan uid
+	search := ldap.NewSearchRequest(
+		a.cfg.BaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, a.cfg.TimeoutSeconds, false,
+		fmt.Sprintf(a.cfg.UserFilter, ldap.EscapeFilter(user.Username)),
+		[]string{"dn", a.cfg.NameAttribute, a.cfg.EmailAttribute},
+		nil,
+	)
+	result, err := conn.Search(search)
+	if err != nil {
+		return fmt.Errorf("%w: search failed: %v", ErrUnavailable, err)
+	}
+	if len(result.Entries) != 1 {
+		// Tidak ada di direktori (atau ambigu): biarkan backend berikutnya mencoba
+		return ErrInvalidCredentials
+	}
+	entry := result.Entries[0]
+
+	// 3. Bind sebagai user untuk memverifikasi password
+	if err := conn.Bind(entry.DN, password); err != nil {
+		var ldapErr *ldap.Error
+		if errors.As(err, &ldapErr) && ldapErr.ResultCode == ldap.LDAPResultInvalidCredentials {
+			return ErrInvalidCredentials
+		}
+		return fmt.Errorf("%w: user bind failed: %v", ErrUnavailable, err)
+	}
+
+	a.syncAttributes(user, entry)
+	return nil
+}
+
+func (a *LDAPAuthenticator) dial() (*ldap.Conn, error) {
+	timeout := time.Duration(a.cfg.TimeoutSeconds) * time.Second
+	tlsConfig := &tls.Config{InsecureSkipVerify: a.cfg.InsecureSkipVerify}
+
+	conn, err := ldap.DialURL(a.cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(timeout)
+
+	if a.cfg.StartTLS && !strings.HasPrefix(strings.ToLower(a.cfg.URL), "ldaps://") {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// syncAttributes menyalin nama lengkap & email dari direktori bila berbeda.
+// Kegagalan sinkronisasi tidak membatalkan login.
+func (a *LDAPAuthenticator) syncAttributes(user *model.User, entry *ldap.Entry) {
+	changed := false
+
+	if name := strings.TrimSpace(entry.GetAttributeValue(a.cfg.NameAttribute)); name != "" && name != user.FullName {
+		user.FullName = name
+		changed = true
+	}
+
+	if email := model.NormalizeIdentifier(entry.GetAttributeValue(a.cfg.EmailAttribute)); email != "" && email != user.Email {
+		taken, err := a.userRepo.IsEmailTaken(email, user.ID)
+		if err == nil && !taken {
+			user.Email = email
+			changed = true
+		} else {
+			fmt.Printf("Warning: ldap email %s for user %s is already used by another account\n", email, user.ID)
+		}
+	}
+
+	if changed {
+		if err := a.userRepo.Update(user); err != nil {
+			fmt.Printf("Warning: failed to sync ldap attributes for user %s: %v\n", user.ID, err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/config"
+	"github.com/google/uuid"
+)
+
+// ldapFakeUserRepo hanya menyediakan method yang dipakai sinkronisasi atribut
+type ldapFakeUserRepo struct {
+	repository.UserRepository
+	updated *model.User
+}
+
+func (r *ldapFakeUserRepo) IsEmailTaken(string, uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func (r *ldapFakeUserRepo) Update(user *model.User) error {
+	r.updated = user
+	return nil
+}
+
+func TestLDAPRejectsEmptyPassword(t *testing.T) {
+	a := NewLDAPAuthenticator(config.LDAPConfig{URL: "ldap://127.0.0.1:1", BaseDN: "dc=univ,dc=ac,dc=id"}, &ldapFakeUserRepo{})
+
+	// Tanpa koneksi: bind password kosong tidak boleh pernah dicoba
+	if err := a.Authenticate(context.Background(), &model.User{Username: "budi"}, ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLDAPMissingConfigIsUnavailable(t *testing.T) {
+	a := NewLDAPAuthenticator(config.LDAPConfig{}, &ldapFakeUserRepo{})
+
+	if err := a.Authenticate(context.Background(), &model.User{Username: "budi"}, "secret"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("err = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestLDAPUnreachableServerIsUnavailable(t *testing.T) {
+	// Port yang baru saja ditutup: koneksi ditolak tanpa menunggu timeout
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	a := NewLDAPAuthenticator(config.LDAPConfig{
+		URL:            "ldap://" + addr,
+		BaseDN:         "dc=univ,dc=ac,dc=id",
+		UserFilter:     "(uid=%s)",
+		TimeoutSeconds: 1,
+	}, &ldapFakeUserRepo{})
+
+	if err := a.Authenticate(context.Background(), &model.User{Username: "budi"}, "secret"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("err = %v, want ErrUnavailable", err)
+	}
+}
+
+// TestLDAPIntegration berjalan terhadap server LDAP sungguhan bila LDAP_TEST_URL di-set, mis.:
+//
+//	LDAP_TEST_URL=ldap://localhost:389 LDAP_TEST_BASE_DN=dc=univ,dc=ac,dc=id \
+//	LDAP_TEST_BIND_DN=cn=admin,dc=univ,dc=ac,dc=id LDAP_TEST_BIND_PASSWORD=admin \
+//	LDAP_TEST_USERNAME=budi LDAP_TEST_PASSWORD=rahasia go test ./app/authn/ -run LDAPIntegration
+func TestLDAPIntegration(t *testing.T) {
+	url := os.Getenv("LDAP_TEST_URL")
+	if url == "" {
+		t.Skip("LDAP_TEST_URL not set; skipping LDAP integration test")
+	}
+	username := os.Getenv("LDAP_TEST_USERNAME")
+	password := os.Getenv("LDAP_TEST_PASSWORD")
+	if username == "" || password == "" {
+		t.Fatal("LDAP_TEST_USERNAME and LDAP_TEST_PASSWORD are required with LDAP_TEST_URL")
+	}
+
+	startTLS, _ := strconv.ParseBool(os.Getenv("LDAP_TEST_START_TLS"))
+	insecure, _ := strconv.ParseBool(os.Getenv("LDAP_TEST_INSECURE_SKIP_VERIFY"))
+	cfg := config.LDAPConfig{
+		URL:                url,
+		StartTLS:           startTLS,
+		InsecureSkipVerify: insecure,
+		BindDN:             os.Getenv("LDAP_TEST_BIND_DN"),
+		BindPassword:       os.Getenv("LDAP_TEST_BIND_PASSWORD"),
+		BaseDN:             os.Getenv("LDAP_TEST_BASE_DN"),
+		UserFilter:         envOr("LDAP_TEST_USER_FILTER", "(uid=%s)"),
+		NameAttribute:      envOr("LDAP_TEST_NAME_ATTRIBUTE", "cn"),
+		EmailAttribute:     envOr("LDAP_TEST_EMAIL_ATTRIBUTE", "mail"),
+		TimeoutSeconds:     5,
+	}
+
+	t.Run("valid password", func(t *testing.T) {
+		repo := &ldapFakeUserRepo{}
+		user := &model.User{ID: uuid.New(), Username: username}
+		if err := NewLDAPAuthenticator(cfg, repo).Authenticate(context.Background(), user, password); err != nil {
+			t.Fatalf("Authenticate() = %v, want success", err)
+		}
+		if user.FullName == "" {
+			t.Errorf("full name was not synced from attribute %q", cfg.NameAttribute)
+		}
+		if repo.updated == nil {
+			t.Error("synced attributes were not saved")
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		user := &model.User{ID: uuid.New(), Username: username}
+		err := NewLDAPAuthenticator(cfg, &ldapFakeUserRepo{}).Authenticate(context.Background(), user, password+"-wrong")
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Authenticate() = %v, want ErrInvalidCredentials", err)
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		user := &model.User{ID: uuid.New(), Username: "no-such-user-" + uuid.NewString()}
+		err := NewLDAPAuthenticator(cfg, &ldapFakeUserRepo{}).Authenticate(context.Background(), user, password)
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Authenticate() = %v, want ErrInvalidCredentials", err)
+		}
+	})
+
+	t.Run("filter injection is escaped", func(t *testing.T) {
+		user := &model.User{ID: uuid.New(), Username: "*"}
+		err := NewLDAPAuthenticator(cfg, &ldapFakeUserRepo{}).Authenticate(context.Background(), user, password)
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Authenticate() = %v, want ErrInvalidCredentials", err)
+		}
+	})
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
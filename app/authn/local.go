@@ -0,0 +1,28 @@
+// File: app/authn/local.go
+
+package authn
+
+import (
+	"context"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LocalAuthenticator membandingkan password dengan hash bcrypt di tabel users
+type LocalAuthenticator struct{}
+
+func NewLocalAuthenticator() *LocalAuthenticator {
+	return &LocalAuthenticator{}
+}
+
+func (a *LocalAuthenticator) Name() string {
+	return BackendLocal
+}
+
+func (a *LocalAuthenticator) Authenticate(_ context.Context, user *model.User, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
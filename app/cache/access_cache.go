@@ -0,0 +1,170 @@
+// File: app/cache/access_cache.go
+
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/google/uuid"
+)
+
+// AccessCache menyimpan status keamanan user, permission per role, dan status sesi login di memori
+// sehingga AuthMiddleware tidak perlu query database di setiap request.
+// Perubahan di instance ini langsung di-invalidate; TTL menjadi batas atas
+// ketertinggalan data bila aplikasi dijalankan lebih dari satu instance.
+type AccessCache interface {
+	UserState(userID uuid.UUID) (*model.UserSecurityState, error)
+	RoleAccess(roleID uuid.UUID) (*RoleAccess, error)
+	Session(sessionID uuid.UUID) (*model.UserSession, error)
+
+	InvalidateUser(userID uuid.UUID)
+	InvalidateSession(sessionID uuid.UUID)
+	InvalidateRole(roleID uuid.UUID)
+	InvalidateAllRoles()
+}
+
+// RoleAccess adalah nama role beserta daftar nama permission-nya
+type RoleAccess struct {
+	Name        string
+	Permissions []string
+}
+
+type userEntry struct {
+	state     model.UserSecurityState
+	expiresAt time.Time
+}
+
+type roleEntry struct {
+	access    RoleAccess
+	expiresAt time.Time
+}
+
+type sessionEntry struct {
+	session   model.UserSession
+	expiresAt time.Time
+}
+
+type accessCache struct {
+	userRepo    repository.UserRepository
+	roleRepo    repository.RoleRepository
+	sessionRepo repository.SessionRepository
+	ttl         time.Duration
+
+	mu       sync.RWMutex
+	users    map[uuid.UUID]userEntry
+	roles    map[uuid.UUID]roleEntry
+	sessions map[uuid.UUID]sessionEntry
+}
+
+func NewAccessCache(userRepo repository.UserRepository, roleRepo repository.RoleRepository, sessionRepo repository.SessionRepository, ttl time.Duration) AccessCache {
+	return &accessCache{
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		sessionRepo: sessionRepo,
+		ttl:         ttl,
+		users:       make(map[uuid.UUID]userEntry),
+		roles:       make(map[uuid.UUID]roleEntry),
+		sessions:    make(map[uuid.UUID]sessionEntry),
+	}
+}
+
+func (c *accessCache) UserState(userID uuid.UUID) (*model.UserSecurityState, error) {
+	now := time.Now()
+
+	c.mu.RLock()
+	entry, ok := c.users[userID]
+	c.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		state := entry.state
+		return &state, nil
+	}
+
+	state, err := c.userRepo.GetSecurityState(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.users[userID] = userEntry{state: *state, expiresAt: now.Add(c.ttl)}
+	c.mu.Unlock()
+
+	return state, nil
+}
+
+func (c *accessCache) RoleAccess(roleID uuid.UUID) (*RoleAccess, error) {
+	now := time.Now()
+
+	c.mu.RLock()
+	entry, ok := c.roles[roleID]
+	c.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		access := entry.access
+		return &access, nil
+	}
+
+	role, err := c.roleRepo.FindByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	access := RoleAccess{Name: role.Name, Permissions: make([]string, 0, len(role.Permissions))}
+	for _, p := range role.Permissions {
+		access.Permissions = append(access.Permissions, p.Name)
+	}
+
+	c.mu.Lock()
+	c.roles[roleID] = roleEntry{access: access, expiresAt: now.Add(c.ttl)}
+	c.mu.Unlock()
+
+	return &access, nil
+}
+
+func (c *accessCache) Session(sessionID uuid.UUID) (*model.UserSession, error) {
+	now := time.Now()
+
+	c.mu.RLock()
+	entry, ok := c.sessions[sessionID]
+	c.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		session := entry.session
+		return &session, nil
+	}
+
+	session, err := c.sessionRepo.FindByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.sessions[sessionID] = sessionEntry{session: *session, expiresAt: now.Add(c.ttl)}
+	c.mu.Unlock()
+
+	return session, nil
+}
+
+func (c *accessCache) InvalidateSession(sessionID uuid.UUID) {
+	c.mu.Lock()
+	delete(c.sessions, sessionID)
+	c.mu.Unlock()
+}
+
+func (c *accessCache) InvalidateUser(userID uuid.UUID) {
+	c.mu.Lock()
+	delete(c.users, userID)
+	c.mu.Unlock()
+}
+
+func (c *accessCache) InvalidateRole(roleID uuid.UUID) {
+	c.mu.Lock()
+	delete(c.roles, roleID)
+	c.mu.Unlock()
+}
+
+func (c *accessCache) InvalidateAllRoles() {
+	c.mu.Lock()
+	c.roles = make(map[uuid.UUID]roleEntry)
+	c.mu.Unlock()
+}
@@ -0,0 +1,114 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/google/uuid"
+)
+
+// ErrErrorFileNotFound dikembalikan bila file error tidak ada atau sudah kedaluwarsa
+var ErrErrorFileNotFound = errors.New("error file not found or expired")
+
+// WriteErrorCSV menulis baris yang gagal beserta kolom "row" dan "errors" di depan header asli,
+// sehingga file bisa diperbaiki lalu diunggah ulang (kolom tambahan diabaikan saat import).
+func WriteErrorCSV(w io.Writer, table *Table, report *model.ImportReport) error {
+	failed := make(map[int][]model.ImportFieldError)
+	for _, row := range report.Rows {
+		if row.Action == model.ImportActionError {
+			failed[row.Row] = row.Errors
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append([]string{"row", "errors"}, table.Header...)); err != nil {
+		return err
+	}
+	for _, row := range table.Rows {
+		fieldErrors, ok := failed[row.Line]
+		if !ok {
+			continue
+		}
+		messages := make([]string, 0, len(fieldErrors))
+		for _, e := range fieldErrors {
+			if e.Field != "" {
+				messages = append(messages, e.Field+": "+e.Message)
+			} else {
+				messages = append(messages, e.Message)
+			}
+		}
+		record := append([]string{strconv.Itoa(row.Line), strings.Join(messages, "; ")}, row.Values...)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ErrorFileStore menyimpan CSV baris gagal di disk agar bisa diunduh setelah import.
+// Direktori ini sengaja terpisah dari UPLOAD_PATH yang disajikan publik.
+type ErrorFileStore struct {
+	dir string
+	ttl time.Duration
+}
+
+func NewErrorFileStore(dir string, ttl time.Duration) *ErrorFileStore {
+	return &ErrorFileStore{dir: dir, ttl: ttl}
+}
+
+// Save menulis file error dan mengembalikan ID-nya; file yang sudah kedaluwarsa ikut dibersihkan
+func (s *ErrorFileStore) Save(table *Table, report *model.ImportReport) (string, error) {
+	if err := os.MkdirAll(s.dir, 0750); err != nil {
+		return "", err
+	}
+	s.sweep()
+
+	id := uuid.New().String()
+	file, err := os.OpenFile(s.path(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		return "", err
+	}
+	if err := WriteErrorCSV(file, table, report); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", err
+	}
+	return id, file.Close()
+}
+
+// Path mengembalikan lokasi file error yang masih berlaku
+func (s *ErrorFileStore) Path(id string) (string, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return "", ErrErrorFileNotFound
+	}
+	info, err := os.Stat(s.path(id))
+	if err != nil || time.Since(info.ModTime()) > s.ttl {
+		return "", ErrErrorFileNotFound
+	}
+	return s.path(id), nil
+}
+
+func (s *ErrorFileStore) path(id string) string {
+	return filepath.Join(s.dir, id+".csv")
+}
+
+func (s *ErrorFileStore) sweep() {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err == nil && !entry.IsDir() && time.Since(info.ModTime()) > s.ttl {
+			os.Remove(filepath.Join(s.dir, entry.Name()))
+		}
+	}
+}
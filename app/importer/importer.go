@@ -0,0 +1,488 @@
+package importer
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/app/utils"
+	"github.com/google/uuid"
+)
+
+// lookupChunkSize membatasi jumlah parameter IN (...) per query lookup
+const lookupChunkSize = 1000
+
+// Options mengatur perilaku satu import
+type Options struct {
+	// DryRun hanya memvalidasi dan menghasilkan laporan, tanpa menulis apa pun
+	DryRun bool
+	// Upsert memperbarui mahasiswa/dosen yang NIM/NIP-nya sudah ada (tanpa Upsert baris tersebut ditolak)
+	Upsert bool
+}
+
+// Importer memvalidasi spreadsheet mahasiswa/dosen lalu membuat user + profil secara atomik.
+// Dipakai bersama oleh endpoint HTTP dan perintah CLI `go run . import`.
+type Importer struct {
+	userRepo         repository.UserRepository
+	studentRepo      repository.StudentRepository
+	lecturerRepo     repository.LecturerRepository
+	roleRepo         repository.RoleRepository
+	provisioningRepo repository.ProvisioningRepository
+}
+
+func New(
+	userRepo repository.UserRepository,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+	roleRepo repository.RoleRepository,
+	provisioningRepo repository.ProvisioningRepository,
+) *Importer {
+	return &Importer{
+		userRepo:         userRepo,
+		studentRepo:      studentRepo,
+		lecturerRepo:     lecturerRepo,
+		roleRepo:         roleRepo,
+		provisioningRepo: provisioningRepo,
+	}
+}
+
+var studentColumns = []columnSpec{
+	{Field: "nim", Aliases: []string{"student_id"}, Required: true},
+	{Field: "name", Aliases: []string{"full_name", "nama", "nama_lengkap"}, Required: true},
+	{Field: "email", Aliases: []string{"e_mail"}, Required: true},
+	{Field: "program_study", Aliases: []string{"program_studi", "prodi"}, Required: true},
+	{Field: "academic_year", Aliases: []string{"angkatan", "tahun_akademik"}, Required: true},
+	{Field: "advisor_nip", Aliases: []string{"nip_dosen_wali", "dosen_wali_nip", "nip_wali"}},
+}
+
+var lecturerColumns = []columnSpec{
+	{Field: "nip", Aliases: []string{"lecturer_id"}, Required: true},
+	{Field: "name", Aliases: []string{"full_name", "nama", "nama_lengkap"}, Required: true},
+	{Field: "email", Aliases: []string{"e_mail"}, Required: true},
+	{Field: "department", Aliases: []string{"departemen"}},
+}
+
+// Run memvalidasi semua baris. Bila tidak ada baris yang gagal dan bukan dry-run,
+// semua baris ditulis dalam satu transaksi; bila ada yang gagal, tidak ada yang ditulis.
+func (im *Importer) Run(kind string, table *Table, opts Options) (*model.ImportReport, error) {
+	report := &model.ImportReport{
+		Kind:      kind,
+		DryRun:    opts.DryRun,
+		Upsert:    opts.Upsert,
+		TotalRows: len(table.Rows),
+		Rows:      make([]model.ImportRowResult, 0, len(table.Rows)),
+	}
+
+	var records []model.ProvisionRecord
+	var err error
+	switch kind {
+	case model.ImportKindStudents:
+		records, err = im.validateStudents(table, opts, report)
+	case model.ImportKindLecturers:
+		records, err = im.validateLecturers(table, opts, report)
+	default:
+		return nil, invalidFile("unknown import kind %q", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range report.Rows {
+		switch row.Action {
+		case model.ImportActionCreate:
+			report.Created++
+		case model.ImportActionUpdate:
+			report.Updated++
+		default:
+			report.Failed++
+		}
+	}
+
+	if opts.DryRun || report.Failed > 0 || len(records) == 0 {
+		return report, nil
+	}
+	if err := im.provisioningRepo.SaveAll(records); err != nil {
+		return nil, fmt.Errorf("import aborted, no rows were written: %w", err)
+	}
+	report.Committed = true
+	return report, nil
+}
+
+func (im *Importer) validateStudents(table *Table, opts Options, report *model.ImportReport) ([]model.ProvisionRecord, error) {
+	columns, err := resolveColumns(table.Header, studentColumns)
+	if err != nil {
+		return nil, err
+	}
+	role, err := im.roleRepo.FindByName(model.RoleMahasiswa)
+	if err != nil {
+		return nil, fmt.Errorf("role %s not found: %w", model.RoleMahasiswa, err)
+	}
+
+	var nims, advisorNIPs, identifiers []string
+	for _, row := range table.Rows {
+		nims = append(nims, row.Value(columns["nim"]))
+		identifiers = append(identifiers, row.Value(columns["nim"]), row.Value(columns["email"]))
+		if nip := row.Value(columns["advisor_nip"]); nip != "" {
+			advisorNIPs = append(advisorNIPs, nip)
+		}
+	}
+	existing, err := im.studentsByNIM(nims)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := im.identifierOwners(identifiers)
+	if err != nil {
+		return nil, err
+	}
+	advisors, err := im.lecturersByNIP(advisorNIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := newDuplicateTracker()
+	passwords := &sharedPassword{}
+	var records []model.ProvisionRecord
+
+	for _, row := range table.Rows {
+		nim := row.Value(columns["nim"])
+		name := row.Value(columns["name"])
+		email := model.NormalizeIdentifier(row.Value(columns["email"]))
+		programStudy := row.Value(columns["program_study"])
+		academicYear := row.Value(columns["academic_year"])
+		advisorNIP := row.Value(columns["advisor_nip"])
+
+		result := model.ImportRowResult{Row: row.Line, Key: nim}
+		check := &rowChecker{}
+		check.required("nim", nim, 20)
+		check.required("name", name, 255)
+		check.email("email", email)
+		check.required("program_study", programStudy, 100)
+		check.required("academic_year", academicYear, 10)
+		seen.check(check, row.Line, "nim", strings.ToLower(nim))
+		seen.check(check, row.Line, "email", email)
+
+		var advisorID *uuid.UUID
+		if advisorNIP != "" {
+			if advisor, ok := advisors[advisorNIP]; ok {
+				advisorID = &advisor.ID
+			} else {
+				check.add("advisor_nip", "no lecturer with NIP "+advisorNIP)
+			}
+		}
+
+		current, exists := existing[nim]
+		switch {
+		case exists && !opts.Upsert:
+			check.add("nim", "student already exists (enable upsert to update)")
+		case exists:
+			owners.checkEmailFree(check, email, current.UserID)
+		default:
+			owners.checkUsernameFree(check, nim)
+			owners.checkEmailFree(check, email, uuid.Nil)
+		}
+
+		if check.failed() {
+			result.Action = model.ImportActionError
+			result.Errors = check.errors
+			report.Rows = append(report.Rows, result)
+			continue
+		}
+
+		if exists {
+			current.User.FullName = name
+			current.User.Email = email
+			current.ProgramStudy = programStudy
+			current.StudyProgramID = nil // ditautkan ulang dari teks saat disimpan
+			current.AcademicYear = academicYear
+			if advisorID != nil {
+				current.AdvisorID = advisorID
+			}
+			records = append(records, model.ProvisionRecord{User: &current.User, Student: current})
+			result.Action = model.ImportActionUpdate
+		} else {
+			user, err := passwords.newUser(nim, email, name, role.ID)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, model.ProvisionRecord{
+				User: user,
+				Student: &model.Student{
+					NIM:          nim,
+					ProgramStudy: programStudy,
+					AcademicYear: academicYear,
+					AdvisorID:    advisorID,
+				},
+			})
+			result.Action = model.ImportActionCreate
+		}
+		report.Rows = append(report.Rows, result)
+	}
+	return records, nil
+}
+
+func (im *Importer) validateLecturers(table *Table, opts Options, report *model.ImportReport) ([]model.ProvisionRecord, error) {
+	columns, err := resolveColumns(table.Header, lecturerColumns)
+	if err != nil {
+		return nil, err
+	}
+	role, err := im.roleRepo.FindByName(model.RoleDosenWali)
+	if err != nil {
+		return nil, fmt.Errorf("role %s not found: %w", model.RoleDosenWali, err)
+	}
+
+	var nips, identifiers []string
+	for _, row := range table.Rows {
+		nips = append(nips, row.Value(columns["nip"]))
+		identifiers = append(identifiers, row.Value(columns["nip"]), row.Value(columns["email"]))
+	}
+	existing, err := im.lecturersByNIP(nips)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := im.identifierOwners(identifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := newDuplicateTracker()
+	passwords := &sharedPassword{}
+	var records []model.ProvisionRecord
+
+	for _, row := range table.Rows {
+		nip := row.Value(columns["nip"])
+		name := row.Value(columns["name"])
+		email := model.NormalizeIdentifier(row.Value(columns["email"]))
+		department := row.Value(columns["department"])
+
+		result := model.ImportRowResult{Row: row.Line, Key: nip}
+		check := &rowChecker{}
+		check.required("nip", nip, 20)
+		check.required("name", name, 255)
+		check.email("email", email)
+		check.maxLength("department", department, 100)
+		seen.check(check, row.Line, "nip", strings.ToLower(nip))
+		seen.check(check, row.Line, "email", email)
+
+		current, exists := existing[nip]
+		switch {
+		case exists && !opts.Upsert:
+			check.add("nip", "lecturer already exists (enable upsert to update)")
+		case exists:
+			owners.checkEmailFree(check, email, current.UserID)
+		default:
+			owners.checkUsernameFree(check, nip)
+			owners.checkEmailFree(check, email, uuid.Nil)
+		}
+
+		if check.failed() {
+			result.Action = model.ImportActionError
+			result.Errors = check.errors
+			report.Rows = append(report.Rows, result)
+			continue
+		}
+
+		if exists {
+			if current.User == nil {
+				return nil, fmt.Errorf("lecturer %s has no user account", nip)
+			}
+			current.User.FullName = name
+			current.User.Email = email
+			current.Department = department
+			current.DepartmentID = nil
+			records = append(records, model.ProvisionRecord{User: current.User, Lecturer: current})
+			result.Action = model.ImportActionUpdate
+		} else {
+			user, err := passwords.newUser(nip, email, name, role.ID)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, model.ProvisionRecord{
+				User:     user,
+				Lecturer: &model.Lecturer{LecturerID: nip, Department: department},
+			})
+			result.Action = model.ImportActionCreate
+		}
+		report.Rows = append(report.Rows, result)
+	}
+	return records, nil
+}
+
+// identifierOwners memetakan identifier (username/email ter-normalisasi) ke user yang sudah memakainya
+type identifierOwners map[string][]uuid.UUID
+
+// takenByOther: nilai dipakai user lain sebagai username maupun email (sama dengan IsUsernameTaken)
+func (o identifierOwners) takenByOther(value string, ownerID uuid.UUID) bool {
+	for _, id := range o[model.NormalizeIdentifier(value)] {
+		if id != ownerID {
+			return true
+		}
+	}
+	return false
+}
+
+func (o identifierOwners) checkUsernameFree(check *rowChecker, identifier string) {
+	if identifier != "" && o.takenByOther(identifier, uuid.Nil) {
+		check.add("", "username "+model.NormalizeIdentifier(identifier)+" is already used by another account")
+	}
+}
+
+func (o identifierOwners) checkEmailFree(check *rowChecker, email string, ownerID uuid.UUID) {
+	if email != "" && o.takenByOther(email, ownerID) {
+		check.add("email", "email is already used by another account")
+	}
+}
+
+// identifierOwners memuat pemilik semua NIM/NIP dan email di file sekaligus, per chunk
+func (im *Importer) identifierOwners(values []string) (identifierOwners, error) {
+	normalized := make([]string, len(values))
+	for i, v := range values {
+		normalized[i] = model.NormalizeIdentifier(v)
+	}
+	owners := make(identifierOwners, len(values))
+	for _, chunk := range chunkStrings(normalized) {
+		found, err := im.userRepo.FindIdentifierOwners(chunk)
+		if err != nil {
+			return nil, err
+		}
+		for value, ids := range found {
+			owners[value] = append(owners[value], ids...)
+		}
+	}
+	return owners, nil
+}
+
+func (im *Importer) studentsByNIM(nims []string) (map[string]*model.Student, error) {
+	result := make(map[string]*model.Student, len(nims))
+	for _, chunk := range chunkStrings(nims) {
+		students, err := im.studentRepo.FindByNIMs(chunk)
+		if err != nil {
+			return nil, err
+		}
+		for i := range students {
+			result[students[i].NIM] = &students[i]
+		}
+	}
+	return result, nil
+}
+
+func (im *Importer) lecturersByNIP(nips []string) (map[string]*model.Lecturer, error) {
+	result := make(map[string]*model.Lecturer, len(nips))
+	for _, chunk := range chunkStrings(nips) {
+		lecturers, err := im.lecturerRepo.FindByLecturerIDs(chunk)
+		if err != nil {
+			return nil, err
+		}
+		for i := range lecturers {
+			result[lecturers[i].LecturerID] = &lecturers[i]
+		}
+	}
+	return result, nil
+}
+
+func chunkStrings(values []string) [][]string {
+	unique := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+
+	var chunks [][]string
+	for len(unique) > lookupChunkSize {
+		chunks = append(chunks, unique[:lookupChunkSize])
+		unique = unique[lookupChunkSize:]
+	}
+	if len(unique) > 0 {
+		chunks = append(chunks, unique)
+	}
+	return chunks
+}
+
+// sharedPassword: user hasil import belum punya password lokal (login lewat SSO/LDAP atau
+// reset oleh admin). Satu hash tak terpakai dipakai untuk seluruh batch agar import ribuan
+// baris tidak menghabiskan waktu di bcrypt.
+type sharedPassword struct {
+	hash string
+}
+
+func (p *sharedPassword) newUser(identifier, email, fullName string, roleID uuid.UUID) (*model.User, error) {
+	if p.hash == "" {
+		hash, err := utils.UnusablePasswordHash()
+		if err != nil {
+			return nil, err
+		}
+		p.hash = hash
+	}
+	return &model.User{
+		Username:     model.NormalizeIdentifier(identifier),
+		Email:        email,
+		FullName:     fullName,
+		PasswordHash: p.hash,
+		RoleID:       roleID,
+		IsActive:     true,
+	}, nil
+}
+
+// rowChecker mengumpulkan semua kesalahan satu baris (bukan berhenti di kesalahan pertama)
+type rowChecker struct {
+	errors []model.ImportFieldError
+}
+
+func (c *rowChecker) add(field, message string) {
+	c.errors = append(c.errors, model.ImportFieldError{Field: field, Message: message})
+}
+
+func (c *rowChecker) failed() bool {
+	return len(c.errors) > 0
+}
+
+func (c *rowChecker) required(field, value string, maxLength int) {
+	if value == "" {
+		c.add(field, "is required")
+		return
+	}
+	c.maxLength(field, value, maxLength)
+}
+
+func (c *rowChecker) maxLength(field, value string, maxLength int) {
+	if utf8.RuneCountInString(value) > maxLength {
+		c.add(field, fmt.Sprintf("must be at most %d characters", maxLength))
+	}
+}
+
+func (c *rowChecker) email(field, value string) {
+	if value == "" {
+		c.add(field, "is required")
+		return
+	}
+	c.maxLength(field, value, 100)
+	if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+		c.add(field, "is not a valid email address")
+	}
+}
+
+// duplicateTracker menandai nilai yang muncul lebih dari sekali di file yang sama
+type duplicateTracker struct {
+	firstLine map[string]int
+}
+
+func newDuplicateTracker() *duplicateTracker {
+	return &duplicateTracker{firstLine: make(map[string]int)}
+}
+
+func (t *duplicateTracker) check(check *rowChecker, line int, field, value string) {
+	if value == "" {
+		return
+	}
+	key := field + "\x00" + value
+	if first, ok := t.firstLine[key]; ok {
+		check.add(field, fmt.Sprintf("duplicate of row %d", first))
+		return
+	}
+	t.firstLine[key] = line
+}
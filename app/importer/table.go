@@ -0,0 +1,175 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrInvalidFile dibungkus oleh semua error yang disebabkan isi/format file (dipetakan ke 400)
+var ErrInvalidFile = errors.New("invalid import file")
+
+func invalidFile(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidFile, fmt.Sprintf(format, args...))
+}
+
+// Table adalah isi spreadsheet: header apa adanya dan baris data (baris kosong sudah dibuang)
+type Table struct {
+	Header []string
+	Rows   []Row
+}
+
+// Row adalah satu baris data; Line adalah nomor baris di file (header = 1)
+type Row struct {
+	Line   int
+	Values []string
+}
+
+// Value mengambil sel pada kolom index (kosong bila baris lebih pendek dari header)
+func (r Row) Value(index int) string {
+	if index < 0 || index >= len(r.Values) {
+		return ""
+	}
+	return strings.TrimSpace(r.Values[index])
+}
+
+// ReadTable membaca file .csv atau .xlsx (sheet pertama). Baris pertama wajib header.
+// maxRows <= 0 berarti tanpa batas.
+func ReadTable(filename string, r io.Reader, maxRows int) (*Table, error) {
+	var records [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		records, err = readCSV(r)
+	case ".xlsx":
+		records, err = readXLSX(r)
+	default:
+		return nil, invalidFile("unsupported file type %q (use .csv or .xlsx)", filepath.Ext(filename))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, invalidFile("file is empty")
+	}
+
+	table := &Table{Header: records[0]}
+	for i, values := range records[1:] {
+		if isBlank(values) {
+			continue
+		}
+		table.Rows = append(table.Rows, Row{Line: i + 2, Values: values})
+	}
+	if len(table.Rows) == 0 {
+		return nil, invalidFile("file has no data rows")
+	}
+	if maxRows > 0 && len(table.Rows) > maxRows {
+		return nil, invalidFile("file has %d rows, the limit is %d", len(table.Rows), maxRows)
+	}
+	return table, nil
+}
+
+// readCSV menerima pemisah koma atau titik koma (default Excel berlocale Indonesia) dan BOM UTF-8
+func readCSV(r io.Reader) ([][]string, error) {
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(3); bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		br.Discard(3)
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	if firstLine, _ := br.Peek(br.Size()); detectSemicolon(firstLine) {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, invalidFile("malformed csv: %v", err)
+	}
+	return records, nil
+}
+
+func detectSemicolon(peek []byte) bool {
+	line := peek
+	if i := bytes.IndexByte(peek, '\n'); i >= 0 {
+		line = peek[:i]
+	}
+	return bytes.Count(line, []byte{';'}) > bytes.Count(line, []byte{','})
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	book, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, invalidFile("malformed xlsx: %v", err)
+	}
+	defer book.Close()
+
+	sheets := book.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, invalidFile("workbook has no sheets")
+	}
+	rows, err := book.GetRows(sheets[0])
+	if err != nil {
+		return nil, invalidFile("cannot read sheet %q: %v", sheets[0], err)
+	}
+	return rows, nil
+}
+
+func isBlank(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// columnSpec memetakan nama field ke alias header yang diterima
+type columnSpec struct {
+	Field    string
+	Aliases  []string
+	Required bool
+}
+
+// resolveColumns mencari index kolom per field berdasarkan header (tidak peka huruf besar/spasi)
+func resolveColumns(header []string, specs []columnSpec) (map[string]int, error) {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		key := normalizeHeader(name)
+		if _, exists := positions[key]; !exists {
+			positions[key] = i
+		}
+	}
+
+	columns := make(map[string]int, len(specs))
+	var missing []string
+	for _, spec := range specs {
+		columns[spec.Field] = -1
+		for _, alias := range append([]string{spec.Field}, spec.Aliases...) {
+			if i, ok := positions[alias]; ok {
+				columns[spec.Field] = i
+				break
+			}
+		}
+		if spec.Required && columns[spec.Field] < 0 {
+			missing = append(missing, spec.Field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, invalidFile("missing required columns: %s", strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+func normalizeHeader(name string) string {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+	return strings.NewReplacer(" ", "_", "-", "_", ".", "").Replace(name)
+}
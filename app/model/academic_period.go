@@ -0,0 +1,107 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =================================================================
+// PERIODE AKADEMIK (semester Ganjil/Genap)
+// =================================================================
+
+// Semester dalam satu tahun akademik
+const (
+	SemesterGanjil = "ganjil"
+	SemesterGenap  = "genap"
+)
+
+// Dasar atribusi prestasi ke periode akademik (ACADEMIC_PERIOD_ATTRIBUTION)
+const (
+	PeriodByEventDate      = "event_date"
+	PeriodBySubmissionDate = "submission_date"
+)
+
+// PeriodDateLayout adalah format tanggal mulai/selesai periode pada request
+const PeriodDateLayout = "2006-01-02"
+
+// IsValidPeriodAttribution memeriksa dasar atribusi periode yang dikenal
+func IsValidPeriodAttribution(basis string) bool {
+	return basis == PeriodByEventDate || basis == PeriodBySubmissionDate
+}
+
+// AcademicPeriod adalah satu semester. Rentang tanggal antarperiode tidak boleh tumpang tindih
+// agar setiap prestasi jatuh ke tepat satu periode; paling banyak satu periode aktif.
+type AcademicPeriod struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	AcademicYear string    `json:"academic_year" gorm:"type:varchar(9);not null;uniqueIndex:idx_academic_period_semester"` // mis. 2024/2025
+	Semester     string    `json:"semester" gorm:"type:varchar(10);not null;uniqueIndex:idx_academic_period_semester"`     // ganjil | genap
+	Name         string    `json:"name" gorm:"type:varchar(50);not null"`
+	StartDate    time.Time `json:"start_date" gorm:"type:date;not null;index"`
+	EndDate      time.Time `json:"end_date" gorm:"type:date;not null"` // inklusif
+	IsActive     bool      `json:"is_active" gorm:"not null;default:false;index"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (AcademicPeriod) TableName() string {
+	return "academic_periods"
+}
+
+// AcademicPeriodRequest: DTO create/update periode. Tanggal berformat YYYY-MM-DD.
+type AcademicPeriodRequest struct {
+	AcademicYear string `json:"academic_year" binding:"required"`
+	Semester     string `json:"semester" binding:"required,oneof=ganjil genap"`
+	StartDate    string `json:"start_date" binding:"required"`
+	EndDate      string `json:"end_date" binding:"required"`
+	IsActive     bool   `json:"is_active"`
+}
+
+var academicYearPattern = regexp.MustCompile(`^(\d{4})/(\d{4})$`)
+
+// ValidAcademicYear: format YYYY/YYYY dengan tahun kedua = tahun pertama + 1
+func ValidAcademicYear(value string) bool {
+	m := academicYearPattern.FindStringSubmatch(value)
+	if m == nil {
+		return false
+	}
+	first, _ := strconv.Atoi(m[1])
+	second, _ := strconv.Atoi(m[2])
+	return second == first+1
+}
+
+// AcademicPeriodName membentuk nama tampilan, mis. "Ganjil 2024/2025"
+func AcademicPeriodName(academicYear, semester string) string {
+	if semester == SemesterGenap {
+		return "Genap " + academicYear
+	}
+	return "Ganjil " + academicYear
+}
+
+// ToPeriod memvalidasi request dan mengisi field periode (ID dan status aktif tidak diubah di sini)
+func (req AcademicPeriodRequest) ToPeriod(period *AcademicPeriod) error {
+	if !ValidAcademicYear(req.AcademicYear) {
+		return fmt.Errorf("academic_year must be formatted as YYYY/YYYY with consecutive years")
+	}
+	start, err := time.Parse(PeriodDateLayout, req.StartDate)
+	if err != nil {
+		return fmt.Errorf("start_date must be formatted as YYYY-MM-DD")
+	}
+	end, err := time.Parse(PeriodDateLayout, req.EndDate)
+	if err != nil {
+		return fmt.Errorf("end_date must be formatted as YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return fmt.Errorf("end_date must not be before start_date")
+	}
+
+	period.AcademicYear = req.AcademicYear
+	period.Semester = req.Semester
+	period.Name = AcademicPeriodName(req.AcademicYear, req.Semester)
+	period.StartDate = start
+	period.EndDate = end
+	return nil
+}
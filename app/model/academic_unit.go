@@ -0,0 +1,245 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// =================================================================
+// MASTER DATA AKADEMIK: Faculty -> Department -> StudyProgram
+// =================================================================
+
+// Faculty adalah fakultas
+type Faculty struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Code      string    `json:"code" gorm:"type:varchar(20);uniqueIndex;not null"`
+	Name      string    `json:"name" gorm:"type:varchar(100);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Faculty) TableName() string {
+	return "faculties"
+}
+
+// Department adalah departemen/jurusan di bawah satu fakultas
+type Department struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	FacultyID uuid.UUID `json:"faculty_id" gorm:"type:uuid;not null;index"`
+	Faculty   *Faculty  `json:"faculty,omitempty" gorm:"foreignKey:FacultyID"`
+	Code      string    `json:"code" gorm:"type:varchar(20);uniqueIndex;not null"`
+	Name      string    `json:"name" gorm:"type:varchar(100);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Department) TableName() string {
+	return "departments"
+}
+
+// StudyProgram adalah program studi di bawah satu departemen
+type StudyProgram struct {
+	ID           uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	DepartmentID uuid.UUID   `json:"department_id" gorm:"type:uuid;not null;index"`
+	Department   *Department `json:"department,omitempty" gorm:"foreignKey:DepartmentID"`
+	Code         string      `json:"code" gorm:"type:varchar(20);uniqueIndex;not null"`
+	Name         string      `json:"name" gorm:"type:varchar(100);not null"`
+	Degree       string      `json:"degree,omitempty" gorm:"type:varchar(10)"` // mis. D3, S1, S2
+	CreatedAt    time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (StudyProgram) TableName() string {
+	return "study_programs"
+}
+
+// Jenis unit yang bisa diberi alias ejaan
+const (
+	UnitTypeDepartment   = "department"
+	UnitTypeStudyProgram = "study_program"
+)
+
+// AcademicUnitAlias adalah ejaan lain sebuah departemen/program studi (mis. "T. Informatika", "TI")
+// yang dipakai saat memetakan teks bebas students.program_study dan lecturers.department.
+type AcademicUnitAlias struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UnitType   string    `json:"unit_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_unit_alias_normalized"`
+	UnitID     uuid.UUID `json:"unit_id" gorm:"type:uuid;not null;index"`
+	Alias      string    `json:"alias" gorm:"type:varchar(100);not null"`
+	Normalized string    `json:"-" gorm:"type:varchar(100);not null;uniqueIndex:idx_unit_alias_normalized"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (AcademicUnitAlias) TableName() string {
+	return "academic_unit_aliases"
+}
+
+// =================================================================
+// DTOs
+// =================================================================
+
+type FacultyRequest struct {
+	Code string `json:"code" binding:"required,max=20"`
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type DepartmentRequest struct {
+	FacultyID string `json:"faculty_id" binding:"required"`
+	Code      string `json:"code" binding:"required,max=20"`
+	Name      string `json:"name" binding:"required,max=100"`
+}
+
+type StudyProgramRequest struct {
+	DepartmentID string `json:"department_id" binding:"required"`
+	Code         string `json:"code" binding:"required,max=20"`
+	Name         string `json:"name" binding:"required,max=100"`
+	Degree       string `json:"degree" binding:"max=10"`
+}
+
+type AcademicUnitAliasRequest struct {
+	UnitType string `json:"unit_type" binding:"required,oneof=department study_program"`
+	UnitID   string `json:"unit_id" binding:"required"`
+	Alias    string `json:"alias" binding:"required,max=100"`
+}
+
+// UnmappedValue adalah teks bebas yang belum cocok dengan master data
+type UnmappedValue struct {
+	Value string `json:"value"`
+	Rows  int64  `json:"rows"`
+	// Ambiguous true bila teks cocok dengan lebih dari satu unit (perlu alias yang lebih spesifik)
+	Ambiguous bool `json:"ambiguous,omitempty"`
+}
+
+// AcademicUnitMappingResult adalah hasil pemetaan satu kolom teks bebas
+type AcademicUnitMappingResult struct {
+	MappedRows int64           `json:"mapped_rows"`
+	Unmapped   []UnmappedValue `json:"unmapped"`
+}
+
+// AcademicUnitMappingReport melaporkan pemetaan students.program_study dan lecturers.department.
+// Applied false berarti laporan hanya pratinjau (tidak ada baris yang diubah).
+type AcademicUnitMappingReport struct {
+	Applied       bool                      `json:"applied"`
+	StudyPrograms AcademicUnitMappingResult `json:"study_programs"`
+	Departments   AcademicUnitMappingResult `json:"departments"`
+}
+
+// =================================================================
+// PENCOCOKAN TEKS BEBAS
+// =================================================================
+
+// NormalizeUnitName menyamakan ejaan: huruf kecil, tanda baca jadi spasi, spasi berlebih dibuang.
+// "Teknik  Informatika", "teknik-informatika" dan "TEKNIK INFORMATIKA." menjadi sama.
+func NormalizeUnitName(value string) string {
+	fields := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// AcademicUnitMatcher memetakan teks bebas ke departemen/program studi lewat nama, kode, atau alias.
+// Dibangun sekali per operasi (import, migrasi) agar tidak ada query per baris.
+type AcademicUnitMatcher struct {
+	programs    map[string]*StudyProgram
+	departments map[string]*Department
+	ambiguous   map[string]bool // kunci "<unit_type>:<normalized>" yang cocok ke lebih dari satu unit
+}
+
+func NewAcademicUnitMatcher(programs []StudyProgram, departments []Department, aliases []AcademicUnitAlias) *AcademicUnitMatcher {
+	m := &AcademicUnitMatcher{
+		programs:    make(map[string]*StudyProgram),
+		departments: make(map[string]*Department),
+		ambiguous:   make(map[string]bool),
+	}
+	programByID := make(map[uuid.UUID]*StudyProgram, len(programs))
+	for i := range programs {
+		program := &programs[i]
+		programByID[program.ID] = program
+		m.addProgram(program.Name, program)
+		m.addProgram(program.Code, program)
+	}
+	departmentByID := make(map[uuid.UUID]*Department, len(departments))
+	for i := range departments {
+		department := &departments[i]
+		departmentByID[department.ID] = department
+		m.addDepartment(department.Name, department)
+		m.addDepartment(department.Code, department)
+	}
+	for _, alias := range aliases {
+		switch alias.UnitType {
+		case UnitTypeStudyProgram:
+			if program := programByID[alias.UnitID]; program != nil {
+				m.addProgram(alias.Alias, program)
+			}
+		case UnitTypeDepartment:
+			if department := departmentByID[alias.UnitID]; department != nil {
+				m.addDepartment(alias.Alias, department)
+			}
+		}
+	}
+	return m
+}
+
+func (m *AcademicUnitMatcher) addProgram(value string, program *StudyProgram) {
+	key := NormalizeUnitName(value)
+	if key == "" {
+		return
+	}
+	if existing, ok := m.programs[key]; ok && existing.ID != program.ID {
+		m.ambiguous[UnitTypeStudyProgram+":"+key] = true
+		return
+	}
+	m.programs[key] = program
+}
+
+func (m *AcademicUnitMatcher) addDepartment(value string, department *Department) {
+	key := NormalizeUnitName(value)
+	if key == "" {
+		return
+	}
+	if existing, ok := m.departments[key]; ok && existing.ID != department.ID {
+		m.ambiguous[UnitTypeDepartment+":"+key] = true
+		return
+	}
+	m.departments[key] = department
+}
+
+// StudyProgram mengembalikan program studi untuk teks bebas; ambiguous true bila teks cocok ke lebih dari satu
+func (m *AcademicUnitMatcher) StudyProgram(value string) (program *StudyProgram, ambiguous bool) {
+	key := NormalizeUnitName(value)
+	if m.ambiguous[UnitTypeStudyProgram+":"+key] {
+		return nil, true
+	}
+	return m.programs[key], false
+}
+
+// Department mengembalikan departemen untuk teks bebas; ambiguous true bila teks cocok ke lebih dari satu
+func (m *AcademicUnitMatcher) Department(value string) (department *Department, ambiguous bool) {
+	key := NormalizeUnitName(value)
+	if m.ambiguous[UnitTypeDepartment+":"+key] {
+		return nil, true
+	}
+	return m.departments[key], false
+}
+
+// LinkStudent mengisi StudyProgramID dari teks program_study dan menyeragamkan teksnya ke nama resmi.
+// Teks yang tidak cocok dibiarkan apa adanya (FK kosong) dan akan muncul di laporan pemetaan.
+func (m *AcademicUnitMatcher) LinkStudent(student *Student) {
+	student.StudyProgramID = nil
+	if program, _ := m.StudyProgram(student.ProgramStudy); program != nil {
+		student.StudyProgramID = &program.ID
+		student.ProgramStudy = program.Name
+	}
+}
+
+// LinkLecturer mengisi DepartmentID dari teks department dan menyeragamkan teksnya ke nama resmi
+func (m *AcademicUnitMatcher) LinkLecturer(lecturer *Lecturer) {
+	lecturer.DepartmentID = nil
+	if department, _ := m.Department(lecturer.Department); department != nil {
+		lecturer.DepartmentID = &department.ID
+		lecturer.Department = department.Name
+	}
+}
@@ -104,7 +104,9 @@ type AchievementDetailResponse struct {
 	SubmittedAt        *time.Time `json:"submitted_at,omitempty"`
 	VerifiedAt         *time.Time `json:"verified_at,omitempty"`
 	VerifiedBy         *uuid.UUID `json:"verified_by,omitempty"`
+	VerifiedOnBehalfOf *uuid.UUID `json:"verified_on_behalf_of,omitempty"`
 	RejectionNote      *string    `json:"rejection_note,omitempty"`
+	AcademicPeriodID   *uuid.UUID `json:"academic_period_id,omitempty"`
 
 	// MongoDB (Content Fields)
 	MongoAchievementID string             `json:"mongo_achievement_id"`
@@ -131,4 +133,30 @@ type AchievementUpdateRequest struct {
 }
 
 // Note: Hapus semua struct atau konstanta yang berhubungan dengan status/workflow
-// dari file ini, seperti AchievementStatus dan AchievementRejectRequest.
\ No newline at end of file
+// dari file ini, seperti AchievementStatus dan AchievementRejectRequest.
+
+// DetailsEventDate membaca details.eventDate dari dokumen MongoDB. Details disimpan lewat
+// konversi JSON sehingga tanggal bisa berupa string RFC3339, selain tipe tanggal BSON.
+func DetailsEventDate(details map[string]interface{}) *time.Time {
+	var eventDate time.Time
+	switch v := details["eventDate"].(type) {
+	case time.Time:
+		eventDate = v
+	case primitive.DateTime:
+		eventDate = v.Time()
+	case string:
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			if parsed, err = time.Parse("2006-01-02", v); err != nil {
+				return nil
+			}
+		}
+		eventDate = parsed
+	default:
+		return nil
+	}
+	if eventDate.IsZero() {
+		return nil
+	}
+	return &eventDate
+}
@@ -45,10 +45,26 @@ type AchievementReference struct {
 	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
 	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
 
-	// Validator (Dosen Wali/Admin)
+	// Validator (Dosen Wali/Admin): user ID yang benar-benar melakukan verify/reject
 	VerifiedBy     *uuid.UUID `gorm:"type:uuid" json:"verified_by,omitempty"`
 	VerifiedByUser *User      `gorm:"foreignKey:VerifiedBy" json:"verified_by_user,omitempty"`
+
+	// Diisi bila verify/reject dilakukan oleh delegate: user ID dosen wali (principal) yang diwakili
+	VerifiedOnBehalfOf     *uuid.UUID `gorm:"type:uuid" json:"verified_on_behalf_of,omitempty"`
+	VerifiedOnBehalfOfUser *User      `gorm:"foreignKey:VerifiedOnBehalfOf" json:"verified_on_behalf_of_user,omitempty"`
 	
+	// Diisi bila dosen wali mahasiswa berganti dengan kebijakan "retain": pengajuan SUBMITTED ini tetap
+	// diverifikasi dosen wali lama (lecturer ID). Dikosongkan lagi setiap kali prestasi diajukan ulang.
+	PinnedAdvisorID *uuid.UUID `gorm:"type:uuid;index" json:"pinned_advisor_id,omitempty"`
+
+	// Salinan details.eventDate dari MongoDB, dipakai untuk atribusi periode akademik
+	EventDate *time.Time `gorm:"index" json:"event_date,omitempty"`
+
+	// Periode akademik prestasi menurut tanggal acuan (lihat PeriodAttributionDate); kosong bila
+	// tanggal acuan belum ada atau tidak jatuh di periode mana pun
+	AcademicPeriodID *uuid.UUID      `gorm:"type:uuid;index" json:"academic_period_id,omitempty"`
+	AcademicPeriod   *AcademicPeriod `gorm:"foreignKey:AcademicPeriodID" json:"academic_period,omitempty"`
+
 	// Alasan penolakan
 	RejectionNote *string `gorm:"type:text" json:"rejection_note,omitempty"` 
 	
@@ -62,6 +78,22 @@ func (AchievementReference) TableName() string {
 	return "achievement_references"
 }
 
+// PeriodAttributionDate mengembalikan tanggal acuan periode akademik sesuai basis
+// (event_date atau submission_date). Bila tanggal utama belum ada, tanggal lainnya dipakai
+// sebagai cadangan; nil bila keduanya kosong (mis. draft tanpa tanggal event).
+func (ref *AchievementReference) PeriodAttributionDate(basis string) *time.Time {
+	if basis == PeriodBySubmissionDate {
+		if ref.SubmittedAt != nil {
+			return ref.SubmittedAt
+		}
+		return ref.EventDate
+	}
+	if ref.EventDate != nil {
+		return ref.EventDate
+	}
+	return ref.SubmittedAt
+}
+
 // =================================================================
 // DTOs yang BERHUBUNGAN DENGAN WORKFLOW (PGSQL)
 // =================================================================
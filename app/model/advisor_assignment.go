@@ -0,0 +1,166 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kebijakan untuk prestasi yang sedang SUBMITTED saat dosen wali mahasiswa berganti
+const (
+	// InFlightTransfer: pengajuan berpindah ke dosen wali baru (perilaku default)
+	InFlightTransfer = "transfer"
+	// InFlightRetain: pengajuan tetap diverifikasi dosen wali lama sampai diputuskan
+	InFlightRetain = "retain"
+	// InFlightReset: pengajuan dikembalikan ke DRAFT agar mahasiswa mengajukan ulang ke dosen wali baru
+	InFlightReset = "reset"
+)
+
+// IsValidInFlightPolicy memeriksa nilai kebijakan in-flight
+func IsValidInFlightPolicy(policy string) bool {
+	switch policy {
+	case InFlightTransfer, InFlightRetain, InFlightReset:
+		return true
+	}
+	return false
+}
+
+// AdvisorAssignment adalah riwayat dosen wali seorang mahasiswa. Baris yang masih berlaku
+// memiliki EffectiveTo NULL; setiap pergantian menutup baris lama dan membuat baris baru.
+type AdvisorAssignment struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	StudentID uuid.UUID `json:"student_id" gorm:"type:uuid;not null;index"`
+	// AdvisorID NULL berarti mahasiswa dilepas dari dosen wali
+	AdvisorID         *uuid.UUID `json:"advisor_id" gorm:"type:uuid;index"`
+	Advisor           *Lecturer  `json:"advisor,omitempty" gorm:"foreignKey:AdvisorID"`
+	PreviousAdvisorID *uuid.UUID `json:"previous_advisor_id,omitempty" gorm:"type:uuid"`
+
+	EffectiveFrom time.Time  `json:"effective_from" gorm:"not null"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+
+	Reason         string `json:"reason,omitempty" gorm:"type:text"`
+	InFlightPolicy string `json:"in_flight_policy" gorm:"type:varchar(20);not null"`
+	// InFlightCount adalah jumlah prestasi SUBMITTED yang terdampak kebijakan in-flight
+	InFlightCount int        `json:"in_flight_count" gorm:"not null;default:0"`
+	AssignedBy    *uuid.UUID `json:"assigned_by,omitempty" gorm:"type:uuid"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (AdvisorAssignment) TableName() string {
+	return "advisor_assignments"
+}
+
+// AdvisorChange adalah satu pergantian dosen wali yang akan ditulis repository
+type AdvisorChange struct {
+	StudentID         uuid.UUID
+	PreviousAdvisorID *uuid.UUID
+	AdvisorID         *uuid.UUID
+}
+
+// AdvisorChangeOptions berlaku untuk semua pergantian dalam satu operasi
+type AdvisorChangeOptions struct {
+	EffectiveFrom  time.Time
+	Reason         string
+	InFlightPolicy string
+	AssignedBy     *uuid.UUID
+	// OnlyUnassigned: pergantian hanya berlaku bila mahasiswa masih tanpa dosen wali saat ditulis
+	OnlyUnassigned bool
+	// MaxAdvisees > 0: batas bimbingan aktif dosen tujuan, dicek ulang di dalam transaksi
+	MaxAdvisees int
+}
+
+// AdvisorAssignRequest adalah body PUT /students/{id}/advisor
+type AdvisorAssignRequest struct {
+	AdvisorID      string     `json:"advisor_id" binding:"required"`
+	EffectiveFrom  *time.Time `json:"effective_from"`
+	Reason         string     `json:"reason"`
+	InFlightPolicy string     `json:"in_flight_policy"` // transfer | retain | reset (default dari konfigurasi)
+}
+
+// BulkAdvisorAssignRequest memilih mahasiswa lewat StudentIDs ATAU ProgramStudy/AcademicYear (angkatan)
+type BulkAdvisorAssignRequest struct {
+	AdvisorID      string     `json:"advisor_id" binding:"required"`
+	StudentIDs     []string   `json:"student_ids"`
+	ProgramStudy   string     `json:"program_study"`
+	AcademicYear   string     `json:"academic_year"`
+	OnlyUnassigned bool       `json:"only_unassigned"`
+	EffectiveFrom  *time.Time `json:"effective_from"`
+	Reason         string     `json:"reason"`
+	InFlightPolicy string     `json:"in_flight_policy"`
+	DryRun         bool       `json:"dry_run"`
+}
+
+// BulkAdvisorAssignResult merangkum hasil penugasan massal
+type BulkAdvisorAssignResult struct {
+	Matched   int  `json:"matched"`
+	Changed   int  `json:"changed"`
+	Unchanged int  `json:"unchanged"` // sudah dibimbing dosen wali yang sama
+	InFlight  int  `json:"in_flight"` // prestasi SUBMITTED yang terdampak kebijakan in-flight
+	DryRun    bool `json:"dry_run"`
+	// NotFound berisi student_ids yang tidak ditemukan (mode daftar)
+	NotFound []string `json:"not_found,omitempty"`
+}
+
+// AdvisorProposalRequest meminta usulan dosen wali untuk mahasiswa yang belum punya dosen wali.
+// Filter program_study/academic_year opsional; kosong = semua mahasiswa tanpa dosen wali.
+type AdvisorProposalRequest struct {
+	ProgramStudy string `json:"program_study"`
+	AcademicYear string `json:"academic_year"`
+	// MaxAdvisees menimpa batas bimbingan per dosen dari konfigurasi (0 = pakai konfigurasi)
+	MaxAdvisees int `json:"max_advisees"`
+	// CrossDepartment mengizinkan dosen dari department lain bila dosen sejurusan penuh/tidak ada
+	CrossDepartment bool `json:"cross_department"`
+}
+
+// AdvisorProposalItem adalah satu usulan pasangan mahasiswa - dosen wali
+type AdvisorProposalItem struct {
+	StudentID    uuid.UUID `json:"student_id"`
+	NIM          string    `json:"nim"`
+	FullName     string    `json:"full_name"`
+	ProgramStudy string    `json:"program_study"`
+	AdvisorID    uuid.UUID `json:"advisor_id"`
+	AdvisorName  string    `json:"advisor_name"`
+	Department   string    `json:"department"`
+	// SameDepartment false berarti usulan lintas department (hanya bila cross_department)
+	SameDepartment bool `json:"same_department"`
+}
+
+// AdvisorProposalSkip adalah mahasiswa yang tidak mendapat usulan beserta alasannya
+type AdvisorProposalSkip struct {
+	StudentID    uuid.UUID `json:"student_id"`
+	NIM          string    `json:"nim"`
+	ProgramStudy string    `json:"program_study"`
+	Reason       string    `json:"reason"`
+}
+
+// AdvisorLoad adalah jumlah bimbingan seorang dosen sebelum dan sesudah usulan diterapkan
+type AdvisorLoad struct {
+	AdvisorID  uuid.UUID `json:"advisor_id"`
+	FullName   string    `json:"full_name"`
+	Department string    `json:"department"`
+	Current    int       `json:"current"`
+	Proposed   int       `json:"proposed"`
+}
+
+// AdvisorProposal adalah hasil usulan; Assignments dapat langsung dikirim ke endpoint apply
+type AdvisorProposal struct {
+	MaxAdvisees int                   `json:"max_advisees"` // 0 = tanpa batas
+	Assignments []AdvisorProposalItem `json:"assignments"`
+	Skipped     []AdvisorProposalSkip `json:"skipped"`
+	Loads       []AdvisorLoad         `json:"loads"`
+}
+
+// AdvisorProposalPair adalah satu pasangan yang akan diterapkan (boleh diubah admin setelah review)
+type AdvisorProposalPair struct {
+	StudentID string `json:"student_id" binding:"required"`
+	AdvisorID string `json:"advisor_id" binding:"required"`
+}
+
+// ApplyAdvisorProposalRequest menerapkan usulan dalam satu transaksi
+type ApplyAdvisorProposalRequest struct {
+	Assignments []AdvisorProposalPair `json:"assignments" binding:"required,min=1,dive"`
+	// MaxAdvisees diperiksa ulang saat apply (0 = pakai konfigurasi)
+	MaxAdvisees   int        `json:"max_advisees"`
+	EffectiveFrom *time.Time `json:"effective_from"`
+	Reason        string     `json:"reason"`
+}
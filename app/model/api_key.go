@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey adalah kredensial jangka panjang milik service account (mis. website fakultas, sync SIAKAD).
+// Hanya hash SHA-256 yang disimpan; plaintext hanya ditampilkan sekali saat key dibuat.
+type APIKey struct {
+	ID               uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ServiceAccountID uuid.UUID `json:"service_account_id" gorm:"type:uuid;not null;index"`
+	Name             string    `json:"name" gorm:"type:varchar(100);not null"`
+	Prefix           string    `json:"prefix" gorm:"type:varchar(20);not null"` // Untuk identifikasi di UI/log
+	KeyHash          string    `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+
+	// Scopes membatasi permission key; permission efektif = scopes ∩ permission role service account
+	Scopes []string `json:"scopes" gorm:"type:text;serializer:json"`
+	// AllowedIPs berisi IP atau CIDR; kosong = semua IP diizinkan
+	AllowedIPs []string `json:"allowed_ips" gorm:"type:text;serializer:json"`
+
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty" gorm:"type:varchar(45)"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy  uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsUsableAt: key belum dicabut dan belum kedaluwarsa
+func (k *APIKey) IsUsableAt(at time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || at.Before(*k.ExpiresAt))
+}
+
+type ServiceAccountCreateRequest struct {
+	Username string `json:"username" binding:"required"`
+	FullName string `json:"full_name" binding:"required"`
+	// Opsional; default <username>@service-account.local
+	Email  string `json:"email" binding:"omitempty,email"`
+	RoleID string `json:"role_id" binding:"required"`
+}
+
+type APIKeyCreateRequest struct {
+	Name       string     `json:"name" binding:"required"`
+	Scopes     []string   `json:"scopes" binding:"required,min=1"`
+	AllowedIPs []string   `json:"allowed_ips"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+// APIKeyCreateResponse: Key (plaintext) hanya dikembalikan sekali
+type APIKeyCreateResponse struct {
+	Key    string `json:"key"`
+	APIKey APIKey `json:"api_key"`
+}
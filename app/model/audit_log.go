@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Jenis aksi yang dicatat di audit log
+const (
+	AuditActionImpersonationStart   = "impersonation.start"
+	AuditActionImpersonationRequest = "impersonation.request"
+)
+
+// AuditLog mencatat aksi sensitif. ActorID selalu user asli (mis. admin yang melakukan impersonation),
+// SubjectUserID adalah user yang identitasnya dipakai / dikenai aksi.
+type AuditLog struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Action        string     `json:"action" gorm:"type:varchar(50);not null;index"`
+	ActorID       uuid.UUID  `json:"actor_id" gorm:"type:uuid;not null;index"`
+	SubjectUserID *uuid.UUID `json:"subject_user_id,omitempty" gorm:"type:uuid;index"`
+	Method        string     `json:"method,omitempty" gorm:"type:varchar(10)"`
+	Path          string     `json:"path,omitempty" gorm:"type:varchar(255)"`
+	StatusCode    int        `json:"status_code,omitempty"`
+	IPAddress     string     `json:"ip_address" gorm:"type:varchar(45)"`
+	Detail        string     `json:"detail,omitempty" gorm:"type:text"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditLogFilter dipakai GET /admin/audit-logs (field kosong = tidak difilter)
+type AuditLogFilter struct {
+	ActorID       *uuid.UUID
+	SubjectUserID *uuid.UUID
+	Action        string
+	Limit         int
+}
+
+// ImpersonationRequest: alasan wajib diisi agar jejak audit bisa dipertanggungjawabkan
+type ImpersonationRequest struct {
+	Reason string `json:"reason" binding:"required,min=5"`
+}
+
+type ImpersonationResponse struct {
+	Token            string              `json:"token"`
+	ExpiresIn        int                 `json:"expires_in"` // detik
+	ImpersonatorID   uuid.UUID           `json:"impersonator_id"`
+	ImpersonatedUser UserProfileResponse `json:"impersonated_user"`
+	WritesAllowed    bool                `json:"writes_allowed"`
+}
@@ -5,10 +5,27 @@ import (
 	"github.com/google/uuid"
 )
 
+// Jenis token yang diterbitkan sistem. Token hanya valid untuk kegunaannya sendiri
+// (mis. refresh token dan MFA challenge token tidak bisa dipakai sebagai access token).
+const (
+	TokenTypeAccess       = "access"
+	TokenTypeRefresh      = "refresh"
+	TokenTypeMFAChallenge = "mfa_challenge"
+	TokenTypeOIDCState    = "oidc_state"
+)
+
 type JwtCustomClaims struct {
 	UserID      uuid.UUID `json:"user_id"`
 	Role        string    `json:"role"`
 	Permissions []string  `json:"permissions"`
+	TokenType   string    `json:"token_type"`
+	MFAVerified bool      `json:"mfa_verified,omitempty"`
+	// SecurityVersion harus sama dengan users.security_version; bila tidak, token dianggap dicabut
+	SecurityVersion int `json:"sv"`
+	// ImpersonatorID terisi bila token diterbitkan lewat impersonation; UserID adalah user yang ditiru
+	ImpersonatorID *uuid.UUID `json:"imp,omitempty"`
+	// SessionID menautkan token ke baris user_sessions; token tanpa sid (lama/impersonation) tidak terikat sesi
+	SessionID *uuid.UUID `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VerificationDelegation memberi wewenang verifikasi sementara dari dosen wali (principal)
+// kepada dosen lain (delegate) selama rentang waktu tertentu, mis. saat principal cuti.
+type VerificationDelegation struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	PrincipalID uuid.UUID `json:"principal_id" gorm:"type:uuid;not null;index"`
+	Principal   *Lecturer `json:"principal,omitempty" gorm:"foreignKey:PrincipalID"`
+	DelegateID  uuid.UUID `json:"delegate_id" gorm:"type:uuid;not null;index"`
+	Delegate    *Lecturer `json:"delegate,omitempty" gorm:"foreignKey:DelegateID"`
+
+	StartsAt time.Time `json:"starts_at" gorm:"not null;index"`
+	EndsAt   time.Time `json:"ends_at" gorm:"not null;index"`
+	Reason   string    `json:"reason" gorm:"type:text"`
+
+	CreatedBy uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"` // User yang memberi delegasi
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy *uuid.UUID `json:"revoked_by,omitempty" gorm:"type:uuid"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (VerificationDelegation) TableName() string {
+	return "verification_delegations"
+}
+
+// IsActiveAt: delegasi berlaku bila belum dicabut dan at berada di [StartsAt, EndsAt)
+func (d *VerificationDelegation) IsActiveAt(at time.Time) bool {
+	return d.RevokedAt == nil && !at.Before(d.StartsAt) && at.Before(d.EndsAt)
+}
+
+// DelegationFilter dipakai saat listing (field kosong = tidak difilter)
+type DelegationFilter struct {
+	LecturerID *uuid.UUID // principal ATAU delegate
+	ActiveAt   *time.Time
+}
+
+type DelegationCreateRequest struct {
+	// Hanya admin yang boleh mengisi principal_id; dosen selalu mendelegasikan wewenangnya sendiri
+	PrincipalID string    `json:"principal_id"`
+	DelegateID  string    `json:"delegate_id" binding:"required"`
+	StartsAt    time.Time `json:"starts_at" binding:"required"`
+	EndsAt      time.Time `json:"ends_at" binding:"required"`
+	Reason      string    `json:"reason"`
+}
@@ -0,0 +1,45 @@
+package model
+
+// Jenis data yang bisa diimpor massal dari CSV/XLSX
+const (
+	ImportKindStudents  = "students"
+	ImportKindLecturers = "lecturers"
+)
+
+// Aksi per baris pada laporan import
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionError  = "error"
+)
+
+// ImportFieldError adalah satu kesalahan validasi pada satu kolom
+type ImportFieldError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportRowResult adalah hasil validasi/penulisan satu baris spreadsheet.
+// Row adalah nomor baris di file (header = baris 1).
+type ImportRowResult struct {
+	Row    int                `json:"row"`
+	Key    string             `json:"key"` // NIM atau NIP
+	Action string             `json:"action"`
+	Errors []ImportFieldError `json:"errors,omitempty"`
+}
+
+// ImportReport adalah laporan per baris sebuah import. Committed false berarti tidak ada
+// data yang ditulis (dry-run, atau ada baris yang gagal validasi sehingga seluruh file ditolak).
+type ImportReport struct {
+	Kind      string            `json:"kind"`
+	DryRun    bool              `json:"dry_run"`
+	Upsert    bool              `json:"upsert"`
+	Committed bool              `json:"committed"`
+	TotalRows int               `json:"total_rows"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowResult `json:"rows"`
+	// ErrorFileID dipakai untuk mengunduh CSV baris gagal lewat GET /imports/errors/{id}
+	ErrorFileID string `json:"error_file_id,omitempty"`
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Lecturer struct {
@@ -12,7 +13,12 @@ type Lecturer struct {
 	User       *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	LecturerID string    `json:"lecturer_id" gorm:"type:varchar(20);unique;not null"`
 	Department string    `json:"department" gorm:"type:varchar(100)"`
-	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	// DepartmentID menunjuk master data; Department disimpan sebagai nama resminya
+	DepartmentID   *uuid.UUID  `json:"department_id" gorm:"type:uuid;index"`
+	DepartmentUnit *Department `json:"department_unit,omitempty" gorm:"foreignKey:DepartmentID"`
+	CreatedAt      time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	// Soft delete agar riwayat dosen wali dan pembimbingan tetap bisa ditelusuri
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 func (Lecturer) TableName() string {
@@ -20,14 +26,16 @@ func (Lecturer) TableName() string {
 }
 
 type LecturerCreateRequest struct {
-	UserID     string `json:"user_id" binding:"required"`
-	LecturerID string `json:"lecturer_id" binding:"required"` // NIP
-	Department string `json:"department"`
+	UserID       string `json:"user_id" binding:"required"`
+	LecturerID   string `json:"lecturer_id" binding:"required"` // NIP
+	Department   string `json:"department"`
+	DepartmentID string `json:"department_id"` // Diutamakan daripada department bila diisi
 }
 
 type LecturerUpdateRequest struct {
-	LecturerID string `json:"lecturer_id"`
-	Department string `json:"department"`
+	LecturerID   string `json:"lecturer_id"`
+	Department   string `json:"department"`
+	DepartmentID string `json:"department_id"`
 }
 
 // ===================================
@@ -39,4 +47,5 @@ type Advisee struct {
 	FullName     string    `json:"full_name"` // Diambil dari User yang di-preload
 	ProgramStudy string    `json:"program_study"`
 	AcademicYear string    `json:"academic_year"`
-}
\ No newline at end of file
+	Status       string    `json:"status"`
+}
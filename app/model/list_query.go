@@ -0,0 +1,34 @@
+package model
+
+// Batas ukuran halaman untuk semua endpoint list
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ListQuery adalah parameter list yang seragam untuk endpoint users, students, dan lecturers.
+// Bila Cursor diisi, pagination memakai keyset (Page diabaikan) sehingga tetap cepat di halaman jauh.
+type ListQuery struct {
+	Page   int
+	Limit  int
+	Cursor string
+	Search string
+	// Sort adalah nama field (bukan kolom); SortDesc true bila diawali "-" (mis. sort=-created_at)
+	Sort     string
+	SortDesc bool
+	// Filters berisi nilai mentah per nama filter; tiap repository memvalidasi filter yang didukungnya
+	Filters map[string]string
+}
+
+// PageMeta adalah metadata paging yang dikembalikan di field "meta" utils.Response
+type PageMeta struct {
+	Limit int    `json:"limit"`
+	Sort  string `json:"sort"`
+	// Mode offset
+	Page       int    `json:"page,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+	TotalPages *int   `json:"total_pages,omitempty"`
+	// Mode cursor: kirim NextCursor sebagai ?cursor= untuk halaman berikutnya
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
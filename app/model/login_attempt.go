@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAttempt mencatat satu percobaan login yang GAGAL.
+// Dipakai untuk menghitung progressive delay dan lockout per akun maupun per IP.
+// Baris milik sebuah akun dihapus ketika login berhasil atau ketika admin melakukan unlock.
+type LoginAttempt struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID     *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+	Identifier string     `json:"identifier" gorm:"type:varchar(100);index"`
+	IPAddress  string     `json:"ip_address" gorm:"type:varchar(45);index"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+// LoginFailureStats adalah hasil agregasi percobaan gagal dalam satu window waktu
+type LoginFailureStats struct {
+	Count         int64      `json:"count"`
+	LastAttemptAt *time.Time `json:"last_attempt_at"`
+}
@@ -28,4 +28,31 @@ type RolePermission struct {
 
 func (RolePermission) TableName() string {
 	return "role_permissions"
-}
\ No newline at end of file
+}
+
+// SeededRolePermission mencatat permission role default yang pernah diberikan seeder.
+// Relasi yang sudah tercatat tidak diberikan ulang, sehingga permission yang dicabut admin
+// lewat /roles tidak kembali saat restart; permission katalog baru tetap diberikan sekali.
+type SeededRolePermission struct {
+	RoleName       string    `gorm:"type:varchar(50);primaryKey"`
+	PermissionName string    `gorm:"type:varchar(100);primaryKey"`
+	SeededAt       time.Time `gorm:"autoCreateTime"`
+}
+
+func (SeededRolePermission) TableName() string {
+	return "seeded_role_permissions"
+}
+
+// ===================================
+// DTO Permission Management
+// ===================================
+type PermissionCreateRequest struct {
+	Name        string `json:"name" binding:"required"` // Format: resource:action
+	Resource    string `json:"resource" binding:"required"`
+	Action      string `json:"action" binding:"required"`
+	Description string `json:"description"`
+}
+
+type PermissionUpdateRequest struct {
+	Description string `json:"description"`
+}
@@ -0,0 +1,169 @@
+package model
+
+import "strings"
+
+// =================================================================
+// KATALOG PERMISSION & ROLE DEFAULT (Sumber kebenaran untuk seeding)
+// =================================================================
+
+// Nama role default. Nama ini juga dipakai service untuk mengenali profil (mahasiswa/dosen).
+const (
+	RoleAdmin     = "Admin"
+	RoleMahasiswa = "Mahasiswa"
+	RoleDosenWali = "Dosen Wali"
+)
+
+// PermissionDefinition adalah satu entri katalog. Name selalu berformat resource:action.
+type PermissionDefinition struct {
+	Resource    string
+	Action      string
+	Description string
+}
+
+func (d PermissionDefinition) Name() string {
+	return d.Resource + ":" + d.Action
+}
+
+// RoleDefinition mendeskripsikan role default beserta permission minimalnya.
+// Setiap pasangan role-permission default diberikan SEKALI (dicatat di seeded_role_permissions):
+// permission yang kemudian dicabut admin tidak ditambahkan lagi saat start berikutnya, dan
+// permission tambahan yang diberikan admin lewat /roles tidak pernah dicabut.
+type RoleDefinition struct {
+	Name           string
+	Description    string
+	AllPermissions bool // Admin otomatis menerima semua permission di katalog
+	Permissions    []string
+}
+
+// PermissionCatalog berisi semua permission yang direferensikan oleh route
+var PermissionCatalog = []PermissionDefinition{
+	// Achievements
+	{"achievement", "read_own", "Melihat prestasi milik sendiri"},
+	{"achievement", "read_list", "Melihat daftar prestasi mahasiswa"},
+	{"achievement", "create", "Membuat prestasi baru"},
+	{"achievement", "update", "Mengubah prestasi (draft/rejected)"},
+	{"achievement", "delete", "Menghapus prestasi (draft/rejected)"},
+	{"achievement", "submit", "Mengajukan prestasi untuk diverifikasi"},
+	{"achievement", "verify", "Memverifikasi prestasi"},
+	{"achievement", "reject", "Menolak prestasi"},
+	{"achievement", "read_history", "Melihat riwayat status prestasi"},
+	{"achievement", "upload_attachment", "Mengunggah bukti prestasi"},
+
+	// Users
+	{"user", "read", "Melihat daftar dan detail user"},
+	{"user", "create", "Membuat user baru"},
+	{"user", "provision", "Membuat user beserta profil mahasiswa/dosen dalam satu transaksi"},
+	{"user", "update", "Mengubah data user"},
+	{"user", "delete", "Menghapus user (soft delete)"},
+	{"user", "deactivate", "Menonaktifkan dan mengaktifkan kembali user"},
+	{"user", "assign_role", "Mengubah role user"},
+	{"user", "manage_security", "Membuka lockout login dan mereset MFA user"},
+	{"user", "impersonate", "Melihat sistem sebagai user lain (view as user)"},
+
+	// Service accounts (integrasi mesin)
+	{"service_account", "manage", "Mengelola service account dan API key-nya"},
+
+	// Students
+	{"student", "read", "Melihat daftar dan detail mahasiswa"},
+	{"student", "create", "Membuat profil mahasiswa"},
+	{"student", "update", "Mengubah profil mahasiswa"},
+	{"student", "delete", "Menghapus profil mahasiswa"},
+	{"student", "assign_advisor", "Menetapkan dosen wali mahasiswa"},
+	{"student", "manage_status", "Mengubah status studi mahasiswa (aktif, cuti, lulus, keluar)"},
+	{"student", "import", "Import massal mahasiswa (user + profil) dari CSV/XLSX"},
+
+	// Lecturers
+	{"lecturer", "read", "Melihat daftar dan detail dosen"},
+	{"lecturer", "create", "Membuat profil dosen"},
+	{"lecturer", "update", "Mengubah profil dosen"},
+	{"lecturer", "delete", "Menghapus profil dosen"},
+	{"lecturer", "read_advisees", "Melihat mahasiswa bimbingan dosen"},
+	{"lecturer", "import", "Import massal dosen (user + profil) dari CSV/XLSX"},
+
+	// Academic units (master data)
+	{"academic_unit", "read", "Melihat master data fakultas, departemen, dan program studi"},
+	{"academic_unit", "manage", "Mengelola master data akademik dan pemetaan teks bebas program studi/departemen"},
+
+	// Academic periods
+	{"academic_period", "read", "Melihat periode akademik (semester)"},
+	{"academic_period", "manage", "Mengelola periode akademik dan periode aktif"},
+
+	// Verification delegation
+	{"delegation", "manage", "Mendelegasikan wewenang verifikasi ke dosen lain untuk sementara"},
+
+	// Reports
+	{"report", "read_statistics", "Melihat statistik prestasi"},
+	{"report", "read_student", "Melihat laporan prestasi mahasiswa"},
+
+	// Roles & Permissions
+	{"role", "read", "Melihat role dan pemegangnya"},
+	{"role", "manage", "Membuat, mengubah, menghapus role dan permission-nya"},
+	{"permission", "read", "Melihat katalog permission"},
+	{"permission", "manage", "Membuat, mengubah, menghapus permission"},
+
+	// System
+	{"route", "read", "Melihat daftar endpoint beserta permission yang dibutuhkan"},
+	{"audit", "read", "Melihat audit log aksi sensitif"},
+}
+
+// DefaultRoles adalah role yang dijamin ada setelah seeding
+var DefaultRoles = []RoleDefinition{
+	{
+		Name:           RoleAdmin,
+		Description:    "Administrator sistem",
+		AllPermissions: true,
+	},
+	{
+		Name:        RoleMahasiswa,
+		Description: "Mahasiswa pelapor prestasi",
+		Permissions: []string{
+			"achievement:read_own",
+			"achievement:create",
+			"achievement:update",
+			"achievement:delete",
+			"achievement:submit",
+			"achievement:read_history",
+			"achievement:upload_attachment",
+			"academic_period:read",
+		},
+	},
+	{
+		Name:        RoleDosenWali,
+		Description: "Dosen wali yang memverifikasi prestasi mahasiswa bimbingan",
+		Permissions: []string{
+			"achievement:read_list",
+			"achievement:verify",
+			"achievement:reject",
+			"achievement:read_history",
+			"student:read",
+			"lecturer:read",
+			"lecturer:read_advisees",
+			"academic_unit:read",
+			"academic_period:read",
+			"report:read_statistics",
+			"report:read_student",
+			"delegation:manage",
+		},
+	},
+}
+
+// IsDefaultRoleName memeriksa apakah nama (tidak case-sensitive) adalah role default.
+// Nama role default dipakai policy, profil dan proteksi admin, sehingga tidak boleh diganti/dihapus.
+func IsDefaultRoleName(name string) bool {
+	for _, def := range DefaultRoles {
+		if strings.EqualFold(strings.TrimSpace(name), def.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCatalogPermission memeriksa apakah nama permission terdaftar di katalog
+func IsCatalogPermission(name string) bool {
+	for _, def := range PermissionCatalog {
+		if def.Name() == name {
+			return true
+		}
+	}
+	return false
+}
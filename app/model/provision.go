@@ -0,0 +1,56 @@
+package model
+
+// Jenis profil yang melekat pada role (lihat ProfileKindForRole)
+const (
+	ProfileKindStudent  = "student"
+	ProfileKindLecturer = "lecturer"
+)
+
+// ProfileKindForRole menentukan profil yang wajib dimiliki user dengan role tersebut.
+// Nama lama ("Student", "Lecturer", "Dosen") tetap dikenali; role lain tidak memiliki profil.
+func ProfileKindForRole(roleName string) string {
+	switch roleName {
+	case RoleMahasiswa, "Student":
+		return ProfileKindStudent
+	case RoleDosenWali, "Lecturer", "Dosen":
+		return ProfileKindLecturer
+	}
+	return ""
+}
+
+// ProvisionRecord adalah satu user beserta profilnya yang ditulis dalam satu transaksi.
+// ID kosong (uuid.Nil) berarti record baru; selain itu record yang ada diperbarui.
+type ProvisionRecord struct {
+	User     *User
+	Student  *Student
+	Lecturer *Lecturer
+}
+
+// ProvisionUserRequest membuat user dan profilnya sekaligus. Isi tepat satu dari Student/Lecturer
+// sesuai role (Mahasiswa -> student, Dosen Wali -> lecturer); role lain tanpa profil.
+type ProvisionUserRequest struct {
+	UserCreateRequest
+	Student  *ProvisionStudentProfile  `json:"student,omitempty"`
+	Lecturer *ProvisionLecturerProfile `json:"lecturer,omitempty"`
+}
+
+type ProvisionStudentProfile struct {
+	NIM            string `json:"nim" binding:"required,max=20"`
+	ProgramStudy   string `json:"program_study" binding:"required_without=StudyProgramID,max=100"`
+	StudyProgramID string `json:"study_program_id"` // Diutamakan daripada program_study bila diisi
+	AcademicYear   string `json:"academic_year" binding:"required,max=10"`
+	AdvisorID      string `json:"advisor_id"` // opsional, UUID lecturer
+}
+
+type ProvisionLecturerProfile struct {
+	LecturerID   string `json:"lecturer_id" binding:"required,max=20"` // NIP
+	Department   string `json:"department" binding:"max=100"`
+	DepartmentID string `json:"department_id"` // Diutamakan daripada department bila diisi
+}
+
+// ProvisionUserResponse adalah hasil provisioning: user beserta profil yang dibuat
+type ProvisionUserResponse struct {
+	User     *User     `json:"user"`
+	Student  *Student  `json:"student,omitempty"`
+	Lecturer *Lecturer `json:"lecturer,omitempty"`
+}
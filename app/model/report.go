@@ -29,7 +29,7 @@ type AchievementStatisticsResponse struct {
 	TotalAchievementCount int                    `json:"total_achievement_count"`
 	ByType                []StatisticsByGroup    `json:"by_type"`      // Total prestasi per tipe
 	ByLevel               []StatisticsByGroup    `json:"by_level"`     // Distribusi tingkat kompetisi
-	ByPeriod              []StatisticsByGroup    `json:"by_period"`    // Total prestasi per periode (tahun event)
+	ByPeriod              []StatisticsByGroup    `json:"by_period"`    // Total prestasi per periode akademik (nama periode, mis. "Ganjil 2024/2025")
 	TopStudents           []TopStudentDetail     `json:"top_students"` // Top mahasiswa berprestasi
 }
 
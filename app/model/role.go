@@ -21,4 +21,23 @@ type Role struct {
 
 func (Role) TableName() string {
 	return "roles"
-}
\ No newline at end of file
+}
+
+// ===================================
+// DTO Role Management
+// ===================================
+type RoleCreateRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Description   string   `json:"description"`
+	PermissionIDs []string `json:"permission_ids"`
+}
+
+type RoleUpdateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type RoleAssignPermissionsRequest struct {
+	// Daftar lengkap permission role (menggantikan yang lama)
+	PermissionIDs []string `json:"permission_ids" binding:"required"`
+}
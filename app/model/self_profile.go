@@ -0,0 +1,138 @@
+package model
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// =================================================================
+// SELF-SERVICE PROFILE (/me)
+// =================================================================
+
+// Field profil yang bisa diubah pemiliknya sendiri
+const (
+	SelfFieldContactEmail = "contact_email"
+	SelfFieldPhone        = "phone"
+	SelfFieldPhoto        = "photo" // hanya lewat POST/DELETE /me/photo
+	SelfFieldBio          = "bio"
+)
+
+// SelfProfileRules adalah aturan per jenis profil: field yang boleh diubah sendiri dan field yang
+// dikenal tetapi hanya boleh diubah admin (dijawab 403, bukan 400 field tidak dikenal).
+type SelfProfileRules struct {
+	Editable  []string `json:"editable_fields"`
+	AdminOnly []string `json:"admin_only_fields"`
+}
+
+// adminOnlyAccountFields berlaku untuk semua role: identitas login dan hak akses
+var adminOnlyAccountFields = []string{"username", "email", "full_name", "role_id", "is_active"}
+
+var selfProfileRules = map[string]SelfProfileRules{
+	ProfileKindStudent: {
+		Editable:  []string{SelfFieldContactEmail, SelfFieldPhone, SelfFieldPhoto, SelfFieldBio},
+		AdminOnly: []string{"nim", "program_study", "study_program_id", "academic_year", "advisor_id", "status"},
+	},
+	ProfileKindLecturer: {
+		Editable:  []string{SelfFieldContactEmail, SelfFieldPhone, SelfFieldPhoto, SelfFieldBio},
+		AdminOnly: []string{"lecturer_id", "department", "department_id"},
+	},
+	// Role tanpa profil mahasiswa/dosen (mis. Admin)
+	"": {
+		Editable: []string{SelfFieldContactEmail, SelfFieldPhone, SelfFieldPhoto, SelfFieldBio},
+	},
+}
+
+// SelfProfileRulesFor mengembalikan aturan untuk jenis profil (lihat ProfileKindForRole)
+func SelfProfileRulesFor(profileKind string) SelfProfileRules {
+	rules := selfProfileRules[profileKind]
+	adminOnly := append(append([]string{}, adminOnlyAccountFields...), rules.AdminOnly...)
+	sort.Strings(adminOnly)
+	return SelfProfileRules{Editable: rules.Editable, AdminOnly: adminOnly}
+}
+
+func (r SelfProfileRules) CanEdit(field string) bool {
+	return containsField(r.Editable, field)
+}
+
+func (r SelfProfileRules) IsAdminOnly(field string) bool {
+	return containsField(r.AdminOnly, field)
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// SelfProfileUpdateRequest: DTO untuk PUT /me. Field yang tidak dikirim tidak diubah;
+// string kosong mengosongkan field (karena itu format email dicek di Validate, bukan tag binding).
+type SelfProfileUpdateRequest struct {
+	ContactEmail *string `json:"contact_email" binding:"omitempty,max=100"`
+	Phone        *string `json:"phone" binding:"omitempty,max=30"`
+	Bio          *string `json:"bio" binding:"omitempty,max=1000"`
+}
+
+// Trim merapikan spasi di tepi setiap field yang dikirim
+func (req *SelfProfileUpdateRequest) Trim() {
+	for _, field := range []*string{req.ContactEmail, req.Phone, req.Bio} {
+		if field != nil {
+			*field = strings.TrimSpace(*field)
+		}
+	}
+}
+
+// phonePattern: angka dengan awalan + opsional; spasi, tanda hubung dan kurung diperbolehkan
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{5,28}$`)
+
+// Validate memeriksa format yang tidak tercakup tag binding
+func (req SelfProfileUpdateRequest) Validate() error {
+	if req.ContactEmail != nil && *req.ContactEmail != "" {
+		addr, err := mail.ParseAddress(*req.ContactEmail)
+		if err != nil || addr.Address != *req.ContactEmail {
+			return fmt.Errorf("contact_email must be a valid email address")
+		}
+	}
+	if req.Phone != nil && *req.Phone != "" && !phonePattern.MatchString(*req.Phone) {
+		return fmt.Errorf("phone must contain digits with an optional leading +")
+	}
+	return nil
+}
+
+// Fields mengembalikan kolom yang akan ditulis, hanya untuk field yang dikirim
+func (req SelfProfileUpdateRequest) Fields() map[string]interface{} {
+	fields := map[string]interface{}{}
+	if req.ContactEmail != nil {
+		fields["contact_email"] = strings.ToLower(*req.ContactEmail)
+	}
+	if req.Phone != nil {
+		fields["phone"] = *req.Phone
+	}
+	if req.Bio != nil {
+		fields["bio"] = *req.Bio
+	}
+	return fields
+}
+
+// SelfProfileResponse adalah profil lengkap milik user yang sedang login
+type SelfProfileResponse struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	FullName     string    `json:"full_name"`
+	Email        string    `json:"email"`
+	Role         string    `json:"role"`
+	ContactEmail string    `json:"contact_email"`
+	Phone        string    `json:"phone"`
+	PhotoURL     string    `json:"photo_url"`
+	Bio          string    `json:"bio"`
+	Student      *Student  `json:"student,omitempty"`
+	Lecturer     *Lecturer `json:"lecturer,omitempty"`
+	SelfProfileRules
+}
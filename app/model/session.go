@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSession adalah satu login (perangkat/browser). ID-nya ditanam di access & refresh token
+// (claim "sid") sehingga sesi dapat dicabut satu per satu tanpa mengganggu sesi lain.
+type UserSession struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	UserAgent  string     `json:"user_agent" gorm:"type:varchar(512)"`
+	IPAddress  string     `json:"ip_address" gorm:"type:varchar(45)"`
+	LastSeenIP string     `json:"last_seen_ip" gorm:"type:varchar(45)"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null;index"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy  *uuid.UUID `json:"revoked_by,omitempty" gorm:"type:uuid"`
+}
+
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// IsActiveAt: belum dicabut dan refresh token-nya belum kedaluwarsa
+func (s *UserSession) IsActiveAt(at time.Time) bool {
+	return s.RevokedAt == nil && at.Before(s.ExpiresAt)
+}
+
+// UserSessionResponse menandai sesi yang sedang dipakai request ini
+type UserSessionResponse struct {
+	UserSession
+	Current bool `json:"current"`
+}
@@ -14,24 +14,54 @@ type Student struct {
 
 	NIM           string `gorm:"type:varchar(20);unique;not null" json:"nim"`
 	ProgramStudy  string `gorm:"type:varchar(100)" json:"program_study"`
+	// StudyProgramID menunjuk master data; ProgramStudy disimpan sebagai nama resminya
+	StudyProgramID *uuid.UUID    `gorm:"type:uuid;index" json:"study_program_id"`
+	StudyProgram   *StudyProgram `gorm:"foreignKey:StudyProgramID" json:"study_program,omitempty"`
 	AcademicYear  string `gorm:"type:varchar(10)" json:"academic_year"`
 
 	AdvisorID     *uuid.UUID `gorm:"type:uuid" json:"advisor_id"`
 	Advisor       *Lecturer  `gorm:"foreignKey:AdvisorID" json:"advisor,omitempty"`
 
+	// Status studi (active, on_leave, graduated, dropped_out) dan tanggal efektifnya;
+	// perpindahan status dicatat di student_status_changes
+	Status      string     `gorm:"type:varchar(20);not null;default:'active';index" json:"status"`
+	StatusSince *time.Time `gorm:"type:date" json:"status_since,omitempty"`
+
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// CurrentStatus menganggap status kosong (data sebelum ada status studi) sebagai aktif
+func (s *Student) CurrentStatus() string {
+	if s.Status == "" {
+		return StudentStatusActive
+	}
+	return s.Status
+}
+
+// PortfolioWritable: mahasiswa aktif boleh mengubah dan mengajukan prestasi. Mahasiswa non-aktif
+// masih diberi masa tenggang sejak tanggal status berlaku; setelahnya portofolio hanya bisa dibaca.
+func (s *Student) PortfolioWritable(now time.Time, grace time.Duration) bool {
+	if s.CurrentStatus() == StudentStatusActive {
+		return true
+	}
+	if s.StatusSince == nil {
+		return false
+	}
+	return now.Before(s.StatusSince.Add(grace))
+}
+
 type StudentCreateRequest struct {
-	UserID       string `json:"user_id" binding:"required"`
-	NIM          string `json:"nim" binding:"required"`
-	ProgramStudy string `json:"program_study" binding:"required"`
-	AcademicYear string `json:"academic_year" binding:"required"`
+	UserID         string `json:"user_id" binding:"required"`
+	NIM            string `json:"nim" binding:"required"`
+	ProgramStudy   string `json:"program_study" binding:"required_without=StudyProgramID"`
+	StudyProgramID string `json:"study_program_id"` // Diutamakan daripada program_study bila diisi
+	AcademicYear   string `json:"academic_year" binding:"required"`
 }
 
 type StudentUpdateRequest struct {
-	ProgramStudy string `json:"program_study"`
-	AcademicYear string `json:"academic_year"`
+	ProgramStudy   string `json:"program_study"`
+	StudyProgramID string `json:"study_program_id"`
+	AcademicYear   string `json:"academic_year"`
 }
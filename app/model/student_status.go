@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =================================================================
+// STATUS STUDI MAHASISWA (lifecycle)
+// =================================================================
+
+const (
+	StudentStatusActive     = "active"
+	StudentStatusOnLeave    = "on_leave" // cuti akademik
+	StudentStatusGraduated  = "graduated"
+	StudentStatusDroppedOut = "dropped_out"
+)
+
+// studentStatusTransitions: lulus bersifat final, mahasiswa keluar hanya bisa diaktifkan kembali
+var studentStatusTransitions = map[string][]string{
+	StudentStatusActive:     {StudentStatusOnLeave, StudentStatusGraduated, StudentStatusDroppedOut},
+	StudentStatusOnLeave:    {StudentStatusActive, StudentStatusGraduated, StudentStatusDroppedOut},
+	StudentStatusDroppedOut: {StudentStatusActive},
+	StudentStatusGraduated:  {},
+}
+
+// IsValidStudentStatus memeriksa status studi yang dikenal
+func IsValidStudentStatus(status string) bool {
+	_, ok := studentStatusTransitions[status]
+	return ok
+}
+
+// CanTransitionStudentStatus memeriksa apakah perpindahan status diizinkan
+func CanTransitionStudentStatus(from, to string) bool {
+	for _, next := range studentStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsArchivedStudentStatus: mahasiswa lulus atau keluar tidak lagi tampil sebagai bimbingan aktif
+func IsArchivedStudentStatus(status string) bool {
+	return status == StudentStatusGraduated || status == StudentStatusDroppedOut
+}
+
+// StudentStatusChange adalah riwayat perpindahan status studi mahasiswa
+type StudentStatusChange struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	StudentID     uuid.UUID  `json:"student_id" gorm:"type:uuid;not null;index"`
+	FromStatus    string     `json:"from_status" gorm:"type:varchar(20);not null"`
+	ToStatus      string     `json:"to_status" gorm:"type:varchar(20);not null"`
+	EffectiveDate time.Time  `json:"effective_date" gorm:"type:date;not null"`
+	Reason        string     `json:"reason,omitempty" gorm:"type:text"`
+	ChangedBy     *uuid.UUID `json:"changed_by,omitempty" gorm:"type:uuid"` // user ID admin
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (StudentStatusChange) TableName() string {
+	return "student_status_changes"
+}
+
+// StudentStatusRequest: DTO untuk PUT /students/:id/status.
+// EffectiveDate berformat YYYY-MM-DD, kosong = hari ini; tidak boleh di masa depan.
+type StudentStatusRequest struct {
+	Status        string `json:"status" binding:"required,oneof=active on_leave graduated dropped_out"`
+	EffectiveDate string `json:"effective_date"`
+	Reason        string `json:"reason"`
+}
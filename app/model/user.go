@@ -1,9 +1,11 @@
 package model
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type User struct {
@@ -18,17 +20,58 @@ type User struct {
 
 	IsActive bool `json:"is_active" gorm:"default:true"`
 
-	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	// Profil kontak yang diubah sendiri lewat /me (lihat SelfProfileRulesFor).
+	// ContactEmail terpisah dari Email yang dipakai sebagai identitas login.
+	ContactEmail string `json:"contact_email,omitempty" gorm:"type:varchar(100)"`
+	Phone        string `json:"phone,omitempty" gorm:"type:varchar(30)"`
+	PhotoURL     string `json:"photo_url,omitempty" gorm:"type:varchar(255)"`
+	Bio          string `json:"bio,omitempty" gorm:"type:text"`
+
+	// IsServiceAccount menandai akun mesin (integrasi) yang hanya boleh autentikasi lewat API key
+	IsServiceAccount bool `json:"is_service_account" gorm:"not null;default:false"`
+
+	// SecurityVersion dinaikkan setiap ada perubahan yang harus mencabut token lama
+	// (ganti role, deaktivasi, ganti password). Nilainya ikut ditanam di JWT (claim "sv").
+	SecurityVersion int `json:"-" gorm:"not null;default:1"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	// DeletedAt: soft delete, baris tetap ada agar referensi historis tidak putus
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 func (User) TableName() string {
 	return "users"
 }
 
+// NormalizeIdentity menyeragamkan username dan email (trim + lowercase)
+// agar keunikan dan pencarian tidak sensitif terhadap huruf besar/kecil.
+func (u *User) NormalizeIdentity() {
+	u.Username = NormalizeIdentifier(u.Username)
+	u.Email = NormalizeIdentifier(u.Email)
+}
+
+// BeforeSave memastikan semua jalur penyimpanan via GORM menyimpan identitas yang sudah dinormalisasi
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	u.NormalizeIdentity()
+	return nil
+}
+
+// NormalizeIdentifier menormalisasi username/email yang diketik user sebelum lookup
+func NormalizeIdentifier(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// UserSecurityState adalah potongan ringan data user yang dibutuhkan AuthMiddleware per request
+type UserSecurityState struct {
+	RoleID          uuid.UUID
+	IsActive        bool
+	SecurityVersion int
+}
+
 type LoginRequest struct {
-	Username string `json:"username" binding:"required"`
+	// Username atau email kampus (tidak case-sensitive)
+	Username string `json:"username" binding:"required" example:"budi@student.unair.ac.id"`
 	Password string `json:"password" binding:"required"`
 }
 
@@ -68,4 +111,9 @@ type UserUpdateRequest struct {
 // ===================================
 type UserUpdateRoleRequest struct {
 	RoleID string `json:"role_id" binding:"required"`
+}
+
+// UserPasswordResetRequest: admin mengganti password user (mis. user lupa password)
+type UserPasswordResetRequest struct {
+	Password string `json:"password" binding:"required,min=8"`
 }
\ No newline at end of file
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Provider identitas eksternal
+const (
+	IdentityProviderOIDC = "oidc"
+)
+
+// UserIdentity menautkan user lokal dengan akun di identity provider eksternal.
+// Setelah tertaut, login berikutnya dicocokkan lewat (provider, subject) bukan lewat NIM/NIP/email.
+type UserIdentity struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	User        *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Provider    string     `json:"provider" gorm:"type:varchar(20);not null;uniqueIndex:idx_user_identity_subject"`
+	Issuer      string     `json:"issuer" gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identity_subject"`
+	Subject     string     `json:"subject" gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identity_subject"`
+	Email       string     `json:"email,omitempty" gorm:"type:varchar(100)"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OIDCStateClaims disimpan di cookie (ditandatangani) antara redirect ke IdP dan callback
+type OIDCStateClaims struct {
+	TokenType    string `json:"token_type"`
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	jwt.RegisteredClaims
+}
+
+// OIDCAuthorizationResponse dikembalikan /auth/oidc/login bila klien meminta JSON
+type OIDCAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	ExpiresIn        int    `json:"expires_in"`
+}
@@ -0,0 +1,230 @@
+// File: app/policy/policy.go
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// =================================================================
+// SUBJECT, ACTION, RESOURCE
+// =================================================================
+
+// Subject adalah identitas pemanggil: user, role, dan profil mahasiswa/dosen bila ada.
+type Subject struct {
+	UserID      uuid.UUID
+	Role        string
+	Permissions []string
+	Student     *model.Student
+	Lecturer    *model.Lecturer
+	// ActingFor berisi ID dosen wali yang wewenang verifikasinya sedang didelegasikan ke subject
+	ActingFor []uuid.UUID
+}
+
+// Action adalah operasi yang dievaluasi terhadap sebuah resource.
+type Action string
+
+const (
+	ActionReadAchievement   Action = "achievement:read"
+	ActionModifyAchievement Action = "achievement:modify" // update, delete, submit, upload attachment
+	ActionVerifyAchievement Action = "achievement:verify" // verify & reject
+	ActionReadStudent       Action = "student:read"
+	ActionReadStudentReport Action = "report:read_student"
+)
+
+// Resource mendeskripsikan data yang diakses, selalu berpusat pada mahasiswa pemiliknya.
+type Resource struct {
+	OwnerStudentID uuid.UUID
+	AdvisorID      *uuid.UUID
+	// DepartmentID departemen pemilik menurut master data (lewat program studi), nil bila
+	// program studinya belum tertaut; saat itu pencocokan jatuh ke teks Department.
+	DepartmentID *uuid.UUID
+	Department   string
+}
+
+// StudentResource membangun Resource dari profil mahasiswa pemilik data.
+func StudentResource(student *model.Student) Resource {
+	res := Resource{
+		OwnerStudentID: student.ID,
+		AdvisorID:      student.AdvisorID,
+		Department:     student.ProgramStudy,
+	}
+	if student.StudyProgram != nil {
+		departmentID := student.StudyProgram.DepartmentID
+		res.DepartmentID = &departmentID
+	}
+	return res
+}
+
+// AchievementResource sama dengan StudentResource, tetapi pengajuan SUBMITTED yang di-pin
+// (dosen wali berganti dengan kebijakan "retain") tetap milik dosen wali lama sampai diputuskan.
+func AchievementResource(owner *model.Student, ref *model.AchievementReference) Resource {
+	res := StudentResource(owner)
+	if ref.Status == model.StatusSubmitted && ref.PinnedAdvisorID != nil {
+		res.AdvisorID = ref.PinnedAdvisorID
+	}
+	return res
+}
+
+// =================================================================
+// RULES
+// =================================================================
+
+// Rule mengembalikan true bila subject diizinkan atas resource.
+type Rule func(sub *Subject, res Resource) bool
+
+// Admin: role administrator selalu diizinkan.
+func Admin(sub *Subject, _ Resource) bool {
+	return strings.EqualFold(sub.Role, model.RoleAdmin)
+}
+
+// Owner: mahasiswa pemilik data.
+func Owner(sub *Subject, res Resource) bool {
+	return sub.Student != nil && sub.Student.ID == res.OwnerStudentID
+}
+
+// AdvisorOfOwner: dosen wali dari mahasiswa pemilik data.
+func AdvisorOfOwner(sub *Subject, res Resource) bool {
+	return sub.Lecturer != nil && res.AdvisorID != nil && *res.AdvisorID == sub.Lecturer.ID
+}
+
+// DelegateOfAdvisor: dosen yang sedang memegang delegasi aktif dari dosen wali pemilik data
+func DelegateOfAdvisor(sub *Subject, res Resource) bool {
+	if res.AdvisorID == nil {
+		return false
+	}
+	for _, principalID := range sub.ActingFor {
+		if principalID == *res.AdvisorID {
+			return true
+		}
+	}
+	return false
+}
+
+// ActsAsDelegate true bila akses subject atas resource hanya berasal dari delegasi
+// (bukan karena ia sendiri dosen wali pemilik data)
+func ActsAsDelegate(sub *Subject, res Resource) bool {
+	return !AdvisorOfOwner(sub, res) && DelegateOfAdvisor(sub, res)
+}
+
+// SameDepartment: dosen yang berada di department yang sama dengan pemilik data.
+func SameDepartment(sub *Subject, res Resource) bool {
+	if sub.Lecturer == nil {
+		return false
+	}
+	if sub.Lecturer.DepartmentID != nil && res.DepartmentID != nil {
+		return *sub.Lecturer.DepartmentID == *res.DepartmentID
+	}
+	if sub.Lecturer.Department == "" || res.Department == "" {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(sub.Lecturer.Department), strings.TrimSpace(res.Department))
+}
+
+// rules memetakan setiap action ke daftar rule (OR logic).
+var rules = map[Action][]Rule{
+	ActionReadAchievement:   {Admin, Owner, AdvisorOfOwner, DelegateOfAdvisor, SameDepartment},
+	ActionModifyAchievement: {Owner},
+	ActionVerifyAchievement: {Admin, AdvisorOfOwner, DelegateOfAdvisor},
+	ActionReadStudent:       {Admin, Owner, AdvisorOfOwner, DelegateOfAdvisor, SameDepartment},
+	ActionReadStudentReport: {Admin, Owner, AdvisorOfOwner, SameDepartment},
+}
+
+// Allowed mengevaluasi rule untuk action; action tanpa rule selalu ditolak.
+func Allowed(sub *Subject, action Action, res Resource) bool {
+	if sub == nil {
+		return false
+	}
+	for _, rule := range rules[action] {
+		if rule(sub, res) {
+			return true
+		}
+	}
+	return false
+}
+
+// =================================================================
+// ENFORCER
+// =================================================================
+
+// Enforcer membangun Subject dari request dan mengevaluasi policy.
+type Enforcer interface {
+	Subject(c *gin.Context) (*Subject, error)
+	// Authorize mengembalikan error bila profil subject gagal dimuat (bukan sekadar ditolak)
+	Authorize(c *gin.Context, action Action, res Resource) (*Subject, bool, error)
+}
+
+type enforcer struct {
+	studentRepo    repository.StudentRepository
+	lecturerRepo   repository.LecturerRepository
+	delegationRepo repository.DelegationRepository
+}
+
+func NewEnforcer(
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+	delegationRepo repository.DelegationRepository,
+) Enforcer {
+	return &enforcer{studentRepo: studentRepo, lecturerRepo: lecturerRepo, delegationRepo: delegationRepo}
+}
+
+// Subject membaca identitas dari context AuthMiddleware lalu memuat profil mahasiswa/dosen.
+// Hasilnya di-cache di context sehingga satu request hanya melakukan lookup sekali.
+func (e *enforcer) Subject(c *gin.Context) (*Subject, error) {
+	if cached, ok := c.Get("policySubject"); ok {
+		return cached.(*Subject), nil
+	}
+
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		return nil, errors.New("user ID not found in context")
+	}
+
+	sub := &Subject{
+		UserID:      userID,
+		Role:        c.GetString("role"),
+		Permissions: c.GetStringSlice("permissions"),
+	}
+	// Tidak punya profil mahasiswa/dosen itu wajar (mis. admin); error lain tidak boleh
+	// diperlakukan sama karena subject akan kehilangan akses yang seharusnya dimiliki
+	student, err := e.studentRepo.FindByUserID(userID)
+	switch {
+	case err == nil:
+		sub.Student = student
+	case !errors.Is(err, repository.ErrStudentNotFound):
+		return nil, fmt.Errorf("failed to load student profile: %w", err)
+	}
+
+	lecturer, err := e.lecturerRepo.FindByUserID(userID)
+	switch {
+	case err == nil:
+		sub.Lecturer = lecturer
+		principals, err := e.delegationRepo.FindActivePrincipalIDs(lecturer.ID, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load verification delegations: %w", err)
+		}
+		sub.ActingFor = principals
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, fmt.Errorf("failed to load lecturer profile: %w", err)
+	}
+
+	c.Set("policySubject", sub)
+	return sub, nil
+}
+
+func (e *enforcer) Authorize(c *gin.Context, action Action, res Resource) (*Subject, bool, error) {
+	sub, err := e.Subject(c)
+	if err != nil {
+		return nil, false, err
+	}
+	return sub, Allowed(sub, action, res), nil
+}
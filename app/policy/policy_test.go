@@ -0,0 +1,231 @@
+package policy
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestAllowed(t *testing.T) {
+	deptA, deptB := uuid.New(), uuid.New()
+	owner := &model.Student{ID: uuid.New()}
+	advisor := &model.Lecturer{ID: uuid.New(), DepartmentID: &deptB}
+	colleague := &model.Lecturer{ID: uuid.New(), DepartmentID: &deptA}
+	outsider := &model.Lecturer{ID: uuid.New(), DepartmentID: &deptB}
+	textOnly := &model.Lecturer{ID: uuid.New(), Department: " teknik informatika "}
+
+	res := Resource{OwnerStudentID: owner.ID, AdvisorID: &advisor.ID, DepartmentID: &deptA, Department: "Teknik Informatika"}
+	unlinked := Resource{OwnerStudentID: owner.ID, AdvisorID: &advisor.ID, Department: "Teknik Informatika"}
+
+	subjects := map[string]*Subject{
+		"admin":     {Role: model.RoleAdmin},
+		"owner":     {Role: model.RoleMahasiswa, Student: owner},
+		"student":   {Role: model.RoleMahasiswa, Student: &model.Student{ID: uuid.New()}},
+		"advisor":   {Role: model.RoleDosenWali, Lecturer: advisor},
+		"delegate":  {Role: model.RoleDosenWali, Lecturer: outsider, ActingFor: []uuid.UUID{advisor.ID}},
+		"colleague": {Role: model.RoleDosenWali, Lecturer: colleague},
+		"outsider":  {Role: model.RoleDosenWali, Lecturer: outsider},
+		"text only": {Role: model.RoleDosenWali, Lecturer: textOnly},
+	}
+
+	tests := []struct {
+		subject string
+		action  Action
+		res     Resource
+		want    bool
+	}{
+		{"admin", ActionReadAchievement, res, true},
+		{"admin", ActionModifyAchievement, res, false},
+		{"admin", ActionVerifyAchievement, res, true},
+		{"owner", ActionReadAchievement, res, true},
+		{"owner", ActionModifyAchievement, res, true},
+		{"owner", ActionVerifyAchievement, res, false},
+		{"student", ActionReadAchievement, res, false},
+		{"student", ActionReadStudent, res, false},
+		{"advisor", ActionReadAchievement, res, true},
+		{"advisor", ActionVerifyAchievement, res, true},
+		{"advisor", ActionModifyAchievement, res, false},
+		{"advisor", ActionReadStudentReport, res, true},
+		{"delegate", ActionVerifyAchievement, res, true},
+		{"delegate", ActionReadStudent, res, true},
+		// Delegasi hanya untuk verifikasi, tidak memberi akses laporan
+		{"delegate", ActionReadStudentReport, res, false},
+		{"colleague", ActionReadAchievement, res, true},
+		{"colleague", ActionVerifyAchievement, res, false},
+		{"outsider", ActionReadAchievement, res, false},
+		// Program studi belum tertaut master data: jatuh ke teks department
+		{"text only", ActionReadStudent, unlinked, true},
+		{"text only", ActionReadStudent, res, true},
+		{"outsider", ActionReadStudent, unlinked, false},
+		{"admin", Action("unknown:action"), res, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject+" "+string(tt.action), func(t *testing.T) {
+			if got := Allowed(subjects[tt.subject], tt.action, tt.res); got != tt.want {
+				t.Fatalf("Allowed(%s, %s) = %v, want %v", tt.subject, tt.action, got, tt.want)
+			}
+		})
+	}
+
+	if Allowed(nil, ActionReadAchievement, res) {
+		t.Error("nil subject must never be allowed")
+	}
+}
+
+func TestAchievementResourcePinnedAdvisor(t *testing.T) {
+	current, previous := uuid.New(), uuid.New()
+	owner := &model.Student{ID: uuid.New(), AdvisorID: &current}
+
+	tests := []struct {
+		status model.AchievementStatus
+		want   uuid.UUID
+	}{
+		{status: model.StatusSubmitted, want: previous},
+		{status: model.StatusVerified, want: current},
+	}
+	for _, tt := range tests {
+		res := AchievementResource(owner, &model.AchievementReference{Status: tt.status, PinnedAdvisorID: &previous})
+		if res.AdvisorID == nil || *res.AdvisorID != tt.want {
+			t.Errorf("status %s: advisor = %v, want %v", tt.status, res.AdvisorID, tt.want)
+		}
+	}
+}
+
+type policyFakeStudentRepo struct {
+	repository.StudentRepository
+	student *model.Student
+	err     error
+	calls   int
+}
+
+func (r *policyFakeStudentRepo) FindByUserID(uuid.UUID) (*model.Student, error) {
+	r.calls++
+	return r.student, r.err
+}
+
+type policyFakeLecturerRepo struct {
+	repository.LecturerRepository
+	lecturer *model.Lecturer
+	err      error
+}
+
+func (r *policyFakeLecturerRepo) FindByUserID(uuid.UUID) (*model.Lecturer, error) {
+	return r.lecturer, r.err
+}
+
+type policyFakeDelegationRepo struct {
+	repository.DelegationRepository
+	principals []uuid.UUID
+	err        error
+}
+
+func (r *policyFakeDelegationRepo) FindActivePrincipalIDs(uuid.UUID, time.Time) ([]uuid.UUID, error) {
+	return r.principals, r.err
+}
+
+func TestEnforcerSubject(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	errDB := errors.New("connection reset")
+	principal := uuid.New()
+
+	tests := []struct {
+		name         string
+		students     *policyFakeStudentRepo
+		lecturers    *policyFakeLecturerRepo
+		delegations  *policyFakeDelegationRepo
+		wantErr      bool
+		wantStudent  bool
+		wantLecturer bool
+		wantActing   int
+	}{
+		{
+			name:      "no profile",
+			students:  &policyFakeStudentRepo{err: repository.ErrStudentNotFound},
+			lecturers: &policyFakeLecturerRepo{err: gorm.ErrRecordNotFound},
+		},
+		{
+			name:        "student",
+			students:    &policyFakeStudentRepo{student: &model.Student{ID: uuid.New()}},
+			lecturers:   &policyFakeLecturerRepo{err: gorm.ErrRecordNotFound},
+			wantStudent: true,
+		},
+		{
+			name:         "lecturer with delegation",
+			students:     &policyFakeStudentRepo{err: repository.ErrStudentNotFound},
+			lecturers:    &policyFakeLecturerRepo{lecturer: &model.Lecturer{ID: uuid.New()}},
+			delegations:  &policyFakeDelegationRepo{principals: []uuid.UUID{principal}},
+			wantLecturer: true,
+			wantActing:   1,
+		},
+		{
+			name:      "student lookup fails",
+			students:  &policyFakeStudentRepo{err: errDB},
+			lecturers: &policyFakeLecturerRepo{err: gorm.ErrRecordNotFound},
+			wantErr:   true,
+		},
+		{
+			name:      "lecturer lookup fails",
+			students:  &policyFakeStudentRepo{err: repository.ErrStudentNotFound},
+			lecturers: &policyFakeLecturerRepo{err: errDB},
+			wantErr:   true,
+		},
+		{
+			name:        "delegation lookup fails",
+			students:    &policyFakeStudentRepo{err: repository.ErrStudentNotFound},
+			lecturers:   &policyFakeLecturerRepo{lecturer: &model.Lecturer{ID: uuid.New()}},
+			delegations: &policyFakeDelegationRepo{err: errDB},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delegations := tt.delegations
+			if delegations == nil {
+				delegations = &policyFakeDelegationRepo{}
+			}
+			e := NewEnforcer(tt.students, tt.lecturers, delegations)
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Set("userID", uuid.NewString())
+			c.Set("role", model.RoleDosenWali)
+
+			sub, err := e.Subject(c)
+			if tt.wantErr {
+				if !errors.Is(err, errDB) {
+					t.Fatalf("Subject() error = %v, want %v", err, errDB)
+				}
+				if _, allowed, err := e.Authorize(c, ActionReadStudent, Resource{}); allowed || err == nil {
+					t.Fatalf("Authorize() = (%v, %v), want lookup error", allowed, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Subject() error = %v", err)
+			}
+			if (sub.Student != nil) != tt.wantStudent || (sub.Lecturer != nil) != tt.wantLecturer || len(sub.ActingFor) != tt.wantActing {
+				t.Fatalf("Subject() = student %v, lecturer %v, acting for %v", sub.Student, sub.Lecturer, sub.ActingFor)
+			}
+
+			// Subject di-cache per request
+			if _, err := e.Subject(c); err != nil || tt.students.calls != 1 {
+				t.Fatalf("second Subject() = %v with %d student lookups, want cached", err, tt.students.calls)
+			}
+		})
+	}
+}
+
+func TestEnforcerSubjectRequiresUserID(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	e := NewEnforcer(&policyFakeStudentRepo{}, &policyFakeLecturerRepo{}, &policyFakeDelegationRepo{})
+
+	if _, err := e.Subject(c); err == nil {
+		t.Fatal("expected error for missing user ID")
+	}
+}
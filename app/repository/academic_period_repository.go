@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AcademicPeriodRepository mengelola periode akademik dan atribusi prestasi ke periode.
+// Setiap perubahan rentang tanggal langsung diikuti atribusi ulang dalam transaksi yang sama.
+type AcademicPeriodRepository interface {
+	FindAll() ([]model.AcademicPeriod, error)
+	FindByID(id uuid.UUID) (*model.AcademicPeriod, error)
+	// FindActive mengembalikan (nil, nil) bila belum ada periode aktif
+	FindActive() (*model.AcademicPeriod, error)
+	// FindByDate mengembalikan periode yang memuat tanggal tersebut, atau (nil, nil) bila tidak ada
+	FindByDate(date time.Time) (*model.AcademicPeriod, error)
+	// FindOverlapping mencari periode lain yang rentangnya beririsan dengan [start, end]
+	FindOverlapping(start, end time.Time, excludeID uuid.UUID) ([]model.AcademicPeriod, error)
+	IsSemesterTaken(academicYear, semester string, excludeID uuid.UUID) (bool, error)
+
+	// Create/Update menonaktifkan periode lain bila period.IsActive, lalu mengatribusi ulang prestasi
+	Create(period *model.AcademicPeriod, basis string) error
+	Update(period *model.AcademicPeriod, basis string) error
+	// Delete melepas prestasi dari periode lalu mengatribusi ulang sisanya
+	Delete(id uuid.UUID, basis string) error
+	// Activate menjadikan satu periode aktif dan menonaktifkan yang lain
+	Activate(id uuid.UUID) error
+
+	// Reattribute menghitung ulang academic_period_id semua prestasi; mengembalikan jumlah yang berubah
+	Reattribute(basis string) (int64, error)
+}
+
+type academicPeriodRepositoryGORM struct {
+	db *gorm.DB
+}
+
+func NewAcademicPeriodRepository(db *gorm.DB) AcademicPeriodRepository {
+	return &academicPeriodRepositoryGORM{db: db}
+}
+
+func (r *academicPeriodRepositoryGORM) FindAll() ([]model.AcademicPeriod, error) {
+	var periods []model.AcademicPeriod
+	err := r.db.Order("start_date DESC").Find(&periods).Error
+	return periods, err
+}
+
+func (r *academicPeriodRepositoryGORM) FindByID(id uuid.UUID) (*model.AcademicPeriod, error) {
+	var period model.AcademicPeriod
+	if err := r.db.Where("id = ?", id).First(&period).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("academic period not found")
+		}
+		return nil, err
+	}
+	return &period, nil
+}
+
+func (r *academicPeriodRepositoryGORM) FindActive() (*model.AcademicPeriod, error) {
+	return r.findOne(r.db.Where("is_active = ?", true))
+}
+
+func (r *academicPeriodRepositoryGORM) FindByDate(date time.Time) (*model.AcademicPeriod, error) {
+	day := date.Format(model.PeriodDateLayout)
+	return r.findOne(r.db.Where("start_date <= ? AND end_date >= ?", day, day).Order("start_date"))
+}
+
+// findOne mengembalikan (nil, nil) bila tidak ada baris yang cocok
+func (r *academicPeriodRepositoryGORM) findOne(query *gorm.DB) (*model.AcademicPeriod, error) {
+	var periods []model.AcademicPeriod
+	if err := query.Limit(1).Find(&periods).Error; err != nil {
+		return nil, err
+	}
+	if len(periods) == 0 {
+		return nil, nil
+	}
+	return &periods[0], nil
+}
+
+func (r *academicPeriodRepositoryGORM) FindOverlapping(start, end time.Time, excludeID uuid.UUID) ([]model.AcademicPeriod, error) {
+	var periods []model.AcademicPeriod
+	err := r.db.
+		Where("start_date <= ? AND end_date >= ?", end.Format(model.PeriodDateLayout), start.Format(model.PeriodDateLayout)).
+		Where("id <> ?", excludeID).
+		Order("start_date").
+		Find(&periods).Error
+	return periods, err
+}
+
+func (r *academicPeriodRepositoryGORM) IsSemesterTaken(academicYear, semester string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.AcademicPeriod{}).
+		Where("academic_year = ? AND semester = ? AND id <> ?", academicYear, semester, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *academicPeriodRepositoryGORM) Create(period *model.AcademicPeriod, basis string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(period).Error; err != nil {
+			return err
+		}
+		return savePeriodTx(tx, period, basis)
+	})
+}
+
+func (r *academicPeriodRepositoryGORM) Update(period *model.AcademicPeriod, basis string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(period).Error; err != nil {
+			return err
+		}
+		return savePeriodTx(tx, period, basis)
+	})
+}
+
+// savePeriodTx menjaga satu periode aktif dan mengatribusi ulang prestasi setelah create/update
+func savePeriodTx(tx *gorm.DB, period *model.AcademicPeriod, basis string) error {
+	if period.IsActive {
+		if err := deactivateOtherPeriods(tx, period.ID); err != nil {
+			return err
+		}
+	}
+	_, err := reattributeTx(tx, basis)
+	return err
+}
+
+func (r *academicPeriodRepositoryGORM) Delete(id uuid.UUID, basis string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.AchievementReference{}).Unscoped().
+			Where("academic_period_id = ?", id).
+			UpdateColumn("academic_period_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", id).Delete(&model.AcademicPeriod{}).Error; err != nil {
+			return err
+		}
+		_, err := reattributeTx(tx, basis)
+		return err
+	})
+}
+
+func (r *academicPeriodRepositoryGORM) Activate(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := deactivateOtherPeriods(tx, id); err != nil {
+			return err
+		}
+		return tx.Model(&model.AcademicPeriod{}).Where("id = ?", id).Update("is_active", true).Error
+	})
+}
+
+func deactivateOtherPeriods(tx *gorm.DB, keepID uuid.UUID) error {
+	return tx.Model(&model.AcademicPeriod{}).
+		Where("is_active = ? AND id <> ?", true, keepID).
+		Update("is_active", false).Error
+}
+
+func (r *academicPeriodRepositoryGORM) Reattribute(basis string) (int64, error) {
+	return reattributeTx(r.db, basis)
+}
+
+// periodDateColumn adalah padanan SQL dari AchievementReference.PeriodAttributionDate
+func periodDateColumn(basis string) string {
+	if basis == model.PeriodBySubmissionDate {
+		return "COALESCE(ar.submitted_at, ar.event_date)"
+	}
+	return "COALESCE(ar.event_date, ar.submitted_at)"
+}
+
+// reattributeTx menghitung ulang periode semua prestasi (termasuk yang sudah di-soft delete agar
+// FK tetap konsisten) dan hanya menulis baris yang periodenya berubah
+func reattributeTx(tx *gorm.DB, basis string) (int64, error) {
+	period := `(SELECT p.id FROM academic_periods p
+		WHERE CAST(` + periodDateColumn(basis) + ` AS date) BETWEEN p.start_date AND p.end_date
+		ORDER BY p.start_date LIMIT 1)`
+	result := tx.Exec(`UPDATE achievement_references ar SET academic_period_id = ` + period +
+		` WHERE ar.academic_period_id IS DISTINCT FROM ` + period)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrAcademicUnitInUse: unit masih punya turunan atau masih dipakai mahasiswa/dosen
+var ErrAcademicUnitInUse = errors.New("academic unit is still referenced")
+
+// AcademicUnitRepository mengelola master data fakultas, departemen, dan program studi
+type AcademicUnitRepository interface {
+	FindAllFaculties() ([]model.Faculty, error)
+	FindFacultyByID(id uuid.UUID) (*model.Faculty, error)
+	CreateFaculty(faculty *model.Faculty) error
+	UpdateFaculty(faculty *model.Faculty) error
+	DeleteFaculty(id uuid.UUID) error
+
+	// FindDepartments: facultyID nil = semua departemen
+	FindDepartments(facultyID *uuid.UUID) ([]model.Department, error)
+	FindDepartmentByID(id uuid.UUID) (*model.Department, error)
+	CreateDepartment(department *model.Department) error
+	// UpdateDepartment juga menyeragamkan lecturers.department ke nama baru
+	UpdateDepartment(department *model.Department) error
+	DeleteDepartment(id uuid.UUID) error
+
+	// FindStudyPrograms: departmentID nil = semua program studi
+	FindStudyPrograms(departmentID *uuid.UUID) ([]model.StudyProgram, error)
+	FindStudyProgramByID(id uuid.UUID) (*model.StudyProgram, error)
+	CreateStudyProgram(program *model.StudyProgram) error
+	// UpdateStudyProgram juga menyeragamkan students.program_study ke nama baru
+	UpdateStudyProgram(program *model.StudyProgram) error
+	DeleteStudyProgram(id uuid.UUID) error
+
+	// IsCodeTaken memeriksa kode unik per jenis unit (faculty, department, study_program)
+	IsCodeTaken(unitType, code string, excludeID uuid.UUID) (bool, error)
+
+	FindAliases(unitType string) ([]model.AcademicUnitAlias, error)
+	CreateAlias(alias *model.AcademicUnitAlias) error
+	DeleteAlias(id uuid.UUID) error
+
+	// LoadMatcher memuat seluruh master data + alias untuk pencocokan teks bebas di memori
+	LoadMatcher() (*model.AcademicUnitMatcher, error)
+	// MapFreeText memetakan students.program_study dan lecturers.department yang belum punya FK.
+	// apply false = hanya laporan (pratinjau).
+	MapFreeText(apply bool) (*model.AcademicUnitMappingReport, error)
+}
+
+// unitTables memetakan jenis unit ke tabelnya (untuk query kode unik)
+var unitTables = map[string]string{
+	"faculty":                  "faculties",
+	model.UnitTypeDepartment:   "departments",
+	model.UnitTypeStudyProgram: "study_programs",
+}
+
+type academicUnitRepositoryGORM struct {
+	db *gorm.DB
+}
+
+func NewAcademicUnitRepository(db *gorm.DB) AcademicUnitRepository {
+	return &academicUnitRepositoryGORM{db: db}
+}
+
+// =================================================================
+// FACULTY
+// =================================================================
+
+func (r *academicUnitRepositoryGORM) FindAllFaculties() ([]model.Faculty, error) {
+	var faculties []model.Faculty
+	err := r.db.Order("name").Find(&faculties).Error
+	return faculties, err
+}
+
+func (r *academicUnitRepositoryGORM) FindFacultyByID(id uuid.UUID) (*model.Faculty, error) {
+	var faculty model.Faculty
+	if err := r.db.Where("id = ?", id).First(&faculty).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("faculty not found")
+		}
+		return nil, err
+	}
+	return &faculty, nil
+}
+
+func (r *academicUnitRepositoryGORM) CreateFaculty(faculty *model.Faculty) error {
+	return r.db.Create(faculty).Error
+}
+
+func (r *academicUnitRepositoryGORM) UpdateFaculty(faculty *model.Faculty) error {
+	return r.db.Save(faculty).Error
+}
+
+func (r *academicUnitRepositoryGORM) DeleteFaculty(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureUnused(tx, &model.Department{}, "faculty_id = ?", id); err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.Faculty{}).Error
+	})
+}
+
+// =================================================================
+// DEPARTMENT
+// =================================================================
+
+func (r *academicUnitRepositoryGORM) FindDepartments(facultyID *uuid.UUID) ([]model.Department, error) {
+	query := r.db.Preload("Faculty")
+	if facultyID != nil {
+		query = query.Where("faculty_id = ?", *facultyID)
+	}
+	var departments []model.Department
+	err := query.Order("name").Find(&departments).Error
+	return departments, err
+}
+
+func (r *academicUnitRepositoryGORM) FindDepartmentByID(id uuid.UUID) (*model.Department, error) {
+	var department model.Department
+	if err := r.db.Preload("Faculty").Where("id = ?", id).First(&department).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("department not found")
+		}
+		return nil, err
+	}
+	return &department, nil
+}
+
+func (r *academicUnitRepositoryGORM) CreateDepartment(department *model.Department) error {
+	return r.db.Omit("Faculty").Create(department).Error
+}
+
+func (r *academicUnitRepositoryGORM) UpdateDepartment(department *model.Department) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Faculty").Save(department).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Lecturer{}).
+			Where("department_id = ?", department.ID).
+			Update("department", department.Name).Error
+	})
+}
+
+func (r *academicUnitRepositoryGORM) DeleteDepartment(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureUnused(tx, &model.StudyProgram{}, "department_id = ?", id); err != nil {
+			return err
+		}
+		if err := ensureUnused(tx, &model.Lecturer{}, "department_id = ?", id); err != nil {
+			return err
+		}
+		if err := tx.Where("unit_type = ? AND unit_id = ?", model.UnitTypeDepartment, id).Delete(&model.AcademicUnitAlias{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.Department{}).Error
+	})
+}
+
+// =================================================================
+// STUDY PROGRAM
+// =================================================================
+
+func (r *academicUnitRepositoryGORM) FindStudyPrograms(departmentID *uuid.UUID) ([]model.StudyProgram, error) {
+	query := r.db.Preload("Department.Faculty")
+	if departmentID != nil {
+		query = query.Where("department_id = ?", *departmentID)
+	}
+	var programs []model.StudyProgram
+	err := query.Order("name").Find(&programs).Error
+	return programs, err
+}
+
+func (r *academicUnitRepositoryGORM) FindStudyProgramByID(id uuid.UUID) (*model.StudyProgram, error) {
+	var program model.StudyProgram
+	if err := r.db.Preload("Department.Faculty").Where("id = ?", id).First(&program).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("study program not found")
+		}
+		return nil, err
+	}
+	return &program, nil
+}
+
+func (r *academicUnitRepositoryGORM) CreateStudyProgram(program *model.StudyProgram) error {
+	return r.db.Omit("Department").Create(program).Error
+}
+
+func (r *academicUnitRepositoryGORM) UpdateStudyProgram(program *model.StudyProgram) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Department").Save(program).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Student{}).
+			Where("study_program_id = ?", program.ID).
+			Update("program_study", program.Name).Error
+	})
+}
+
+func (r *academicUnitRepositoryGORM) DeleteStudyProgram(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Mahasiswa yang sudah di-soft-delete tetap memegang FK, jadi ikut dihitung
+		if err := ensureUnused(tx.Unscoped(), &model.Student{}, "study_program_id = ?", id); err != nil {
+			return err
+		}
+		if err := tx.Where("unit_type = ? AND unit_id = ?", model.UnitTypeStudyProgram, id).Delete(&model.AcademicUnitAlias{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.StudyProgram{}).Error
+	})
+}
+
+func (r *academicUnitRepositoryGORM) IsCodeTaken(unitType, code string, excludeID uuid.UUID) (bool, error) {
+	table, ok := unitTables[unitType]
+	if !ok {
+		return false, errors.New("unknown academic unit type")
+	}
+	var count int64
+	err := r.db.Table(table).
+		Where("UPPER(code) = ? AND id <> ?", strings.ToUpper(strings.TrimSpace(code)), excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ensureUnused mengembalikan ErrAcademicUnitInUse bila masih ada baris yang mereferensikan unit
+func ensureUnused(tx *gorm.DB, value interface{}, where string, id uuid.UUID) error {
+	var count int64
+	if err := tx.Model(value).Where(where, id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAcademicUnitInUse
+	}
+	return nil
+}
+
+// =================================================================
+// ALIAS & PEMETAAN TEKS BEBAS
+// =================================================================
+
+func (r *academicUnitRepositoryGORM) FindAliases(unitType string) ([]model.AcademicUnitAlias, error) {
+	query := r.db.Order("unit_type, alias")
+	if unitType != "" {
+		query = query.Where("unit_type = ?", unitType)
+	}
+	var aliases []model.AcademicUnitAlias
+	err := query.Find(&aliases).Error
+	return aliases, err
+}
+
+func (r *academicUnitRepositoryGORM) CreateAlias(alias *model.AcademicUnitAlias) error {
+	alias.Normalized = model.NormalizeUnitName(alias.Alias)
+	return r.db.Create(alias).Error
+}
+
+func (r *academicUnitRepositoryGORM) DeleteAlias(id uuid.UUID) error {
+	result := r.db.Where("id = ?", id).Delete(&model.AcademicUnitAlias{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("alias not found")
+	}
+	return nil
+}
+
+func (r *academicUnitRepositoryGORM) LoadMatcher() (*model.AcademicUnitMatcher, error) {
+	var programs []model.StudyProgram
+	if err := r.db.Find(&programs).Error; err != nil {
+		return nil, err
+	}
+	var departments []model.Department
+	if err := r.db.Find(&departments).Error; err != nil {
+		return nil, err
+	}
+	var aliases []model.AcademicUnitAlias
+	if err := r.db.Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+	return model.NewAcademicUnitMatcher(programs, departments, aliases), nil
+}
+
+// freeTextValue adalah satu nilai teks bebas beserta jumlah barisnya
+type freeTextValue struct {
+	Value    string
+	RowCount int64
+}
+
+func (r *academicUnitRepositoryGORM) MapFreeText(apply bool) (*model.AcademicUnitMappingReport, error) {
+	matcher, err := r.LoadMatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.AcademicUnitMappingReport{Applied: apply}
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		// 1. students.program_study -> study_programs
+		var programValues []freeTextValue
+		if err := tx.Model(&model.Student{}).
+			Select("program_study AS value, COUNT(*) AS row_count").
+			Where("study_program_id IS NULL AND COALESCE(program_study, '') <> ''").
+			Group("program_study").Order("program_study").
+			Scan(&programValues).Error; err != nil {
+			return err
+		}
+		report.StudyPrograms.Unmapped = []model.UnmappedValue{}
+		for _, v := range programValues {
+			program, ambiguous := matcher.StudyProgram(v.Value)
+			if program == nil {
+				report.StudyPrograms.Unmapped = append(report.StudyPrograms.Unmapped, model.UnmappedValue{Value: v.Value, Rows: v.RowCount, Ambiguous: ambiguous})
+				continue
+			}
+			report.StudyPrograms.MappedRows += v.RowCount
+			if !apply {
+				continue
+			}
+			if err := tx.Model(&model.Student{}).
+				Where("study_program_id IS NULL AND program_study = ?", v.Value).
+				Updates(map[string]interface{}{"study_program_id": program.ID, "program_study": program.Name}).Error; err != nil {
+				return err
+			}
+		}
+
+		// 2. lecturers.department -> departments
+		var departmentValues []freeTextValue
+		if err := tx.Model(&model.Lecturer{}).
+			Select("department AS value, COUNT(*) AS row_count").
+			Where("department_id IS NULL AND COALESCE(department, '') <> ''").
+			Group("department").Order("department").
+			Scan(&departmentValues).Error; err != nil {
+			return err
+		}
+		report.Departments.Unmapped = []model.UnmappedValue{}
+		for _, v := range departmentValues {
+			department, ambiguous := matcher.Department(v.Value)
+			if department == nil {
+				report.Departments.Unmapped = append(report.Departments.Unmapped, model.UnmappedValue{Value: v.Value, Rows: v.RowCount, Ambiguous: ambiguous})
+				continue
+			}
+			report.Departments.MappedRows += v.RowCount
+			if !apply {
+				continue
+			}
+			if err := tx.Model(&model.Lecturer{}).
+				Where("department_id IS NULL AND department = ?", v.Value).
+				Updates(map[string]interface{}{"department_id": department.ID, "department": department.Name}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
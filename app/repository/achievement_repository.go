@@ -23,7 +23,10 @@ type AchievementRepository interface {
 	CreateReference(achievementRef *model.AchievementReference) error
 	UpdateReference(achievementRef *model.AchievementReference) error
 	FindReferenceByID(id uuid.UUID) (*model.AchievementReference, error)
-	FindReferencesByStudentID(studentID uuid.UUID) ([]model.AchievementReference, error)
+	// FindReferencesByStudentID: periodID nil = semua periode akademik
+	FindReferencesByStudentID(studentID uuid.UUID, periodID *uuid.UUID) ([]model.AchievementReference, error)
+	// FindReferences: semua referensi beserta pemilik dan periodenya; status kosong/periodID nil = tanpa filter
+	FindReferences(status model.AchievementStatus, periodID *uuid.UUID) ([]model.AchievementReference, error)
 	DeleteReference(id uuid.UUID) error // Soft delete di PGSQL
 
 	// MongoDB (Content/Detail)
@@ -78,11 +81,15 @@ func (r *achievementRepository) FindReferenceByID(id uuid.UUID) (*model.Achievem
 }
 
 // FindReferencesByStudentID: Mencari daftar referensi prestasi berdasarkan StudentID.
-func (r *achievementRepository) FindReferencesByStudentID(studentID uuid.UUID) ([]model.AchievementReference, error) {
+func (r *achievementRepository) FindReferencesByStudentID(studentID uuid.UUID, periodID *uuid.UUID) ([]model.AchievementReference, error) {
 	var references []model.AchievementReference
 	
 	// Query Gorm: Temukan semua AchievementReference di mana StudentID cocok.
-	result := r.db.Where("student_id = ?", studentID).Find(&references)
+	query := r.db.Where("student_id = ?", studentID)
+	if periodID != nil {
+		query = query.Where("academic_period_id = ?", *periodID)
+	}
+	result := query.Find(&references)
 	
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -93,6 +100,27 @@ func (r *achievementRepository) FindReferencesByStudentID(studentID uuid.UUID) (
 	return references, nil
 }
 
+// FindReferences: Mencari referensi prestasi lintas mahasiswa untuk daftar dan statistik.
+// Mahasiswa/user yang sudah di-soft-delete tetap dimuat agar prestasinya tetap terhitung.
+func (r *achievementRepository) FindReferences(status model.AchievementStatus, periodID *uuid.UUID) ([]model.AchievementReference, error) {
+	var references []model.AchievementReference
+
+	query := r.db.Preload("Student", includeDeleted).
+		Preload("Student.User", includeDeleted).
+		Preload("Student.StudyProgram").
+		Preload("AcademicPeriod")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if periodID != nil {
+		query = query.Where("academic_period_id = ?", *periodID)
+	}
+	if err := query.Order("created_at DESC").Find(&references).Error; err != nil {
+		return nil, err
+	}
+	return references, nil
+}
+
 // DeleteReference: Melakukan soft delete pada referensi prestasi di PostgreSQL.
 func (r *achievementRepository) DeleteReference(id uuid.UUID) error {
 	// Gorm akan secara otomatis melakukan soft delete jika model.AchievementReference memiliki field gorm.DeletedAt
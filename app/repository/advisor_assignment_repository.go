@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAdvisorBackdated: effective_from lebih awal dari riwayat dosen wali yang masih berlaku
+var ErrAdvisorBackdated = errors.New("effective_from is earlier than the current advisor assignment")
+
+// ErrStudentAlreadyAssigned: OnlyUnassigned diminta, tetapi mahasiswa sementara itu sudah mendapat dosen wali
+var ErrStudentAlreadyAssigned = errors.New("student already has an advisor")
+
+// ErrAdvisorCapacityExceeded: pergantian membuat bimbingan aktif dosen melebihi MaxAdvisees
+var ErrAdvisorCapacityExceeded = errors.New("advisor would exceed max_advisees")
+
+// AdvisorAssignmentRepository menyimpan riwayat dosen wali mahasiswa
+type AdvisorAssignmentRepository interface {
+	// Reassign menerapkan semua pergantian dalam SATU transaksi: menutup riwayat lama, membuat
+	// riwayat baru, memperbarui students.advisor_id, dan menerapkan kebijakan in-flight.
+	// Mengembalikan total prestasi SUBMITTED yang terdampak.
+	Reassign(changes []model.AdvisorChange, opts model.AdvisorChangeOptions) (int, error)
+	// CountInFlight menghitung prestasi SUBMITTED milik mahasiswa-mahasiswa ini (untuk dry-run)
+	CountInFlight(studentIDs []uuid.UUID) (int64, error)
+	FindByStudentID(studentID uuid.UUID) ([]model.AdvisorAssignment, error)
+}
+
+type advisorAssignmentRepositoryGORM struct {
+	db *gorm.DB
+}
+
+func NewAdvisorAssignmentRepository(db *gorm.DB) AdvisorAssignmentRepository {
+	return &advisorAssignmentRepositoryGORM{db: db}
+}
+
+func (r *advisorAssignmentRepositoryGORM) Reassign(changes []model.AdvisorChange, opts model.AdvisorChangeOptions) (int, error) {
+	total := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		total = 0
+		// Baris dosen tujuan dikunci lebih dulu agar transaksi paralel ke dosen yang sama berjalan
+		// bergantian dan hitungan batas bimbingan di bawah selalu melihat data terbaru
+		advisorIDs := changeAdvisorIDs(changes)
+		if opts.MaxAdvisees > 0 && len(advisorIDs) > 0 {
+			var locked []model.Lecturer
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Select("id").Where("id IN ?", advisorIDs).Order("id").
+				Find(&locked).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, change := range changes {
+			affected, err := reassignAdvisorTx(tx, change, opts)
+			if err != nil {
+				return fmt.Errorf("reassign student %s: %w", change.StudentID, err)
+			}
+			total += affected
+		}
+
+		if opts.MaxAdvisees > 0 {
+			for _, advisorID := range advisorIDs {
+				var count int64
+				if err := tx.Model(&model.Student{}).
+					Where("advisor_id = ? AND status NOT IN ?", advisorID, archivedStudentStatuses).
+					Count(&count).Error; err != nil {
+					return err
+				}
+				if count > int64(opts.MaxAdvisees) {
+					return fmt.Errorf("lecturer %s: %w", advisorID, ErrAdvisorCapacityExceeded)
+				}
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+// changeAdvisorIDs mengembalikan dosen tujuan yang unik, terurut agar urutan penguncian konsisten
+func changeAdvisorIDs(changes []model.AdvisorChange) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(changes))
+	ids := make([]uuid.UUID, 0, len(changes))
+	for _, change := range changes {
+		if change.AdvisorID != nil && !seen[*change.AdvisorID] {
+			seen[*change.AdvisorID] = true
+			ids = append(ids, *change.AdvisorID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ids
+}
+
+func (r *advisorAssignmentRepositoryGORM) CountInFlight(studentIDs []uuid.UUID) (int64, error) {
+	var count int64
+	if len(studentIDs) == 0 {
+		return 0, nil
+	}
+	err := r.db.Model(&model.AchievementReference{}).
+		Where("student_id IN ? AND status = ?", studentIDs, model.StatusSubmitted).
+		Count(&count).Error
+	return count, err
+}
+
+// includeDeleted dipakai pada preload data historis: dosen/user yang sudah di-soft-delete tetap ditampilkan
+func includeDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+func (r *advisorAssignmentRepositoryGORM) FindByStudentID(studentID uuid.UUID) ([]model.AdvisorAssignment, error) {
+	var history []model.AdvisorAssignment
+	err := r.db.Preload("Advisor", includeDeleted).
+		Preload("Advisor.User", includeDeleted).
+		Where("student_id = ?", studentID).
+		Order("effective_from DESC, created_at DESC").
+		Find(&history).Error
+	return history, err
+}
+
+// reassignAdvisorTx menulis satu pergantian dosen wali di dalam transaksi yang sudah berjalan.
+// Dipakai juga oleh ProvisioningRepository agar import/provisioning ikut tercatat di riwayat.
+func reassignAdvisorTx(tx *gorm.DB, change model.AdvisorChange, opts model.AdvisorChangeOptions) (int, error) {
+	// 1. Tutup riwayat yang masih berlaku (rentang riwayat tidak boleh tumpang tindih)
+	var later int64
+	if err := tx.Model(&model.AdvisorAssignment{}).
+		Where("student_id = ? AND effective_to IS NULL AND effective_from > ?", change.StudentID, opts.EffectiveFrom).
+		Count(&later).Error; err != nil {
+		return 0, err
+	}
+	if later > 0 {
+		return 0, ErrAdvisorBackdated
+	}
+	if err := tx.Model(&model.AdvisorAssignment{}).
+		Where("student_id = ? AND effective_to IS NULL", change.StudentID).
+		Update("effective_to", opts.EffectiveFrom).Error; err != nil {
+		return 0, err
+	}
+
+	// 2. Dosen wali baru; dengan OnlyUnassigned, kondisi "masih tanpa dosen wali" dicek ulang
+	// oleh UPDATE itu sendiri sehingga penetapan paralel tidak saling menimpa
+	update := tx.Model(&model.Student{}).Where("id = ?", change.StudentID)
+	if opts.OnlyUnassigned {
+		update = update.Where("advisor_id IS NULL")
+	}
+	result := update.Update("advisor_id", change.AdvisorID)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if opts.OnlyUnassigned && result.RowsAffected == 0 {
+		return 0, ErrStudentAlreadyAssigned
+	}
+
+	// 3. Prestasi yang sedang menunggu verifikasi
+	affected, err := applyInFlightPolicy(tx, change, opts.InFlightPolicy)
+	if err != nil {
+		return 0, err
+	}
+
+	// 4. Riwayat baru
+	assignment := model.AdvisorAssignment{
+		StudentID:         change.StudentID,
+		AdvisorID:         change.AdvisorID,
+		PreviousAdvisorID: change.PreviousAdvisorID,
+		EffectiveFrom:     opts.EffectiveFrom,
+		Reason:            opts.Reason,
+		InFlightPolicy:    opts.InFlightPolicy,
+		InFlightCount:     affected,
+		AssignedBy:        opts.AssignedBy,
+	}
+	if err := tx.Omit("Advisor").Create(&assignment).Error; err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// applyInFlightPolicy memperlakukan prestasi SUBMITTED mahasiswa sesuai kebijakan:
+//   - transfer: pin dosen wali lama dilepas, verifikasi ikut dosen wali baru
+//   - retain:   prestasi di-pin ke dosen wali lama (pin yang sudah ada dipertahankan)
+//   - reset:    prestasi kembali ke DRAFT dan harus diajukan ulang
+func applyInFlightPolicy(tx *gorm.DB, change model.AdvisorChange, policy string) (int, error) {
+	submitted := func() *gorm.DB {
+		return tx.Model(&model.AchievementReference{}).
+			Where("student_id = ? AND status = ?", change.StudentID, model.StatusSubmitted)
+	}
+
+	var result *gorm.DB
+	switch policy {
+	case model.InFlightRetain:
+		if change.PreviousAdvisorID == nil {
+			// Tidak ada dosen wali lama: sama dengan transfer
+			result = submitted().Update("pinned_advisor_id", nil)
+			break
+		}
+		// Pin yang sudah ada menunjuk dosen wali yang lebih lama lagi, jangan ditimpa
+		var pinned int64
+		if err := submitted().Where("pinned_advisor_id IS NOT NULL").Count(&pinned).Error; err != nil {
+			return 0, err
+		}
+		result = submitted().Where("pinned_advisor_id IS NULL").Update("pinned_advisor_id", *change.PreviousAdvisorID)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		return int(result.RowsAffected + pinned), nil
+	case model.InFlightReset:
+		result = submitted().Updates(map[string]interface{}{
+			"status":            model.StatusDraft,
+			"submitted_at":      nil,
+			"pinned_advisor_id": nil,
+		})
+	default:
+		result = submitted().Update("pinned_advisor_id", nil)
+	}
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(key *model.APIKey) error
+	FindByHash(keyHash string) (*model.APIKey, error)
+	FindByServiceAccountID(serviceAccountID uuid.UUID) ([]model.APIKey, error)
+	Revoke(id, serviceAccountID uuid.UUID, at time.Time) error
+	// TouchLastUsed hanya menulis bila last_used_at lebih lama dari minInterval (hemat write per request)
+	TouchLastUsed(id uuid.UUID, ip string, at time.Time, minInterval time.Duration) error
+}
+
+type apiKeyRepositoryGORM struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepositoryGORM{db: db}
+}
+
+func (r *apiKeyRepositoryGORM) Create(key *model.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepositoryGORM) FindByHash(keyHash string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("api key not found")
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepositoryGORM) FindByServiceAccountID(serviceAccountID uuid.UUID) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.db.Where("service_account_id = ?", serviceAccountID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepositoryGORM) Revoke(id, serviceAccountID uuid.UUID, at time.Time) error {
+	result := r.db.Model(&model.APIKey{}).
+		Where("id = ? AND service_account_id = ? AND revoked_at IS NULL", id, serviceAccountID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("api key not found or already revoked")
+	}
+	return nil
+}
+
+func (r *apiKeyRepositoryGORM) TouchLastUsed(id uuid.UUID, ip string, at time.Time, minInterval time.Duration) error {
+	return r.db.Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ? OR last_used_ip <> ?)", id, at.Add(-minInterval), ip).
+		Updates(map[string]interface{}{"last_used_at": at, "last_used_ip": ip}).Error
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"github.com/fitrinovs/achievement_system/app/model"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository interface {
+	Create(entry *model.AuditLog) error
+	FindAll(filter model.AuditLogFilter) ([]model.AuditLog, error)
+}
+
+type auditLogRepositoryGORM struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepositoryGORM{db: db}
+}
+
+func (r *auditLogRepositoryGORM) Create(entry *model.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+// FindAll mengembalikan log terbaru lebih dulu
+func (r *auditLogRepositoryGORM) FindAll(filter model.AuditLogFilter) ([]model.AuditLog, error) {
+	query := r.db.Model(&model.AuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.SubjectUserID != nil {
+		query = query.Where("subject_user_id = ?", *filter.SubjectUserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var logs []model.AuditLog
+	err := query.Order("created_at DESC").Find(&logs).Error
+	return logs, err
+}
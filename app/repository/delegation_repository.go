@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DelegationRepository interface {
+	Create(delegation *model.VerificationDelegation) error
+	FindByID(id uuid.UUID) (*model.VerificationDelegation, error)
+	FindAll(filter model.DelegationFilter) ([]model.VerificationDelegation, error)
+	Revoke(id uuid.UUID, revokedBy uuid.UUID, at time.Time) error
+	// HasOverlap mengecek delegasi aktif (belum dicabut) dengan pasangan yang sama dan rentang waktu beririsan
+	HasOverlap(principalID, delegateID uuid.UUID, startsAt, endsAt time.Time) (bool, error)
+	// FindActivePrincipalIDs mengembalikan ID dosen yang wewenangnya sedang didelegasikan ke delegateID
+	FindActivePrincipalIDs(delegateID uuid.UUID, at time.Time) ([]uuid.UUID, error)
+}
+
+type delegationRepositoryGORM struct {
+	db *gorm.DB
+}
+
+func NewDelegationRepository(db *gorm.DB) DelegationRepository {
+	return &delegationRepositoryGORM{db: db}
+}
+
+// preloadDelegationParties memuat pemberi dan penerima delegasi, termasuk dosen yang sudah di-soft-delete
+func preloadDelegationParties(db *gorm.DB) *gorm.DB {
+	return db.Preload("Principal", includeDeleted).Preload("Principal.User", includeDeleted).
+		Preload("Delegate", includeDeleted).Preload("Delegate.User", includeDeleted)
+}
+
+func (r *delegationRepositoryGORM) Create(delegation *model.VerificationDelegation) error {
+	return r.db.Create(delegation).Error
+}
+
+func (r *delegationRepositoryGORM) FindByID(id uuid.UUID) (*model.VerificationDelegation, error) {
+	var delegation model.VerificationDelegation
+	err := r.db.Scopes(preloadDelegationParties).
+		Where("id = ?", id).
+		First(&delegation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("delegation not found")
+		}
+		return nil, err
+	}
+	return &delegation, nil
+}
+
+func (r *delegationRepositoryGORM) FindAll(filter model.DelegationFilter) ([]model.VerificationDelegation, error) {
+	query := r.db.Scopes(preloadDelegationParties)
+	if filter.LecturerID != nil {
+		query = query.Where("principal_id = ? OR delegate_id = ?", *filter.LecturerID, *filter.LecturerID)
+	}
+	if filter.ActiveAt != nil {
+		query = query.Where("revoked_at IS NULL AND starts_at <= ? AND ends_at > ?", *filter.ActiveAt, *filter.ActiveAt)
+	}
+
+	var delegations []model.VerificationDelegation
+	err := query.Order("starts_at DESC").Find(&delegations).Error
+	return delegations, err
+}
+
+func (r *delegationRepositoryGORM) Revoke(id uuid.UUID, revokedBy uuid.UUID, at time.Time) error {
+	result := r.db.Model(&model.VerificationDelegation{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": at, "revoked_by": revokedBy})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("delegation not found or already revoked")
+	}
+	return nil
+}
+
+func (r *delegationRepositoryGORM) HasOverlap(principalID, delegateID uuid.UUID, startsAt, endsAt time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.VerificationDelegation{}).
+		Where("principal_id = ? AND delegate_id = ? AND revoked_at IS NULL", principalID, delegateID).
+		Where("starts_at < ? AND ends_at > ?", endsAt, startsAt).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *delegationRepositoryGORM) FindActivePrincipalIDs(delegateID uuid.UUID, at time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&model.VerificationDelegation{}).
+		Where("delegate_id = ? AND revoked_at IS NULL AND starts_at <= ? AND ends_at > ?", delegateID, at, at).
+		Distinct().
+		Pluck("principal_id", &ids).Error
+	return ids, err
+}
@@ -9,12 +9,43 @@ import (
 type LecturerRepository interface {
 	FindByID(id uuid.UUID) (*model.Lecturer, error)
 	FindByUserID(userID uuid.UUID) (*model.Lecturer, error)
+	// FindByLecturerID mencari berdasarkan NIP
+	FindByLecturerID(lecturerID string) (*model.Lecturer, error)
+	// FindByLecturerIDs mengambil dosen (beserta User) untuk banyak NIP sekaligus, dipakai import massal
+	FindByLecturerIDs(lecturerIDs []string) ([]model.Lecturer, error)
 	FindAll() ([]*model.Lecturer, error)
+	// List mengambil satu halaman dosen (search nama/NIP/email, filter, sort) beserta metadata paging
+	List(q model.ListQuery) ([]model.Lecturer, model.PageMeta, error)
 	Create(lecturer *model.Lecturer) error
 	Update(lecturer *model.Lecturer) error
 	Delete(id uuid.UUID) error
 }
 
+// lecturerListSpec: filter department, department_id (UUID atau "none") dan active (status user)
+var lecturerListSpec = listSpec[model.Lecturer]{
+	IDColumn: "lecturers.id",
+	ID:       func(l *model.Lecturer) uuid.UUID { return l.ID },
+	Sorts: map[string]listSortField[model.Lecturer]{
+		"name": {Column: "users.full_name", Value: func(l *model.Lecturer) interface{} {
+			if l.User == nil {
+				return ""
+			}
+			return l.User.FullName
+		}},
+		"nip":        {Column: "lecturers.lecturer_id", Value: func(l *model.Lecturer) interface{} { return l.LecturerID }},
+		"department": {Column: "COALESCE(lecturers.department, '')", Value: func(l *model.Lecturer) interface{} { return l.Department }},
+		"created_at": {Column: "lecturers.created_at", Value: func(l *model.Lecturer) interface{} { return cursorTime(l.CreatedAt) }},
+	},
+	DefaultSort:   "name",
+	SearchColumns: []string{"users.full_name", "lecturers.lecturer_id", "users.email"},
+	Filters: map[string]listFilter{
+		"department":    equalsIgnoreCaseFilter("lecturers.department"),
+		"department_id": uuidOrNoneFilter("lecturers.department_id"),
+		"active":        activeFilter("users.is_active"),
+	},
+	Preloads: []string{"User", "DepartmentUnit"},
+}
+
 type lecturerRepositoryGORM struct {
 	db *gorm.DB
 }
@@ -25,7 +56,7 @@ func NewLecturerRepository(db *gorm.DB) LecturerRepository {
 
 func (r *lecturerRepositoryGORM) FindByID(id uuid.UUID) (*model.Lecturer, error) {
 	var lecturer model.Lecturer
-	err := r.db.Preload("User").First(&lecturer, "id = ?", id).Error
+	err := r.db.Preload("User").Preload("DepartmentUnit").First(&lecturer, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -34,19 +65,42 @@ func (r *lecturerRepositoryGORM) FindByID(id uuid.UUID) (*model.Lecturer, error)
 
 func (r *lecturerRepositoryGORM) FindByUserID(userID uuid.UUID) (*model.Lecturer, error) {
 	var lecturer model.Lecturer
-	err := r.db.Preload("User").First(&lecturer, "user_id = ?", userID).Error
+	err := r.db.Preload("User").Preload("DepartmentUnit").First(&lecturer, "user_id = ?", userID).Error
 	if err != nil {
 		return nil, err
 	}
 	return &lecturer, nil
 }
 
+func (r *lecturerRepositoryGORM) FindByLecturerID(lecturerID string) (*model.Lecturer, error) {
+	var lecturer model.Lecturer
+	err := r.db.Preload("User").First(&lecturer, "lecturer_id = ?", lecturerID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &lecturer, nil
+}
+
+func (r *lecturerRepositoryGORM) FindByLecturerIDs(lecturerIDs []string) ([]model.Lecturer, error) {
+	var lecturers []model.Lecturer
+	if len(lecturerIDs) == 0 {
+		return lecturers, nil
+	}
+	err := r.db.Preload("User").Where("lecturer_id IN ?", lecturerIDs).Find(&lecturers).Error
+	return lecturers, err
+}
+
 func (r *lecturerRepositoryGORM) FindAll() ([]*model.Lecturer, error) {
 	var lecturers []*model.Lecturer
 	err := r.db.Preload("User").Find(&lecturers).Error
 	return lecturers, err
 }
 
+func (r *lecturerRepositoryGORM) List(q model.ListQuery) ([]model.Lecturer, model.PageMeta, error) {
+	base := r.db.Model(&model.Lecturer{}).Joins("JOIN users ON users.id = lecturers.user_id")
+	return findListPage(base, lecturerListSpec, q)
+}
+
 func (r *lecturerRepositoryGORM) Create(lecturer *model.Lecturer) error {
 	return r.db.Create(lecturer).Error
 }
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidListQuery dibungkus oleh semua error validasi sort/filter/cursor (dipetakan ke 400 di service)
+var ErrInvalidListQuery = errors.New("invalid list query")
+
+func invalidListQuery(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidListQuery, fmt.Sprintf(format, args...))
+}
+
+// listSortField adalah field yang boleh dipakai di ?sort=. Column harus non-NULL
+// (gunakan COALESCE) agar keyset pagination konsisten.
+type listSortField[T any] struct {
+	Column string
+	Value  func(row *T) interface{}
+}
+
+// listFilter menerjemahkan nilai filter mentah menjadi klausa WHERE (placeholder "?").
+// Mengembalikan clause kosong bila nilai berarti "tanpa filter" (mis. active=all).
+type listFilter func(value string) (clause string, args []interface{}, err error)
+
+// listSpec mendeskripsikan field yang bisa dicari, difilter, dan diurutkan untuk satu resource
+type listSpec[T any] struct {
+	IDColumn      string
+	ID            func(row *T) uuid.UUID
+	Sorts         map[string]listSortField[T]
+	DefaultSort   string
+	SearchColumns []string
+	Filters       map[string]listFilter
+	// Preloads hanya dipasang pada query halaman (bukan COUNT)
+	Preloads []string
+	// HistoricalPreloads ikut memuat baris yang sudah di-soft-delete (lihat includeDeleted)
+	HistoricalPreloads []string
+}
+
+type listClause struct {
+	SQL  string
+	Args []interface{}
+}
+
+// listPlan adalah hasil kompilasi ListQuery: filter (juga dipakai COUNT), kondisi cursor, dan urutan
+type listPlan struct {
+	Filters []listClause
+	Cursor  *listClause
+	Order   string
+	Limit   int
+	Offset  int
+	SortKey string // mis. "name" atau "-created_at"
+}
+
+type listCursor struct {
+	Sort  string      `json:"s"`
+	Value interface{} `json:"v"`
+	ID    uuid.UUID   `json:"id"`
+}
+
+func (spec listSpec[T]) plan(q model.ListQuery) (*listPlan, error) {
+	sortName := q.Sort
+	if sortName == "" {
+		sortName = spec.DefaultSort
+	}
+	field, ok := spec.Sorts[sortName]
+	if !ok {
+		return nil, invalidListQuery("unsupported sort field %q (allowed: %s)", sortName, strings.Join(spec.sortNames(), ", "))
+	}
+
+	direction, comparator := "ASC", ">"
+	sortKey := sortName
+	if q.SortDesc {
+		direction, comparator = "DESC", "<"
+		sortKey = "-" + sortName
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = model.DefaultListLimit
+	}
+	if limit > model.MaxListLimit {
+		limit = model.MaxListLimit
+	}
+
+	plan := &listPlan{
+		Order:   fmt.Sprintf("%s %s, %s %s", field.Column, direction, spec.IDColumn, direction),
+		Limit:   limit,
+		SortKey: sortKey,
+	}
+
+	if q.Search != "" && len(spec.SearchColumns) > 0 {
+		pattern := "%" + escapeLike(q.Search) + "%"
+		parts := make([]string, 0, len(spec.SearchColumns))
+		args := make([]interface{}, 0, len(spec.SearchColumns))
+		for _, column := range spec.SearchColumns {
+			parts = append(parts, column+" ILIKE ?")
+			args = append(args, pattern)
+		}
+		plan.Filters = append(plan.Filters, listClause{SQL: "(" + strings.Join(parts, " OR ") + ")", Args: args})
+	}
+
+	filterNames := make([]string, 0, len(q.Filters))
+	for name := range q.Filters {
+		filterNames = append(filterNames, name)
+	}
+	sort.Strings(filterNames)
+	for _, name := range filterNames {
+		build, ok := spec.Filters[name]
+		if !ok {
+			return nil, invalidListQuery("unsupported filter %q", name)
+		}
+		clause, args, err := build(q.Filters[name])
+		if err != nil {
+			return nil, invalidListQuery("filter %s: %v", name, err)
+		}
+		if clause != "" {
+			plan.Filters = append(plan.Filters, listClause{SQL: clause, Args: args})
+		}
+	}
+
+	if q.Cursor != "" {
+		cursor, err := decodeListCursor(q.Cursor)
+		if err != nil || cursor.Sort != sortKey {
+			return nil, invalidListQuery("invalid cursor for sort %q", sortKey)
+		}
+		plan.Cursor = &listClause{
+			SQL:  fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", field.Column, comparator, field.Column, spec.IDColumn, comparator),
+			Args: []interface{}{cursor.Value, cursor.Value, cursor.ID},
+		}
+	} else if q.Page > 1 {
+		plan.Offset = (q.Page - 1) * limit
+	}
+	return plan, nil
+}
+
+func (spec listSpec[T]) sortNames() []string {
+	names := make([]string, 0, len(spec.Sorts))
+	for name := range spec.Sorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// finish memotong baris ekstra (limit+1) dan menyusun PageMeta. total nil pada mode cursor.
+func (spec listSpec[T]) finish(rows []T, plan *listPlan, q model.ListQuery, total *int64) ([]T, model.PageMeta) {
+	meta := model.PageMeta{Limit: plan.Limit, Sort: plan.SortKey}
+
+	if len(rows) > plan.Limit {
+		rows = rows[:plan.Limit]
+		meta.HasMore = true
+	}
+	if meta.HasMore && len(rows) > 0 {
+		sortName := strings.TrimPrefix(plan.SortKey, "-")
+		last := &rows[len(rows)-1]
+		meta.NextCursor = encodeListCursor(listCursor{
+			Sort:  plan.SortKey,
+			Value: spec.Sorts[sortName].Value(last),
+			ID:    spec.ID(last),
+		})
+	}
+
+	if plan.Cursor == nil && total != nil {
+		page := q.Page
+		if page < 1 {
+			page = 1
+		}
+		totalPages := int((*total + int64(plan.Limit) - 1) / int64(plan.Limit))
+		meta.Page = page
+		meta.Total = total
+		meta.TotalPages = &totalPages
+	}
+	if rows == nil {
+		rows = []T{}
+	}
+	return rows, meta
+}
+
+// applyListFilters memasang klausa search/filter ke query GORM
+func applyListFilters(tx *gorm.DB, plan *listPlan) *gorm.DB {
+	for _, clause := range plan.Filters {
+		tx = tx.Where(clause.SQL, clause.Args...)
+	}
+	return tx
+}
+
+// findListPage menjalankan COUNT (mode offset) lalu mengambil satu halaman (+1 baris untuk has_more)
+func findListPage[T any](base *gorm.DB, spec listSpec[T], q model.ListQuery) ([]T, model.PageMeta, error) {
+	plan, err := spec.plan(q)
+	if err != nil {
+		return nil, model.PageMeta{}, err
+	}
+
+	filtered := applyListFilters(base, plan).Session(&gorm.Session{})
+
+	var total *int64
+	if plan.Cursor == nil {
+		var count int64
+		if err := filtered.Count(&count).Error; err != nil {
+			return nil, model.PageMeta{}, err
+		}
+		total = &count
+	}
+
+	page := filtered
+	if plan.Cursor != nil {
+		page = page.Where(plan.Cursor.SQL, plan.Cursor.Args...)
+	}
+	for _, relation := range spec.Preloads {
+		page = page.Preload(relation)
+	}
+	for _, relation := range spec.HistoricalPreloads {
+		page = page.Preload(relation, includeDeleted)
+	}
+	var rows []T
+	if err := page.Order(plan.Order).Limit(plan.Limit + 1).Offset(plan.Offset).Find(&rows).Error; err != nil {
+		return nil, model.PageMeta{}, err
+	}
+
+	rows, meta := spec.finish(rows, plan, q, total)
+	return rows, meta, nil
+}
+
+// whereSQL menyusun klausa WHERE (placeholder "?") untuk implementasi database/sql
+func (plan *listPlan) whereSQL(withCursor bool) (string, []interface{}) {
+	clauses := plan.Filters
+	if withCursor && plan.Cursor != nil {
+		clauses = append(clauses[:len(clauses):len(clauses)], *plan.Cursor)
+	}
+	if len(clauses) == 0 {
+		return "", nil
+	}
+
+	parts := make([]string, 0, len(clauses))
+	var args []interface{}
+	for _, clause := range clauses {
+		parts = append(parts, clause.SQL)
+		args = append(args, clause.Args...)
+	}
+	return " WHERE " + strings.Join(parts, " AND "), args
+}
+
+// rebindDollar mengubah placeholder "?" menjadi $1, $2, ... (format PostgreSQL)
+func rebindDollar(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, ch := range query {
+		if ch == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
+
+func encodeListCursor(cursor listCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeListCursor(value string) (*listCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// cursorTime menyeragamkan nilai waktu di cursor (presisi penuh, zona UTC)
+func cursorTime(t time.Time) interface{} {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// Builder filter yang dipakai bersama oleh beberapa resource
+
+func equalsIgnoreCaseFilter(column string) listFilter {
+	return func(value string) (string, []interface{}, error) {
+		return "LOWER(" + column + ") = LOWER(?)", []interface{}{value}, nil
+	}
+}
+
+func equalsFilter(column string) listFilter {
+	return func(value string) (string, []interface{}, error) {
+		return column + " = ?", []interface{}{value}, nil
+	}
+}
+
+// activeFilter menerima true/false; "all" berarti tanpa filter
+func activeFilter(column string) listFilter {
+	return func(value string) (string, []interface{}, error) {
+		if strings.EqualFold(value, "all") {
+			return "", nil, nil
+		}
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", nil, errors.New("must be true, false, or all")
+		}
+		return column + " = ?", []interface{}{active}, nil
+	}
+}
+
+// uuidOrNoneFilter menerima UUID atau "none" (kolom NULL)
+func uuidOrNoneFilter(column string) listFilter {
+	return func(value string) (string, []interface{}, error) {
+		if strings.EqualFold(value, "none") {
+			return column + " IS NULL", nil, nil
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return "", nil, errors.New("must be a UUID or none")
+		}
+		return column + " = ?", []interface{}{id}, nil
+	}
+}
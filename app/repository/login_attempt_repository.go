@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LoginAttemptRepository interface {
+	Create(attempt *model.LoginAttempt) error
+	FailureStatsByUserID(userID uuid.UUID, since time.Time) (*model.LoginFailureStats, error)
+	FailureStatsByIP(ip string, since time.Time) (*model.LoginFailureStats, error)
+	DeleteByUserID(userID uuid.UUID) error
+}
+
+type loginAttemptRepositoryGORM struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepositoryGORM{db: db}
+}
+
+func (r *loginAttemptRepositoryGORM) Create(attempt *model.LoginAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+func (r *loginAttemptRepositoryGORM) FailureStatsByUserID(userID uuid.UUID, since time.Time) (*model.LoginFailureStats, error) {
+	return r.failureStats(r.db.Where("user_id = ? AND created_at >= ?", userID, since))
+}
+
+func (r *loginAttemptRepositoryGORM) FailureStatsByIP(ip string, since time.Time) (*model.LoginFailureStats, error) {
+	return r.failureStats(r.db.Where("ip_address = ? AND created_at >= ?", ip, since))
+}
+
+// DeleteByUserID mereset counter akun (login sukses atau unlock oleh admin)
+func (r *loginAttemptRepositoryGORM) DeleteByUserID(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&model.LoginAttempt{}).Error
+}
+
+func (r *loginAttemptRepositoryGORM) failureStats(query *gorm.DB) (*model.LoginFailureStats, error) {
+	var stats model.LoginFailureStats
+	err := query.Model(&model.LoginAttempt{}).
+		Select("COUNT(*) AS count, MAX(created_at) AS last_attempt_at").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
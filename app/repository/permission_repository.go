@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PermissionRepository interface {
+	FindAll() ([]model.Permission, error)
+	FindByID(id uuid.UUID) (*model.Permission, error)
+	FindByName(name string) (*model.Permission, error)
+	FindByIDs(ids []uuid.UUID) ([]model.Permission, error)
+	Create(permission *model.Permission) error
+	Update(permission *model.Permission) error
+	Delete(id uuid.UUID) error
+}
+
+type permissionRepositoryGORM struct {
+	db *gorm.DB
+}
+
+func NewPermissionRepository(db *gorm.DB) PermissionRepository {
+	return &permissionRepositoryGORM{db: db}
+}
+
+func (r *permissionRepositoryGORM) FindAll() ([]model.Permission, error) {
+	var permissions []model.Permission
+	err := r.db.Order("resource, action").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *permissionRepositoryGORM) FindByID(id uuid.UUID) (*model.Permission, error) {
+	var permission model.Permission
+	err := r.db.Where("id = ?", id).First(&permission).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("permission not found")
+		}
+		return nil, err
+	}
+	return &permission, nil
+}
+
+func (r *permissionRepositoryGORM) FindByName(name string) (*model.Permission, error) {
+	var permission model.Permission
+	err := r.db.Where("name = ?", name).First(&permission).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("permission not found")
+		}
+		return nil, err
+	}
+	return &permission, nil
+}
+
+func (r *permissionRepositoryGORM) FindByIDs(ids []uuid.UUID) ([]model.Permission, error) {
+	var permissions []model.Permission
+	if len(ids) == 0 {
+		return permissions, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *permissionRepositoryGORM) Create(permission *model.Permission) error {
+	return r.db.Create(permission).Error
+}
+
+func (r *permissionRepositoryGORM) Update(permission *model.Permission) error {
+	return r.db.Save(permission).Error
+}
+
+// Delete menghapus permission beserta relasinya di role_permissions
+func (r *permissionRepositoryGORM) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("permission_id = ?", id).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&model.Permission{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("permission not found")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProvisioningRepository menulis user beserta profil mahasiswa/dosennya secara atomik
+type ProvisioningRepository interface {
+	// SaveAll membuat atau memperbarui semua record dalam SATU transaksi: gagal satu, batal semua
+	SaveAll(records []model.ProvisionRecord) error
+}
+
+type provisioningRepositoryGORM struct {
+	db *gorm.DB
+}
+
+func NewProvisioningRepository(db *gorm.DB) ProvisioningRepository {
+	return &provisioningRepositoryGORM{db: db}
+}
+
+func (r *provisioningRepositoryGORM) SaveAll(records []model.ProvisionRecord) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Profil tanpa FK master data ditautkan lewat teks program_study/department
+		matcher, err := (&academicUnitRepositoryGORM{db: tx}).LoadMatcher()
+		if err != nil {
+			return err
+		}
+
+		for i := range records {
+			record := &records[i]
+			if record.User == nil {
+				return fmt.Errorf("record %d has no user", i+1)
+			}
+
+			// Relasi (Role, User, Advisor) tidak ikut disimpan; hanya kolom milik tabel itu sendiri
+			if err := saveProvisioned(tx, record.User, record.User.ID); err != nil {
+				return fmt.Errorf("save user %s: %w", record.User.Username, err)
+			}
+
+			if record.Student != nil {
+				record.Student.UserID = record.User.ID
+				if record.Student.StudyProgramID == nil {
+					matcher.LinkStudent(record.Student)
+				}
+				previousAdvisorID, err := currentAdvisorID(tx, record.Student.ID)
+				if err != nil {
+					return fmt.Errorf("load student %s: %w", record.Student.NIM, err)
+				}
+				if err := saveProvisioned(tx, record.Student, record.Student.ID); err != nil {
+					return fmt.Errorf("save student %s: %w", record.Student.NIM, err)
+				}
+				if err := recordProvisionedAdvisor(tx, record.Student, previousAdvisorID, now); err != nil {
+					return fmt.Errorf("save advisor history %s: %w", record.Student.NIM, err)
+				}
+			}
+			if record.Lecturer != nil {
+				record.Lecturer.UserID = record.User.ID
+				if record.Lecturer.DepartmentID == nil {
+					matcher.LinkLecturer(record.Lecturer)
+				}
+				if err := saveProvisioned(tx, record.Lecturer, record.Lecturer.ID); err != nil {
+					return fmt.Errorf("save lecturer %s: %w", record.Lecturer.LecturerID, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func saveProvisioned(tx *gorm.DB, value interface{}, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return tx.Omit(clause.Associations).Create(value).Error
+	}
+	return tx.Omit(clause.Associations).Save(value).Error
+}
+
+// currentAdvisorID membaca advisor_id yang tersimpan sebelum profil mahasiswa ditimpa
+func currentAdvisorID(tx *gorm.DB, studentID uuid.UUID) (*uuid.UUID, error) {
+	if studentID == uuid.Nil {
+		return nil, nil
+	}
+	var advisorIDs []*uuid.UUID
+	if err := tx.Model(&model.Student{}).Where("id = ?", studentID).Pluck("advisor_id", &advisorIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(advisorIDs) == 0 {
+		return nil, nil
+	}
+	return advisorIDs[0], nil
+}
+
+// recordProvisionedAdvisor mencatat riwayat dosen wali bila provisioning/import mengubahnya.
+// Pengajuan yang sedang berjalan mengikuti dosen wali baru (kebijakan transfer).
+func recordProvisionedAdvisor(tx *gorm.DB, student *model.Student, previousAdvisorID *uuid.UUID, at time.Time) error {
+	if sameAdvisor(previousAdvisorID, student.AdvisorID) {
+		return nil
+	}
+	_, err := reassignAdvisorTx(tx, model.AdvisorChange{
+		StudentID:         student.ID,
+		PreviousAdvisorID: previousAdvisorID,
+		AdvisorID:         student.AdvisorID,
+	}, model.AdvisorChangeOptions{
+		EffectiveFrom:  at,
+		Reason:         "provisioning",
+		InFlightPolicy: model.InFlightTransfer,
+	})
+	return err
+}
+
+func sameAdvisor(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -14,7 +14,8 @@ type RoleRepository interface {
 	FindAll() ([]model.Role, error)
 	FindByID(id uuid.UUID) (*model.Role, error)
 	FindByName(name string) (*model.Role, error)
-	Create(role *model.Role) error
+	// Create menyimpan role beserta permission awalnya dalam satu transaksi
+	Create(role *model.Role, permissionIDs []uuid.UUID) error
 	Update(role *model.Role) error
 	Delete(id uuid.UUID) error
 	AssignPermissions(roleID uuid.UUID, permissionIDs []uuid.UUID) error
@@ -61,21 +62,46 @@ func (r *roleRepositoryGORM) FindByName(name string) (*model.Role, error) {
 	return &role, nil
 }
 
-func (r *roleRepositoryGORM) Create(role *model.Role) error {
-	return r.db.Create(role).Error
+func (r *roleRepositoryGORM) Create(role *model.Role, permissionIDs []uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Create(role).Error; err != nil {
+			return err
+		}
+		return replaceRolePermissionsTx(tx, role.ID, permissionIDs)
+	})
 }
 
 func (r *roleRepositoryGORM) Update(role *model.Role) error {
-	return r.db.Save(role).Error
+	// Permission role diubah lewat AssignPermissions, bukan lewat Save
+	return r.db.Omit("Permissions").Save(role).Error
 }
 
 func (r *roleRepositoryGORM) Delete(id uuid.UUID) error {
-	return r.db.Delete(&model.Role{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", id).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&model.Role{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("role not found")
+		}
+		return nil
+	})
 }
 
 func (r *roleRepositoryGORM) AssignPermissions(roleID uuid.UUID, permissionIDs []uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRolePermissionsTx(tx, roleID, permissionIDs)
+	})
+}
+
+// replaceRolePermissionsTx mengganti seluruh permission role di dalam transaksi yang sudah berjalan
+func replaceRolePermissionsTx(tx *gorm.DB, roleID uuid.UUID, permissionIDs []uuid.UUID) error {
 	// First, remove existing permissions
-	if err := r.db.Exec("DELETE FROM role_permissions WHERE role_id = ?", roleID).Error; err != nil {
+	if err := tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", roleID).Error; err != nil {
 		return err
 	}
 
@@ -85,7 +111,7 @@ func (r *roleRepositoryGORM) AssignPermissions(roleID uuid.UUID, permissionIDs [
 			RoleID:       roleID,
 			PermissionID: permID,
 		}
-		if err := r.db.Create(&rolePermission).Error; err != nil {
+		if err := tx.Create(&rolePermission).Error; err != nil {
 			return err
 		}
 	}
@@ -171,7 +197,7 @@ func (r *RoleRepositorySQL) FindByName(name string) (*model.Role, error) {
 	return &role, nil
 }
 
-func (r *RoleRepositorySQL) Create(role *model.Role) error {
+func (r *RoleRepositorySQL) Create(role *model.Role, permissionIDs []uuid.UUID) error {
 	query := `
 		INSERT INTO roles (id, name, description)
 		VALUES ($1, $2, $3)
@@ -182,8 +208,22 @@ func (r *RoleRepositorySQL) Create(role *model.Role) error {
 		role.ID = uuid.New()
 	}
 
-	err := r.DB.QueryRow(query, role.ID, role.Name, role.Description).Scan(&role.CreatedAt)
-	return err
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRow(query, role.ID, role.Name, role.Description).Scan(&role.CreatedAt); err != nil {
+		return err
+	}
+	for _, permID := range permissionIDs {
+		if _, err := tx.Exec("INSERT INTO role_permissions (role_id, permission_id) VALUES ($1, $2)", role.ID, permID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (r *RoleRepositorySQL) Update(role *model.Role) error {
@@ -211,6 +251,10 @@ func (r *RoleRepositorySQL) Update(role *model.Role) error {
 }
 
 func (r *RoleRepositorySQL) Delete(id uuid.UUID) error {
+	if _, err := r.DB.Exec(`DELETE FROM role_permissions WHERE role_id = $1`, id); err != nil {
+		return err
+	}
+
 	query := `DELETE FROM roles WHERE id = $1`
 
 	result, err := r.DB.Exec(query, id)
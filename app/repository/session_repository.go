@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(session *model.UserSession) error
+	FindByID(id uuid.UUID) (*model.UserSession, error)
+	// FindActiveByUserID mengambil sesi yang belum dicabut dan belum kedaluwarsa, terbaru dulu
+	FindActiveByUserID(userID uuid.UUID, at time.Time) ([]model.UserSession, error)
+	// Extend memperpanjang masa berlaku sesi saat refresh token dirotasi
+	Extend(id uuid.UUID, expiresAt time.Time) error
+	// TouchLastSeen hanya menulis bila last_seen_at lebih lama dari minInterval atau IP berubah
+	TouchLastSeen(id uuid.UUID, ip string, at time.Time, minInterval time.Duration) error
+	Revoke(id, userID, revokedBy uuid.UUID, at time.Time) error
+	// RevokeAllExcept mencabut semua sesi aktif user kecuali keepID (uuid.Nil = cabut semua)
+	RevokeAllExcept(userID, keepID, revokedBy uuid.UUID, at time.Time) ([]uuid.UUID, error)
+}
+
+type sessionRepositoryGORM struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepositoryGORM{db: db}
+}
+
+func (r *sessionRepositoryGORM) Create(session *model.UserSession) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepositoryGORM) FindByID(id uuid.UUID) (*model.UserSession, error) {
+	var session model.UserSession
+	err := r.db.First(&session, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("session not found")
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepositoryGORM) FindActiveByUserID(userID uuid.UUID, at time.Time) ([]model.UserSession, error) {
+	var sessions []model.UserSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, at).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepositoryGORM) Extend(id uuid.UUID, expiresAt time.Time) error {
+	return r.db.Model(&model.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("expires_at", expiresAt).Error
+}
+
+func (r *sessionRepositoryGORM) TouchLastSeen(id uuid.UUID, ip string, at time.Time, minInterval time.Duration) error {
+	return r.db.Model(&model.UserSession{}).
+		Where("id = ? AND (last_seen_at < ? OR last_seen_ip <> ?)", id, at.Add(-minInterval), ip).
+		Updates(map[string]interface{}{"last_seen_at": at, "last_seen_ip": ip}).Error
+}
+
+func (r *sessionRepositoryGORM) Revoke(id, userID, revokedBy uuid.UUID, at time.Time) error {
+	result := r.db.Model(&model.UserSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Updates(map[string]interface{}{"revoked_at": at, "revoked_by": revokedBy})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("session not found or already revoked")
+	}
+	return nil
+}
+
+func (r *sessionRepositoryGORM) RevokeAllExcept(userID, keepID, revokedBy uuid.UUID, at time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&model.UserSession{}).
+			Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, at)
+		if keepID != uuid.Nil {
+			query = query.Where("id <> ?", keepID)
+		}
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&model.UserSession{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"revoked_at": at, "revoked_by": revokedBy}).Error
+	})
+	return ids, err
+}
@@ -17,7 +17,8 @@ type StudentRepository interface {
 	FindAll() ([]model.Student, error)
 	FindByID(id uuid.UUID) (*model.Student, error)
 	FindByUserID(userID uuid.UUID) (*model.Student, error)
-	FindByAdvisorID(advisorID uuid.UUID) ([]model.Student, error)
+	// FindByAdvisorID: includeArchived false = tanpa mahasiswa lulus/keluar
+	FindByAdvisorID(advisorID uuid.UUID, includeArchived bool) ([]model.Student, error)
 	
 	Create(student *model.Student) error
 	Update(student *model.Student) error
@@ -27,6 +28,58 @@ type StudentRepository interface {
 	// METHOD BARU UNTUK REPORT SERVICE
 	FindAdviseeIDsByAdvisorID(advisorID uuid.UUID) ([]uuid.UUID, error) 
 	FindByStudentID(studentID string) (*model.Student, error) 
+
+	// FindByNIMs mengambil mahasiswa (beserta User) untuk banyak NIM sekaligus, dipakai import massal
+	FindByNIMs(nims []string) ([]model.Student, error)
+
+	// FindByIDs dan FindByCohort memilih mahasiswa untuk penugasan dosen wali massal
+	FindByIDs(ids []uuid.UUID) ([]model.Student, error)
+	FindByCohort(programStudy, academicYear string, onlyUnassigned bool) ([]model.Student, error)
+
+	// List mengambil satu halaman mahasiswa (search nama/NIM/email, filter, sort) beserta metadata paging
+	List(q model.ListQuery) ([]model.Student, model.PageMeta, error)
+
+	// ChangeStatus memindahkan status studi dan mencatat riwayatnya dalam satu transaksi.
+	// ErrStudentStatusChanged bila status di database sudah bukan change.FromStatus.
+	ChangeStatus(student *model.Student, change *model.StudentStatusChange) error
+	FindStatusHistory(studentID uuid.UUID) ([]model.StudentStatusChange, error)
+}
+
+// ErrStudentNotFound: user tidak punya profil mahasiswa
+var ErrStudentNotFound = errors.New("student not found")
+
+// ErrStudentStatusChanged: status mahasiswa diubah request lain sejak dibaca
+var ErrStudentStatusChanged = errors.New("student status was changed concurrently")
+
+// archivedStudentStatuses adalah status yang disembunyikan dari daftar bimbingan aktif
+var archivedStudentStatuses = []string{model.StudentStatusGraduated, model.StudentStatusDroppedOut}
+
+// studentListSpec: filter program_study, study_program_id, academic_year, advisor_id (UUID dosen atau "none"),
+// status (status studi) dan active (status user)
+var studentListSpec = listSpec[model.Student]{
+	IDColumn: "students.id",
+	ID:       func(st *model.Student) uuid.UUID { return st.ID },
+	Sorts: map[string]listSortField[model.Student]{
+		"name":          {Column: "users.full_name", Value: func(st *model.Student) interface{} { return st.User.FullName }},
+		"nim":           {Column: "students.nim", Value: func(st *model.Student) interface{} { return st.NIM }},
+		"program_study": {Column: "COALESCE(students.program_study, '')", Value: func(st *model.Student) interface{} { return st.ProgramStudy }},
+		"academic_year": {Column: "COALESCE(students.academic_year, '')", Value: func(st *model.Student) interface{} { return st.AcademicYear }},
+		"created_at":    {Column: "students.created_at", Value: func(st *model.Student) interface{} { return cursorTime(st.CreatedAt) }},
+	},
+	DefaultSort:   "nim",
+	SearchColumns: []string{"users.full_name", "students.nim", "users.email"},
+	Filters: map[string]listFilter{
+		"program_study":    equalsIgnoreCaseFilter("students.program_study"),
+		"study_program_id": uuidOrNoneFilter("students.study_program_id"), // "none" = teks belum terpetakan ke master data
+		"academic_year":    equalsFilter("students.academic_year"),
+		"advisor_id":       uuidOrNoneFilter("students.advisor_id"),
+		"status":           equalsFilter("students.status"),
+		"active":           activeFilter("users.is_active"),
+	},
+	Preloads: []string{"StudyProgram"},
+	// User ikut dimuat walau sudah di-soft-delete: nilai cursor sort "name" harus sama dengan
+	// users.full_name hasil JOIN, bukan string kosong dari User yang gagal di-preload
+	HistoricalPreloads: []string{"User", "Advisor", "Advisor.User"},
 }
 
 // =================================================================
@@ -44,7 +97,8 @@ func NewStudentRepository(db *gorm.DB) StudentRepository {
 func (r *studentRepository) FindAll() ([]model.Student, error) {
 	var students []model.Student
 	err := r.db.Preload("User").
-		Preload("Advisor.User").
+		Preload("Advisor", includeDeleted).
+		Preload("Advisor.User", includeDeleted).
 		Find(&students).Error
 	return students, err
 }
@@ -52,7 +106,9 @@ func (r *studentRepository) FindAll() ([]model.Student, error) {
 func (r *studentRepository) FindByID(id uuid.UUID) (*model.Student, error) {
 	var student model.Student
 	if err := r.db.Preload("User").
-		Preload("Advisor.User").
+		Preload("Advisor", includeDeleted). // dosen wali mahasiswa lulus/keluar bisa sudah dihapus
+		Preload("Advisor.User", includeDeleted).
+		Preload("StudyProgram").
 		First(&student, "id = ?", id).Error; err != nil {
 		return nil, errors.New("student not found")
 	}
@@ -62,20 +118,97 @@ func (r *studentRepository) FindByID(id uuid.UUID) (*model.Student, error) {
 func (r *studentRepository) FindByUserID(userID uuid.UUID) (*model.Student, error) {
 	var student model.Student
 	// Tambahkan Preload User agar bisa digunakan di report service
-	if err := r.db.Preload("User").First(&student, "user_id = ?", userID).Error; err != nil {
-		return nil, errors.New("student not found")
+	if err := r.db.Preload("User").Preload("StudyProgram").First(&student, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStudentNotFound
+		}
+		return nil, err
 	}
 	return &student, nil
 }
 
-func (r *studentRepository) FindByAdvisorID(advisorID uuid.UUID) ([]model.Student, error) {
+func (r *studentRepository) FindByAdvisorID(advisorID uuid.UUID, includeArchived bool) ([]model.Student, error) {
 	var students []model.Student
-	err := r.db.Where("advisor_id = ?", advisorID).
-		Preload("User").
-		Find(&students).Error
+	query := r.db.Where("advisor_id = ?", advisorID)
+	if !includeArchived {
+		query = query.Where("status NOT IN ?", archivedStudentStatuses)
+	}
+	err := query.Preload("User").Find(&students).Error
+	return students, err
+}
+
+func (r *studentRepository) List(q model.ListQuery) ([]model.Student, model.PageMeta, error) {
+	base := r.db.Model(&model.Student{}).Joins("JOIN users ON users.id = students.user_id")
+	return findListPage(base, studentListSpec, q)
+}
+
+func (r *studentRepository) FindByNIMs(nims []string) ([]model.Student, error) {
+	var students []model.Student
+	if len(nims) == 0 {
+		return students, nil
+	}
+	err := r.db.Preload("User").Where("nim IN ?", nims).Find(&students).Error
 	return students, err
 }
 
+func (r *studentRepository) FindByIDs(ids []uuid.UUID) ([]model.Student, error) {
+	var students []model.Student
+	if len(ids) == 0 {
+		return students, nil
+	}
+	err := r.db.Preload("User").Preload("StudyProgram").Where("id IN ?", ids).Find(&students).Error
+	return students, err
+}
+
+// FindByCohort: program studi dibandingkan case-insensitive, angkatan opsional
+// dan mahasiswa lulus/keluar tidak ikut dipilih
+func (r *studentRepository) FindByCohort(programStudy, academicYear string, onlyUnassigned bool) ([]model.Student, error) {
+	query := r.db.Preload("User").Preload("StudyProgram").Where("status NOT IN ?", archivedStudentStatuses)
+	if programStudy != "" {
+		query = query.Where("LOWER(program_study) = LOWER(?)", programStudy)
+	}
+	if academicYear != "" {
+		query = query.Where("academic_year = ?", academicYear)
+	}
+	if onlyUnassigned {
+		query = query.Where("advisor_id IS NULL")
+	}
+
+	var students []model.Student
+	err := query.Order("nim").Find(&students).Error
+	return students, err
+}
+
+func (r *studentRepository) ChangeStatus(student *model.Student, change *model.StudentStatusChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Student{}).
+			Where("id = ? AND status = ?", student.ID, change.FromStatus).
+			Updates(map[string]interface{}{"status": change.ToStatus, "status_since": change.EffectiveDate})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStudentStatusChanged
+		}
+		if err := tx.Create(change).Error; err != nil {
+			return err
+		}
+
+		student.Status = change.ToStatus
+		effective := change.EffectiveDate
+		student.StatusSince = &effective
+		return nil
+	})
+}
+
+func (r *studentRepository) FindStatusHistory(studentID uuid.UUID) ([]model.StudentStatusChange, error) {
+	var changes []model.StudentStatusChange
+	err := r.db.Where("student_id = ?", studentID).
+		Order("effective_date DESC, created_at DESC").
+		Find(&changes).Error
+	return changes, err
+}
+
 func (r *studentRepository) Create(student *model.Student) error {
 	return r.db.Create(student).Error
 }
@@ -127,7 +260,7 @@ func (r *studentRepository) FindByStudentID(studentID string) (*model.Student, e
 	// Preload User diperlukan agar student.User.FullName bisa diakses di service
 	if err := r.db.
 		Preload("User"). // Pastikan relasi User dimuat
-		Where("student_id = ?", studentID).
+		Where("nim = ?", studentID).
 		First(&student).
 		Error; err != nil {
 		
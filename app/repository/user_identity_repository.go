@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository interface {
+	FindBySubject(provider, issuer, subject string) (*model.UserIdentity, error)
+	FindByUserID(userID uuid.UUID) ([]model.UserIdentity, error)
+	Create(identity *model.UserIdentity) error
+	TouchLastLogin(id uuid.UUID, at time.Time) error
+}
+
+type userIdentityRepositoryGORM struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepositoryGORM{db: db}
+}
+
+func (r *userIdentityRepositoryGORM) FindBySubject(provider, issuer, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.Where("provider = ? AND issuer = ? AND subject = ?", provider, issuer, subject).
+		First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("identity not found")
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *userIdentityRepositoryGORM) FindByUserID(userID uuid.UUID) ([]model.UserIdentity, error) {
+	var identities []model.UserIdentity
+	err := r.db.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error
+	return identities, err
+}
+
+func (r *userIdentityRepositoryGORM) Create(identity *model.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *userIdentityRepositoryGORM) TouchLastLogin(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&model.UserIdentity{}).Where("id = ?", id).Update("last_login_at", at).Error
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/fitrinovs/achievement_system/app/model"
 
@@ -14,6 +16,16 @@ import (
 type UserRepository interface {
 	FindByUsername(username string) (*model.User, error)
 	FindByEmail(email string) (*model.User, error)
+	// FindByIdentifier mencari berdasarkan username ATAU email (case-insensitive) untuk login.
+	// User non-aktif ikut dikembalikan agar Login bisa menolaknya secara eksplisit setelah cek password.
+	FindByIdentifier(identifier string) (*model.User, error)
+	// IsUsernameTaken / IsEmailTaken juga memperhitungkan user non-aktif; excludeID untuk proses update.
+	// Keduanya memeriksa kolom username DAN email karena login menerima salah satunya.
+	IsUsernameTaken(username string, excludeID uuid.UUID) (bool, error)
+	IsEmailTaken(email string, excludeID uuid.UUID) (bool, error)
+	// FindIdentifierOwners versi massal IsUsernameTaken/IsEmailTaken (dipakai import): memetakan setiap
+	// nilai (ter-normalisasi) ke user yang memakainya sebagai username atau email, termasuk user non-aktif
+	FindIdentifierOwners(values []string) (map[string][]uuid.UUID, error)
 	FindByID(id uuid.UUID) (*model.User, error)
 	Create(user *model.User) error
 	Update(user *model.User) error
@@ -23,6 +35,53 @@ type UserRepository interface {
 	FindAll() ([]*model.User, error)
 	// TAMBAHAN: UpdateRole
 	UpdateRole(userID uuid.UUID, roleID uuid.UUID) error
+	FindByRoleID(roleID uuid.UUID) ([]*model.User, error)
+	// GetSecurityState mengambil role, status aktif, dan security version (termasuk user non-aktif)
+	GetSecurityState(userID uuid.UUID) (*model.UserSecurityState, error)
+	// BumpSecurityVersion mencabut semua token user yang sudah diterbitkan
+	BumpSecurityVersion(userID uuid.UUID) error
+	// UpdatePassword hanya menulis password_hash; pemanggil menaikkan security version
+	UpdatePassword(userID uuid.UUID, passwordHash string) error
+	// FindServiceAccounts mengambil semua service account (termasuk yang non-aktif)
+	FindServiceAccounts() ([]*model.User, error)
+	// List mengambil satu halaman user (search, filter role/active, sort) beserta metadata paging
+	List(q model.ListQuery) ([]model.User, model.PageMeta, error)
+	// UpdateSelfProfile hanya menulis kolom profil self-service (contact_email, phone, photo_url, bio)
+	UpdateSelfProfile(userID uuid.UUID, fields map[string]interface{}) error
+	// FindByIDIncludingInactive seperti FindByID tetapi ikut mengembalikan user non-aktif (untuk admin)
+	FindByIDIncludingInactive(id uuid.UUID) (*model.User, error)
+	// SetActive mengaktifkan/menonaktifkan user; security_version naik agar token lama dicabut
+	SetActive(userID uuid.UUID, active bool) error
+}
+
+// userListSpec: search username/email/nama; filter role (nama atau UUID) dan active
+var userListSpec = listSpec[model.User]{
+	IDColumn: "users.id",
+	ID:       func(u *model.User) uuid.UUID { return u.ID },
+	Sorts: map[string]listSortField[model.User]{
+		"name":     {Column: "users.full_name", Value: func(u *model.User) interface{} { return u.FullName }},
+		"username": {Column: "users.username", Value: func(u *model.User) interface{} { return u.Username }},
+		"email":    {Column: "users.email", Value: func(u *model.User) interface{} { return u.Email }},
+		"role": {Column: "COALESCE(roles.name, '')", Value: func(u *model.User) interface{} {
+			if u.Role == nil {
+				return ""
+			}
+			return u.Role.Name
+		}},
+		"created_at": {Column: "users.created_at", Value: func(u *model.User) interface{} { return cursorTime(u.CreatedAt) }},
+	},
+	DefaultSort:   "name",
+	SearchColumns: []string{"users.full_name", "users.username", "users.email"},
+	Filters: map[string]listFilter{
+		"role": func(value string) (string, []interface{}, error) {
+			if id, err := uuid.Parse(value); err == nil {
+				return "users.role_id = ?", []interface{}{id}, nil
+			}
+			return "LOWER(roles.name) = LOWER(?)", []interface{}{value}, nil
+		},
+		"active": activeFilter("users.is_active"),
+	},
+	Preloads: []string{"Role"},
 }
 
 // GORM Implementation
@@ -49,7 +108,7 @@ func NewUserRepositorySQL(db *sql.DB) UserRepository {
 func (r *userRepositoryGORM) FindByUsername(username string) (*model.User, error) {
 	var user model.User
 	err := r.db.Preload("Role.Permissions").
-		Where("username = ? AND is_active = ?", username, true).
+		Where("LOWER(username) = ? AND is_active = ?", model.NormalizeIdentifier(username), true).
 		First(&user).Error
 
 	if err != nil {
@@ -64,7 +123,7 @@ func (r *userRepositoryGORM) FindByUsername(username string) (*model.User, error
 func (r *userRepositoryGORM) FindByEmail(email string) (*model.User, error) {
 	var user model.User
 	err := r.db.Preload("Role.Permissions").
-		Where("email = ? AND is_active = ?", email, true).
+		Where("LOWER(email) = ? AND is_active = ?", model.NormalizeIdentifier(email), true).
 		First(&user).Error
 
 	if err != nil {
@@ -76,6 +135,96 @@ func (r *userRepositoryGORM) FindByEmail(email string) (*model.User, error) {
 	return &user, nil
 }
 
+// ErrAmbiguousIdentifier: identifier login cocok dengan lebih dari satu user
+// (mis. username user A sama dengan email user B pada data lama)
+var ErrAmbiguousIdentifier = errors.New("login identifier matches more than one user")
+
+func (r *userRepositoryGORM) FindByIdentifier(identifier string) (*model.User, error) {
+	var users []model.User
+	normalized := model.NormalizeIdentifier(identifier)
+	err := r.db.Preload("Role.Permissions").
+		Where("LOWER(username) = ? OR LOWER(email) = ?", normalized, normalized).
+		Limit(2).
+		Find(&users).Error
+
+	if err != nil {
+		return nil, err
+	}
+	switch len(users) {
+	case 0:
+		return nil, errors.New("user not found")
+	case 1:
+		return &users[0], nil
+	default:
+		return nil, ErrAmbiguousIdentifier
+	}
+}
+
+// IsUsernameTaken dan IsEmailTaken memakai Unscoped: user yang sudah dihapus (soft delete)
+// tetap memegang username/email-nya karena unique index berlaku untuk seluruh baris
+func (r *userRepositoryGORM) IsUsernameTaken(username string, excludeID uuid.UUID) (bool, error) {
+	return r.isIdentifierTaken(username, excludeID)
+}
+
+func (r *userRepositoryGORM) IsEmailTaken(email string, excludeID uuid.UUID) (bool, error) {
+	return r.isIdentifierTaken(email, excludeID)
+}
+
+// isIdentifierTaken: nilai tidak boleh dipakai sebagai username maupun email user lain
+func (r *userRepositoryGORM) isIdentifierTaken(value string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	normalized := model.NormalizeIdentifier(value)
+	err := r.db.Unscoped().Model(&model.User{}).
+		Where("(LOWER(username) = ? OR LOWER(email) = ?) AND id <> ?", normalized, normalized, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *userRepositoryGORM) FindIdentifierOwners(values []string) (map[string][]uuid.UUID, error) {
+	normalized := normalizeIdentifiers(values)
+	if len(normalized) == 0 {
+		return map[string][]uuid.UUID{}, nil
+	}
+	var users []model.User
+	err := r.db.Unscoped().Select("id", "username", "email").
+		Where("LOWER(username) IN ? OR LOWER(email) IN ?", normalized, normalized).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return identifierOwners(normalized, users), nil
+}
+
+func normalizeIdentifiers(values []string) []string {
+	normalized := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = model.NormalizeIdentifier(v); v != "" {
+			normalized = append(normalized, v)
+		}
+	}
+	return normalized
+}
+
+// identifierOwners memetakan nilai yang dicari ke ID user yang username atau email-nya sama
+func identifierOwners(values []string, users []model.User) map[string][]uuid.UUID {
+	wanted := make(map[string]bool, len(values))
+	for _, v := range values {
+		wanted[v] = true
+	}
+	owners := make(map[string][]uuid.UUID)
+	for _, user := range users {
+		username := model.NormalizeIdentifier(user.Username)
+		email := model.NormalizeIdentifier(user.Email)
+		if wanted[username] {
+			owners[username] = append(owners[username], user.ID)
+		}
+		if email != username && wanted[email] {
+			owners[email] = append(owners[email], user.ID)
+		}
+	}
+	return owners
+}
+
 func (r *userRepositoryGORM) FindByID(id uuid.UUID) (*model.User, error) {
 	var user model.User
 	err := r.db.Preload("Role.Permissions").
@@ -91,6 +240,21 @@ func (r *userRepositoryGORM) FindByID(id uuid.UUID) (*model.User, error) {
 	return &user, nil
 }
 
+func (r *userRepositoryGORM) FindByIDIncludingInactive(id uuid.UUID) (*model.User, error) {
+	var user model.User
+	err := r.db.Preload("Role.Permissions").
+		Where("id = ?", id).
+		First(&user).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
 // ===================================
 // TAMBAHAN: FindAll GORM
 // ===================================
@@ -105,6 +269,31 @@ func (r *userRepositoryGORM) FindAll() ([]*model.User, error) {
 	return users, nil
 }
 
+func (r *userRepositoryGORM) List(q model.ListQuery) ([]model.User, model.PageMeta, error) {
+	base := r.db.Model(&model.User{}).Joins("LEFT JOIN roles ON roles.id = users.role_id")
+	return findListPage(base, userListSpec, q)
+}
+
+func (r *userRepositoryGORM) FindServiceAccounts() ([]*model.User, error) {
+	var users []*model.User
+	err := r.db.Preload("Role").
+		Where("is_service_account = ?", true).
+		Order("username").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userRepositoryGORM) FindByRoleID(roleID uuid.UUID) ([]*model.User, error) {
+	var users []*model.User
+	err := r.db.Where("role_id = ?", roleID).
+		Order("username").
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (r *userRepositoryGORM) Create(user *model.User) error {
 	return r.db.Create(user).Error
 }
@@ -113,14 +302,30 @@ func (r *userRepositoryGORM) Update(user *model.User) error {
 	return r.db.Save(user).Error
 }
 
+// selfProfileColumns membatasi kolom yang boleh ditulis UpdateSelfProfile
+var selfProfileColumns = []string{"contact_email", "phone", "photo_url", "bio"}
+
+func (r *userRepositoryGORM) UpdateSelfProfile(userID uuid.UUID, fields map[string]interface{}) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Select(selfProfileColumns).
+		Updates(fields).Error
+}
+
 // ===================================
 // TAMBAHAN: UpdateRole GORM
 // ===================================
 func (r *userRepositoryGORM) UpdateRole(userID uuid.UUID, roleID uuid.UUID) error {
-	// Hanya update kolom role_id
+	// Hanya update kolom role_id; security_version dinaikkan agar token dengan role lama dicabut
 	result := r.db.Model(&model.User{}).
 		Where("id = ?", userID).
-		Update("role_id", roleID)
+		Updates(map[string]interface{}{
+			"role_id":          roleID,
+			"security_version": gorm.Expr("security_version + 1"),
+		})
 
 	if result.Error != nil {
 		return result.Error
@@ -131,8 +336,83 @@ func (r *userRepositoryGORM) UpdateRole(userID uuid.UUID, roleID uuid.UUID) erro
 	return nil
 }
 
+func (r *userRepositoryGORM) SetActive(userID uuid.UUID, active bool) error {
+	result := r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"is_active":        active,
+			"security_version": gorm.Expr("security_version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// Delete melakukan soft delete: baris user tetap ada agar referensi historis (mis. VerifiedBy,
+// riwayat dosen wali) tidak putus. User sekaligus dinonaktifkan, token lama dicabut, dan profil
+// mahasiswa/dosennya ikut di-soft-delete.
 func (r *userRepositoryGORM) Delete(id uuid.UUID) error {
-	return r.db.Delete(&model.User{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"is_active":        false,
+				"security_version": gorm.Expr("security_version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("user not found")
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&model.Student{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&model.Lecturer{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.User{}, "id = ?", id).Error
+	})
+}
+
+func (r *userRepositoryGORM) GetSecurityState(userID uuid.UUID) (*model.UserSecurityState, error) {
+	var state model.UserSecurityState
+	result := r.db.Model(&model.User{}).
+		Select("role_id, is_active, security_version").
+		Where("id = ?", userID).
+		Limit(1).
+		Scan(&state)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("user not found")
+	}
+	return &state, nil
+}
+
+func (r *userRepositoryGORM) BumpSecurityVersion(userID uuid.UUID) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("security_version", gorm.Expr("security_version + 1")).Error
+}
+
+func (r *userRepositoryGORM) UpdatePassword(userID uuid.UUID, passwordHash string) error {
+	result := r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("password_hash", passwordHash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+	return nil
 }
 
 func (r *userRepositoryGORM) GetUserPermissions(userID uuid.UUID) ([]string, error) {
@@ -163,13 +443,13 @@ func (r *UserRepositorySQL) FindByUsername(username string) (*model.User, error)
 		       r.id, r.name, r.description
 		FROM users u
 		JOIN roles r ON u.role_id = r.id
-		WHERE u.username = $1 AND u.is_active = true
+		WHERE LOWER(u.username) = $1 AND u.is_active = true
 	`
 
 	var user model.User
 	var role model.Role
 
-	err := r.DB.QueryRow(query, username).Scan(
+	err := r.DB.QueryRow(query, model.NormalizeIdentifier(username)).Scan(
 		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.FullName,
 		&user.RoleID, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
 		&role.ID, &role.Name, &role.Description,
@@ -193,13 +473,13 @@ func (r *UserRepositorySQL) FindByEmail(email string) (*model.User, error) {
 		       r.id, r.name, r.description
 		FROM users u
 		JOIN roles r ON u.role_id = r.id
-		WHERE u.email = $1 AND u.is_active = true
+		WHERE LOWER(u.email) = $1 AND u.is_active = true
 	`
 
 	var user model.User
 	var role model.Role
 
-	err := r.DB.QueryRow(query, email).Scan(
+	err := r.DB.QueryRow(query, model.NormalizeIdentifier(email)).Scan(
 		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.FullName,
 		&user.RoleID, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
 		&role.ID, &role.Name, &role.Description,
@@ -216,6 +496,104 @@ func (r *UserRepositorySQL) FindByEmail(email string) (*model.User, error) {
 	return &user, nil
 }
 
+func (r *UserRepositorySQL) FindByIdentifier(identifier string) (*model.User, error) {
+	query := `
+		SELECT u.id, u.username, u.email, u.password_hash, u.full_name, 
+		       u.role_id, u.is_active, u.is_service_account, u.created_at, u.updated_at,
+		       r.id, r.name, r.description
+		FROM users u
+		JOIN roles r ON u.role_id = r.id
+		WHERE (LOWER(u.username) = $1 OR LOWER(u.email) = $1) AND u.deleted_at IS NULL
+	`
+
+	rows, err := r.DB.Query(query+" LIMIT 2", model.NormalizeIdentifier(identifier))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		var user model.User
+		var role model.Role
+		err := rows.Scan(
+			&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.FullName,
+			&user.RoleID, &user.IsActive, &user.IsServiceAccount, &user.CreatedAt, &user.UpdatedAt,
+			&role.ID, &role.Name, &role.Description,
+		)
+		if err != nil {
+			return nil, err
+		}
+		user.Role = &role
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	switch len(users) {
+	case 0:
+		return nil, errors.New("user not found")
+	case 1:
+		return &users[0], nil
+	default:
+		return nil, ErrAmbiguousIdentifier
+	}
+}
+
+func (r *UserRepositorySQL) IsUsernameTaken(username string, excludeID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.DB.QueryRow(
+		`SELECT EXISTS(SELECT 1 FROM users WHERE (LOWER(username) = $1 OR LOWER(email) = $1) AND id <> $2)`,
+		model.NormalizeIdentifier(username), excludeID,
+	).Scan(&exists)
+	return exists, err
+}
+
+func (r *UserRepositorySQL) IsEmailTaken(email string, excludeID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.DB.QueryRow(
+		`SELECT EXISTS(SELECT 1 FROM users WHERE (LOWER(username) = $1 OR LOWER(email) = $1) AND id <> $2)`,
+		model.NormalizeIdentifier(email), excludeID,
+	).Scan(&exists)
+	return exists, err
+}
+
+func (r *UserRepositorySQL) FindIdentifierOwners(values []string) (map[string][]uuid.UUID, error) {
+	normalized := normalizeIdentifiers(values)
+	if len(normalized) == 0 {
+		return map[string][]uuid.UUID{}, nil
+	}
+	placeholders := make([]string, len(normalized))
+	args := make([]interface{}, len(normalized))
+	for i, v := range normalized {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = v
+	}
+	in := strings.Join(placeholders, ", ")
+	rows, err := r.DB.Query(
+		`SELECT id, username, email FROM users WHERE LOWER(username) IN (`+in+`) OR LOWER(email) IN (`+in+`)`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		var user model.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return identifierOwners(normalized, users), nil
+}
+
 func (r *UserRepositorySQL) FindByID(id uuid.UUID) (*model.User, error) {
 	query := `
 		SELECT u.id, u.username, u.email, u.password_hash, u.full_name, 
@@ -289,21 +667,137 @@ func (r *UserRepositorySQL) FindAll() ([]*model.User, error) {
 	return users, nil
 }
 
+func (r *UserRepositorySQL) List(q model.ListQuery) ([]model.User, model.PageMeta, error) {
+	plan, err := userListSpec.plan(q)
+	if err != nil {
+		return nil, model.PageMeta{}, err
+	}
+
+	from := " FROM (SELECT * FROM users WHERE deleted_at IS NULL) users LEFT JOIN roles ON roles.id = users.role_id"
+
+	var total *int64
+	if plan.Cursor == nil {
+		where, args := plan.whereSQL(false)
+		var count int64
+		if err := r.DB.QueryRow(rebindDollar("SELECT COUNT(*)"+from+where), args...).Scan(&count); err != nil {
+			return nil, model.PageMeta{}, err
+		}
+		total = &count
+	}
+
+	where, args := plan.whereSQL(true)
+	query := `SELECT users.id, users.username, users.email, users.password_hash, users.full_name,
+		       users.role_id, users.is_active, users.is_service_account, users.created_at, users.updated_at,
+		       COALESCE(roles.name, '')` + from + where + " ORDER BY " + plan.Order + " LIMIT ? OFFSET ?"
+	args = append(args, plan.Limit+1, plan.Offset)
+
+	rows, err := r.DB.Query(rebindDollar(query), args...)
+	if err != nil {
+		return nil, model.PageMeta{}, err
+	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		var user model.User
+		var roleName string
+		err := rows.Scan(
+			&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.FullName,
+			&user.RoleID, &user.IsActive, &user.IsServiceAccount, &user.CreatedAt, &user.UpdatedAt,
+			&roleName,
+		)
+		if err != nil {
+			return nil, model.PageMeta{}, err
+		}
+		user.Role = &model.Role{ID: user.RoleID, Name: roleName}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, model.PageMeta{}, err
+	}
+
+	users, meta := userListSpec.finish(users, plan, q, total)
+	return users, meta, nil
+}
+
+func (r *UserRepositorySQL) FindServiceAccounts() ([]*model.User, error) {
+	query := `
+		SELECT id, username, email, password_hash, full_name,
+		       role_id, is_active, is_service_account, created_at, updated_at
+		FROM users
+		WHERE is_service_account = true AND deleted_at IS NULL
+		ORDER BY username
+	`
+
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*model.User
+	for rows.Next() {
+		var user model.User
+		err := rows.Scan(
+			&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.FullName,
+			&user.RoleID, &user.IsActive, &user.IsServiceAccount, &user.CreatedAt, &user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *UserRepositorySQL) FindByRoleID(roleID uuid.UUID) ([]*model.User, error) {
+	query := `
+		SELECT id, username, email, password_hash, full_name,
+		       role_id, is_active, created_at, updated_at
+		FROM users
+		WHERE role_id = $1 AND deleted_at IS NULL
+		ORDER BY username
+	`
+
+	rows, err := r.DB.Query(query, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*model.User
+	for rows.Next() {
+		var user model.User
+		err := rows.Scan(
+			&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.FullName,
+			&user.RoleID, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	return users, rows.Err()
+}
+
 func (r *UserRepositorySQL) Create(user *model.User) error {
 	query := `
-		INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_active, is_service_account)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING created_at, updated_at
 	`
 
 	if user.ID == uuid.Nil {
 		user.ID = uuid.New()
 	}
+	user.NormalizeIdentity()
 
 	err := r.DB.QueryRow(
 		query,
 		user.ID, user.Username, user.Email, user.PasswordHash,
-		user.FullName, user.RoleID, user.IsActive,
+		user.FullName, user.RoleID, user.IsActive, user.IsServiceAccount,
 	).Scan(&user.CreatedAt, &user.UpdatedAt)
 
 	return err
@@ -317,6 +811,7 @@ func (r *UserRepositorySQL) Update(user *model.User) error {
 		WHERE id = $7
 		RETURNING updated_at
 	`
+	user.NormalizeIdentity()
 
 	err := r.DB.QueryRow(
 		query,
@@ -333,7 +828,7 @@ func (r *UserRepositorySQL) Update(user *model.User) error {
 func (r *UserRepositorySQL) UpdateRole(userID uuid.UUID, roleID uuid.UUID) error {
 	query := `
 		UPDATE users 
-		SET role_id = $1, updated_at = NOW()
+		SET role_id = $1, security_version = security_version + 1, updated_at = NOW()
 		WHERE id = $2
 	`
 
@@ -354,10 +849,106 @@ func (r *UserRepositorySQL) UpdateRole(userID uuid.UUID, roleID uuid.UUID) error
 	return nil
 }
 
+func (r *UserRepositorySQL) UpdateSelfProfile(userID uuid.UUID, fields map[string]interface{}) error {
+	sets := []string{}
+	args := []interface{}{}
+	for _, column := range selfProfileColumns {
+		value, ok := fields[column]
+		if !ok {
+			continue
+		}
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+
+	args = append(args, userID)
+	query := fmt.Sprintf(`UPDATE users SET %s, updated_at = NOW() WHERE id = $%d`, strings.Join(sets, ", "), len(args))
+	result, err := r.DB.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func (r *UserRepositorySQL) FindByIDIncludingInactive(id uuid.UUID) (*model.User, error) {
+	query := `
+		SELECT u.id, u.username, u.email, u.password_hash, u.full_name, 
+		       u.role_id, u.is_active, u.created_at, u.updated_at,
+		       r.id, r.name, r.description
+		FROM users u
+		JOIN roles r ON u.role_id = r.id
+		WHERE u.id = $1 AND u.deleted_at IS NULL
+	`
+
+	var user model.User
+	var role model.Role
+
+	err := r.DB.QueryRow(query, id).Scan(
+		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.FullName,
+		&user.RoleID, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
+		&role.ID, &role.Name, &role.Description,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	user.Role = &role
+	return &user, nil
+}
+
+func (r *UserRepositorySQL) SetActive(userID uuid.UUID, active bool) error {
+	query := `
+		UPDATE users 
+		SET is_active = $1, security_version = security_version + 1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.DB.Exec(query, active, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
 func (r *UserRepositorySQL) Delete(id uuid.UUID) error {
-	query := `DELETE FROM users WHERE id = $1`
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	result, err := r.DB.Exec(query, id)
+	result, err := tx.Exec(`
+		UPDATE users
+		SET is_active = false, security_version = security_version + 1, deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id)
 	if err != nil {
 		return err
 	}
@@ -371,6 +962,50 @@ func (r *UserRepositorySQL) Delete(id uuid.UUID) error {
 		return errors.New("user not found")
 	}
 
+	for _, table := range []string{"students", "lecturers"} {
+		if _, err := tx.Exec(`UPDATE `+table+` SET deleted_at = NOW() WHERE user_id = $1 AND deleted_at IS NULL`, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *UserRepositorySQL) GetSecurityState(userID uuid.UUID) (*model.UserSecurityState, error) {
+	query := `SELECT role_id, is_active, security_version FROM users WHERE id = $1`
+
+	var state model.UserSecurityState
+	err := r.DB.QueryRow(query, userID).Scan(&state.RoleID, &state.IsActive, &state.SecurityVersion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *UserRepositorySQL) BumpSecurityVersion(userID uuid.UUID) error {
+	query := `UPDATE users SET security_version = security_version + 1, updated_at = NOW() WHERE id = $1`
+	_, err := r.DB.Exec(query, userID)
+	return err
+}
+
+func (r *UserRepositorySQL) UpdatePassword(userID uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := r.DB.Exec(query, passwordHash, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("user not found")
+	}
 	return nil
 }
 
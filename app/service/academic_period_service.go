@@ -0,0 +1,293 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/config"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AcademicPeriodService interface {
+	GetAcademicPeriods(c *gin.Context)
+	GetActiveAcademicPeriod(c *gin.Context)
+	GetAcademicPeriodByID(c *gin.Context)
+	CreateAcademicPeriod(c *gin.Context)
+	UpdateAcademicPeriod(c *gin.Context)
+	DeleteAcademicPeriod(c *gin.Context)
+	ActivateAcademicPeriod(c *gin.Context)
+}
+
+type academicPeriodService struct {
+	periodRepo repository.AcademicPeriodRepository
+	cfg        config.AcademicPeriodConfig
+}
+
+func NewAcademicPeriodService(periodRepo repository.AcademicPeriodRepository, cfg config.AcademicPeriodConfig) AcademicPeriodService {
+	return &academicPeriodService{periodRepo: periodRepo, cfg: cfg}
+}
+
+// periodQuery membaca filter ?period_id= (UUID periode, atau "active" untuk periode aktif).
+// nil berarti tanpa filter; response error sudah ditulis bila ok == false.
+func periodQuery(c *gin.Context, periodRepo repository.AcademicPeriodRepository) (*model.AcademicPeriod, bool) {
+	raw := strings.TrimSpace(c.Query("period_id"))
+	if raw == "" {
+		return nil, true
+	}
+
+	if strings.EqualFold(raw, "active") {
+		period, err := periodRepo.FindActive()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return nil, false
+		}
+		if period == nil {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "no active academic period"})
+			return nil, false
+		}
+		return period, true
+	}
+
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid period_id format"})
+		return nil, false
+	}
+	period, err := periodRepo.FindByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return nil, false
+	}
+	return period, true
+}
+
+// periodID mengembalikan ID periode filter, nil bila tanpa filter
+func periodID(period *model.AcademicPeriod) *uuid.UUID {
+	if period == nil {
+		return nil
+	}
+	return &period.ID
+}
+
+// checkPeriod menulis 409 bila semester sudah terdaftar atau rentang tanggal beririsan dengan periode lain
+func (s *academicPeriodService) checkPeriod(c *gin.Context, period *model.AcademicPeriod) bool {
+	taken, err := s.periodRepo.IsSemesterTaken(period.AcademicYear, period.Semester, period.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return false
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "academic period " + period.Name + " already exists"})
+		return false
+	}
+
+	overlapping, err := s.periodRepo.FindOverlapping(period.StartDate, period.EndDate, period.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return false
+	}
+	if len(overlapping) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "date range overlaps academic period " + overlapping[0].Name})
+		return false
+	}
+	return true
+}
+
+// GetAcademicPeriods godoc
+// @Summary      Get Academic Periods
+// @Description  Semua periode akademik, terbaru lebih dulu.
+// @Tags         Academic Periods
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {array} model.AcademicPeriod
+// @Router       /academic-periods [get]
+func (s *academicPeriodService) GetAcademicPeriods(c *gin.Context) {
+	periods, err := s.periodRepo.FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": periods})
+}
+
+// GetActiveAcademicPeriod godoc
+// @Summary      Get Active Academic Period
+// @Tags         Academic Periods
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} model.AcademicPeriod
+// @Failure      404 {object} map[string]string
+// @Router       /academic-periods/active [get]
+func (s *academicPeriodService) GetActiveAcademicPeriod(c *gin.Context) {
+	period, err := s.periodRepo.FindActive()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if period == nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "no active academic period"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": period})
+}
+
+// GetAcademicPeriodByID godoc
+// @Summary      Get Academic Period by ID
+// @Tags         Academic Periods
+// @Security     BearerAuth
+// @Param        id path string true "Academic Period UUID"
+// @Success      200 {object} model.AcademicPeriod
+// @Failure      404 {object} map[string]string
+// @Router       /academic-periods/{id} [get]
+func (s *academicPeriodService) GetAcademicPeriodByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid academic period id format"})
+		return
+	}
+	period, err := s.periodRepo.FindByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": period})
+}
+
+// CreateAcademicPeriod godoc
+// @Summary      Create Academic Period
+// @Description  Prestasi yang tanggal acuannya jatuh di rentang periode langsung diatribusikan. is_active=true menonaktifkan periode lain.
+// @Tags         Academic Periods
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.AcademicPeriodRequest true "Academic Period"
+// @Success      201 {object} model.AcademicPeriod
+// @Failure      409 {object} map[string]string
+// @Router       /academic-periods [post]
+func (s *academicPeriodService) CreateAcademicPeriod(c *gin.Context) {
+	var req model.AcademicPeriodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	period := &model.AcademicPeriod{IsActive: req.IsActive}
+	if err := req.ToPeriod(period); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if !s.checkPeriod(c, period) {
+		return
+	}
+	if err := s.periodRepo.Create(period, s.cfg.AttributeBy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": period})
+}
+
+// UpdateAcademicPeriod godoc
+// @Summary      Update Academic Period
+// @Description  Perubahan rentang tanggal mengatribusi ulang prestasi. is_active=false tidak menonaktifkan periode yang sedang aktif; aktifkan periode lain untuk menggantinya.
+// @Tags         Academic Periods
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Academic Period UUID"
+// @Param        request body model.AcademicPeriodRequest true "Academic Period"
+// @Success      200 {object} model.AcademicPeriod
+// @Failure      409 {object} map[string]string
+// @Router       /academic-periods/{id} [put]
+func (s *academicPeriodService) UpdateAcademicPeriod(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid academic period id format"})
+		return
+	}
+	var req model.AcademicPeriodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	period, err := s.periodRepo.FindByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if err := req.ToPeriod(period); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	period.IsActive = period.IsActive || req.IsActive
+	if !s.checkPeriod(c, period) {
+		return
+	}
+	if err := s.periodRepo.Update(period, s.cfg.AttributeBy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": period})
+}
+
+// DeleteAcademicPeriod godoc
+// @Summary      Delete Academic Period
+// @Description  Prestasi di periode ini dilepas (tanpa periode). Periode aktif tidak bisa dihapus.
+// @Tags         Academic Periods
+// @Security     BearerAuth
+// @Param        id path string true "Academic Period UUID"
+// @Success      200 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /academic-periods/{id} [delete]
+func (s *academicPeriodService) DeleteAcademicPeriod(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid academic period id format"})
+		return
+	}
+	period, err := s.periodRepo.FindByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if period.IsActive {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "cannot delete the active academic period; activate another period first"})
+		return
+	}
+	if err := s.periodRepo.Delete(id, s.cfg.AttributeBy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "academic period deleted"})
+}
+
+// ActivateAcademicPeriod godoc
+// @Summary      Activate Academic Period
+// @Description  Menjadikan periode ini aktif dan menonaktifkan periode lain.
+// @Tags         Academic Periods
+// @Security     BearerAuth
+// @Param        id path string true "Academic Period UUID"
+// @Success      200 {object} model.AcademicPeriod
+// @Failure      404 {object} map[string]string
+// @Router       /academic-periods/{id}/activate [post]
+func (s *academicPeriodService) ActivateAcademicPeriod(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid academic period id format"})
+		return
+	}
+	period, err := s.periodRepo.FindByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if err := s.periodRepo.Activate(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	period.IsActive = true
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": period})
+}
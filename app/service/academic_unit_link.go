@@ -0,0 +1,73 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// academicUnitLinker menautkan profil mahasiswa/dosen ke master data akademik.
+// ID eksplisit (study_program_id/department_id) diutamakan; bila kosong, teks bebas dicocokkan
+// lewat nama, kode, atau alias. Teks yang tidak cocok tetap disimpan dengan FK kosong.
+type academicUnitLinker struct {
+	unitRepo repository.AcademicUnitRepository
+}
+
+// linkStudent menulis response error dan mengembalikan false bila study_program_id tidak valid
+func (l academicUnitLinker) linkStudent(c *gin.Context, student *model.Student, studyProgramID string) bool {
+	student.StudyProgram = nil
+	if studyProgramID == "" {
+		matcher, err := l.unitRepo.LoadMatcher()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return false
+		}
+		matcher.LinkStudent(student)
+		return true
+	}
+
+	id, err := uuid.Parse(studyProgramID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid study program id format"})
+		return false
+	}
+	program, err := l.unitRepo.FindStudyProgramByID(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return false
+	}
+	student.StudyProgramID = &program.ID
+	student.ProgramStudy = program.Name
+	return true
+}
+
+// linkLecturer menulis response error dan mengembalikan false bila department_id tidak valid
+func (l academicUnitLinker) linkLecturer(c *gin.Context, lecturer *model.Lecturer, departmentID string) bool {
+	lecturer.DepartmentUnit = nil
+	if departmentID == "" {
+		matcher, err := l.unitRepo.LoadMatcher()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return false
+		}
+		matcher.LinkLecturer(lecturer)
+		return true
+	}
+
+	id, err := uuid.Parse(departmentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid department id format"})
+		return false
+	}
+	department, err := l.unitRepo.FindDepartmentByID(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return false
+	}
+	lecturer.DepartmentID = &department.ID
+	lecturer.Department = department.Name
+	return true
+}
@@ -0,0 +1,668 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AcademicUnitService interface {
+	GetFaculties(c *gin.Context)
+	GetFacultyByID(c *gin.Context)
+	CreateFaculty(c *gin.Context)
+	UpdateFaculty(c *gin.Context)
+	DeleteFaculty(c *gin.Context)
+
+	GetDepartments(c *gin.Context)
+	GetDepartmentByID(c *gin.Context)
+	CreateDepartment(c *gin.Context)
+	UpdateDepartment(c *gin.Context)
+	DeleteDepartment(c *gin.Context)
+
+	GetStudyPrograms(c *gin.Context)
+	GetStudyProgramByID(c *gin.Context)
+	CreateStudyProgram(c *gin.Context)
+	UpdateStudyProgram(c *gin.Context)
+	DeleteStudyProgram(c *gin.Context)
+
+	GetAliases(c *gin.Context)
+	CreateAlias(c *gin.Context)
+	DeleteAlias(c *gin.Context)
+
+	GetMappingReport(c *gin.Context)
+	ApplyMapping(c *gin.Context)
+}
+
+type academicUnitService struct {
+	unitRepo repository.AcademicUnitRepository
+}
+
+func NewAcademicUnitService(unitRepo repository.AcademicUnitRepository) AcademicUnitService {
+	return &academicUnitService{unitRepo: unitRepo}
+}
+
+// normalizeUnitCode: kode unit disimpan huruf besar tanpa spasi di tepi
+func normalizeUnitCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// checkUnitCode menulis 409 bila kode sudah dipakai unit lain sejenis
+func (s *academicUnitService) checkUnitCode(c *gin.Context, unitType, code string, excludeID uuid.UUID) bool {
+	taken, err := s.unitRepo.IsCodeTaken(unitType, code, excludeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return false
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "code already used"})
+		return false
+	}
+	return true
+}
+
+func respondUnitDeleteError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrAcademicUnitInUse) {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "unit still has child units, students or lecturers; reassign them first"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+}
+
+// optionalUUIDQuery membaca query parameter UUID opsional
+func optionalUUIDQuery(c *gin.Context, name string) (*uuid.UUID, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid " + name + " format"})
+		return nil, false
+	}
+	return &id, true
+}
+
+// =================================================================
+// FACULTIES
+// =================================================================
+
+// GetFaculties godoc
+// @Summary      Get All Faculties
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {array} model.Faculty
+// @Router       /faculties [get]
+func (s *academicUnitService) GetFaculties(c *gin.Context) {
+	faculties, err := s.unitRepo.FindAllFaculties()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": faculties})
+}
+
+// GetFacultyByID godoc
+// @Summary      Get Faculty by ID
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Param        id path string true "Faculty UUID"
+// @Success      200 {object} model.Faculty
+// @Failure      404 {object} map[string]string
+// @Router       /faculties/{id} [get]
+func (s *academicUnitService) GetFacultyByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid faculty id format"})
+		return
+	}
+	faculty, err := s.unitRepo.FindFacultyByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": faculty})
+}
+
+// CreateFaculty godoc
+// @Summary      Create Faculty
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.FacultyRequest true "Faculty"
+// @Success      201 {object} model.Faculty
+// @Failure      409 {object} map[string]string
+// @Router       /faculties [post]
+func (s *academicUnitService) CreateFaculty(c *gin.Context) {
+	var req model.FacultyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	faculty := &model.Faculty{Code: normalizeUnitCode(req.Code), Name: strings.TrimSpace(req.Name)}
+	if !s.checkUnitCode(c, "faculty", faculty.Code, uuid.Nil) {
+		return
+	}
+	if err := s.unitRepo.CreateFaculty(faculty); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": faculty})
+}
+
+// UpdateFaculty godoc
+// @Summary      Update Faculty
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Faculty UUID"
+// @Param        request body model.FacultyRequest true "Faculty"
+// @Success      200 {object} model.Faculty
+// @Router       /faculties/{id} [put]
+func (s *academicUnitService) UpdateFaculty(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid faculty id format"})
+		return
+	}
+	var req model.FacultyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	faculty, err := s.unitRepo.FindFacultyByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	faculty.Code = normalizeUnitCode(req.Code)
+	faculty.Name = strings.TrimSpace(req.Name)
+	if !s.checkUnitCode(c, "faculty", faculty.Code, faculty.ID) {
+		return
+	}
+	if err := s.unitRepo.UpdateFaculty(faculty); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": faculty})
+}
+
+// DeleteFaculty godoc
+// @Summary      Delete Faculty
+// @Description  Ditolak (409) selama fakultas masih punya departemen.
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Param        id path string true "Faculty UUID"
+// @Success      200 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /faculties/{id} [delete]
+func (s *academicUnitService) DeleteFaculty(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid faculty id format"})
+		return
+	}
+	if _, err := s.unitRepo.FindFacultyByID(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if err := s.unitRepo.DeleteFaculty(id); err != nil {
+		respondUnitDeleteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "faculty deleted"})
+}
+
+// =================================================================
+// DEPARTMENTS
+// =================================================================
+
+// GetDepartments godoc
+// @Summary      Get Departments
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Produce      json
+// @Param        faculty_id query string false "Filter fakultas"
+// @Success      200 {array} model.Department
+// @Router       /departments [get]
+func (s *academicUnitService) GetDepartments(c *gin.Context) {
+	facultyID, ok := optionalUUIDQuery(c, "faculty_id")
+	if !ok {
+		return
+	}
+	departments, err := s.unitRepo.FindDepartments(facultyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": departments})
+}
+
+// GetDepartmentByID godoc
+// @Summary      Get Department by ID
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Param        id path string true "Department UUID"
+// @Success      200 {object} model.Department
+// @Failure      404 {object} map[string]string
+// @Router       /departments/{id} [get]
+func (s *academicUnitService) GetDepartmentByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid department id format"})
+		return
+	}
+	department, err := s.unitRepo.FindDepartmentByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": department})
+}
+
+// bindDepartment memvalidasi request dan fakultas induknya
+func (s *academicUnitService) bindDepartment(c *gin.Context, department *model.Department) bool {
+	var req model.DepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return false
+	}
+	facultyID, err := uuid.Parse(req.FacultyID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid faculty id format"})
+		return false
+	}
+	if _, err := s.unitRepo.FindFacultyByID(facultyID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return false
+	}
+
+	department.FacultyID = facultyID
+	department.Faculty = nil
+	department.Code = normalizeUnitCode(req.Code)
+	department.Name = strings.TrimSpace(req.Name)
+	return s.checkUnitCode(c, model.UnitTypeDepartment, department.Code, department.ID)
+}
+
+// CreateDepartment godoc
+// @Summary      Create Department
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.DepartmentRequest true "Department"
+// @Success      201 {object} model.Department
+// @Failure      409 {object} map[string]string
+// @Router       /departments [post]
+func (s *academicUnitService) CreateDepartment(c *gin.Context) {
+	department := &model.Department{}
+	if !s.bindDepartment(c, department) {
+		return
+	}
+	if err := s.unitRepo.CreateDepartment(department); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": department})
+}
+
+// UpdateDepartment godoc
+// @Summary      Update Department
+// @Description  Nama baru ikut diterapkan ke kolom department semua dosen di departemen ini.
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Department UUID"
+// @Param        request body model.DepartmentRequest true "Department"
+// @Success      200 {object} model.Department
+// @Router       /departments/{id} [put]
+func (s *academicUnitService) UpdateDepartment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid department id format"})
+		return
+	}
+	department, err := s.unitRepo.FindDepartmentByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if !s.bindDepartment(c, department) {
+		return
+	}
+	if err := s.unitRepo.UpdateDepartment(department); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": department})
+}
+
+// DeleteDepartment godoc
+// @Summary      Delete Department
+// @Description  Ditolak (409) selama departemen masih punya program studi atau dosen.
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Param        id path string true "Department UUID"
+// @Success      200 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /departments/{id} [delete]
+func (s *academicUnitService) DeleteDepartment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid department id format"})
+		return
+	}
+	if _, err := s.unitRepo.FindDepartmentByID(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if err := s.unitRepo.DeleteDepartment(id); err != nil {
+		respondUnitDeleteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "department deleted"})
+}
+
+// =================================================================
+// STUDY PROGRAMS
+// =================================================================
+
+// GetStudyPrograms godoc
+// @Summary      Get Study Programs
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Produce      json
+// @Param        department_id query string false "Filter departemen"
+// @Success      200 {array} model.StudyProgram
+// @Router       /study-programs [get]
+func (s *academicUnitService) GetStudyPrograms(c *gin.Context) {
+	departmentID, ok := optionalUUIDQuery(c, "department_id")
+	if !ok {
+		return
+	}
+	programs, err := s.unitRepo.FindStudyPrograms(departmentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": programs})
+}
+
+// GetStudyProgramByID godoc
+// @Summary      Get Study Program by ID
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Param        id path string true "Study Program UUID"
+// @Success      200 {object} model.StudyProgram
+// @Failure      404 {object} map[string]string
+// @Router       /study-programs/{id} [get]
+func (s *academicUnitService) GetStudyProgramByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid study program id format"})
+		return
+	}
+	program, err := s.unitRepo.FindStudyProgramByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": program})
+}
+
+// bindStudyProgram memvalidasi request dan departemen induknya
+func (s *academicUnitService) bindStudyProgram(c *gin.Context, program *model.StudyProgram) bool {
+	var req model.StudyProgramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return false
+	}
+	departmentID, err := uuid.Parse(req.DepartmentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid department id format"})
+		return false
+	}
+	if _, err := s.unitRepo.FindDepartmentByID(departmentID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return false
+	}
+
+	program.DepartmentID = departmentID
+	program.Department = nil
+	program.Code = normalizeUnitCode(req.Code)
+	program.Name = strings.TrimSpace(req.Name)
+	program.Degree = strings.TrimSpace(req.Degree)
+	return s.checkUnitCode(c, model.UnitTypeStudyProgram, program.Code, program.ID)
+}
+
+// CreateStudyProgram godoc
+// @Summary      Create Study Program
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.StudyProgramRequest true "Study Program"
+// @Success      201 {object} model.StudyProgram
+// @Failure      409 {object} map[string]string
+// @Router       /study-programs [post]
+func (s *academicUnitService) CreateStudyProgram(c *gin.Context) {
+	program := &model.StudyProgram{}
+	if !s.bindStudyProgram(c, program) {
+		return
+	}
+	if err := s.unitRepo.CreateStudyProgram(program); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": program})
+}
+
+// UpdateStudyProgram godoc
+// @Summary      Update Study Program
+// @Description  Nama baru ikut diterapkan ke kolom program_study semua mahasiswa di program studi ini.
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Study Program UUID"
+// @Param        request body model.StudyProgramRequest true "Study Program"
+// @Success      200 {object} model.StudyProgram
+// @Router       /study-programs/{id} [put]
+func (s *academicUnitService) UpdateStudyProgram(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid study program id format"})
+		return
+	}
+	program, err := s.unitRepo.FindStudyProgramByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if !s.bindStudyProgram(c, program) {
+		return
+	}
+	if err := s.unitRepo.UpdateStudyProgram(program); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": program})
+}
+
+// DeleteStudyProgram godoc
+// @Summary      Delete Study Program
+// @Description  Ditolak (409) selama masih ada mahasiswa di program studi ini.
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Param        id path string true "Study Program UUID"
+// @Success      200 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /study-programs/{id} [delete]
+func (s *academicUnitService) DeleteStudyProgram(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid study program id format"})
+		return
+	}
+	if _, err := s.unitRepo.FindStudyProgramByID(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if err := s.unitRepo.DeleteStudyProgram(id); err != nil {
+		respondUnitDeleteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "study program deleted"})
+}
+
+// =================================================================
+// ALIAS & PEMETAAN TEKS BEBAS
+// =================================================================
+
+// GetAliases godoc
+// @Summary      Get Academic Unit Aliases
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Produce      json
+// @Param        unit_type query string false "department | study_program"
+// @Success      200 {array} model.AcademicUnitAlias
+// @Router       /academic-units/aliases [get]
+func (s *academicUnitService) GetAliases(c *gin.Context) {
+	aliases, err := s.unitRepo.FindAliases(c.Query("unit_type"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": aliases})
+}
+
+// CreateAlias godoc
+// @Summary      Create Academic Unit Alias
+// @Description  Mendaftarkan ejaan lain departemen/program studi (mis. "T. Informatika") untuk pemetaan teks bebas.
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.AcademicUnitAliasRequest true "Alias"
+// @Success      201 {object} model.AcademicUnitAlias
+// @Failure      409 {object} map[string]string
+// @Router       /academic-units/aliases [post]
+func (s *academicUnitService) CreateAlias(c *gin.Context) {
+	var req model.AcademicUnitAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	unitID, err := uuid.Parse(req.UnitID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid unit id format"})
+		return
+	}
+	if req.UnitType == model.UnitTypeDepartment {
+		_, err = s.unitRepo.FindDepartmentByID(unitID)
+	} else {
+		_, err = s.unitRepo.FindStudyProgramByID(unitID)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if model.NormalizeUnitName(req.Alias) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "alias must contain letters or digits"})
+		return
+	}
+
+	matcher, err := s.unitRepo.LoadMatcher()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	// Alias tidak boleh menunjuk unit lain yang sudah cocok dengan ejaan yang sama
+	var existingID *uuid.UUID
+	if req.UnitType == model.UnitTypeDepartment {
+		if department, _ := matcher.Department(req.Alias); department != nil {
+			existingID = &department.ID
+		}
+	} else if program, _ := matcher.StudyProgram(req.Alias); program != nil {
+		existingID = &program.ID
+	}
+	if existingID != nil && *existingID == unitID {
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "alias already matches this unit"})
+		return
+	}
+	if existingID != nil {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "alias already matches another unit", "data": gin.H{"unit_id": existingID}})
+		return
+	}
+
+	alias := &model.AcademicUnitAlias{UnitType: req.UnitType, UnitID: unitID, Alias: strings.TrimSpace(req.Alias)}
+	if err := s.unitRepo.CreateAlias(alias); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": alias})
+}
+
+// DeleteAlias godoc
+// @Summary      Delete Academic Unit Alias
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Param        id path string true "Alias UUID"
+// @Success      200 {object} map[string]string
+// @Router       /academic-units/aliases/{id} [delete]
+func (s *academicUnitService) DeleteAlias(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid alias id format"})
+		return
+	}
+	if err := s.unitRepo.DeleteAlias(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "alias deleted"})
+}
+
+// GetMappingReport godoc
+// @Summary      Preview Free-Text Mapping
+// @Description  Pratinjau pemetaan students.program_study dan lecturers.department yang belum punya FK ke master data,
+// @Description  termasuk daftar nilai yang belum cocok (tambahkan alias lalu jalankan POST untuk memetakannya).
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} model.AcademicUnitMappingReport
+// @Router       /academic-units/mapping [get]
+func (s *academicUnitService) GetMappingReport(c *gin.Context) {
+	s.mapFreeText(c, false)
+}
+
+// ApplyMapping godoc
+// @Summary      Apply Free-Text Mapping
+// @Description  Mengisi FK master data untuk nilai teks bebas yang cocok dan menyeragamkan teksnya ke nama resmi.
+// @Tags         Academic Units
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} model.AcademicUnitMappingReport
+// @Router       /academic-units/mapping [post]
+func (s *academicUnitService) ApplyMapping(c *gin.Context) {
+	s.mapFreeText(c, true)
+}
+
+func (s *academicUnitService) mapFreeText(c *gin.Context, apply bool) {
+	report, err := s.unitRepo.MapFreeText(apply)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": report})
+}
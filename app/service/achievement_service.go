@@ -14,7 +14,9 @@ import (
 	"time"
 
 	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/policy"
 	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/config"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -51,17 +53,29 @@ type achievementService struct {
 	achievementRepo repository.AchievementRepository
 	studentRepo     repository.StudentRepository
 	lecturerRepo    repository.LecturerRepository
+	enforcer        policy.Enforcer
+	periodRepo      repository.AcademicPeriodRepository
+	periodCfg       config.AcademicPeriodConfig
+	studentCfg      config.StudentConfig
 }
 
 func NewAchievementService(
 	achievementRepo repository.AchievementRepository,
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
+	enforcer policy.Enforcer,
+	periodRepo repository.AcademicPeriodRepository,
+	periodCfg config.AcademicPeriodConfig,
+	studentCfg config.StudentConfig,
 ) AchievementService {
 	return &achievementService{
 		achievementRepo: achievementRepo,
 		studentRepo:     studentRepo,
 		lecturerRepo:    lecturerRepo,
+		enforcer:        enforcer,
+		periodRepo:      periodRepo,
+		periodCfg:       periodCfg,
+		studentCfg:      studentCfg,
 	}
 }
 
@@ -105,6 +119,85 @@ func (s *achievementService) getStudentByUserID(c *gin.Context) (*model.Student,
 	return student, nil
 }
 
+// authorizeAchievement mengevaluasi policy terhadap mahasiswa pemilik prestasi.
+// Bila ditolak, response 403 sudah ditulis dan pemanggil cukup return.
+func (s *achievementService) authorizeAchievement(c *gin.Context, pgRef *model.AchievementReference, action policy.Action) (*policy.Subject, bool) {
+	sub, _, ok := s.authorizeAchievementOwner(c, pgRef, action)
+	return sub, ok
+}
+
+// authorizeAchievementOwner sama dengan authorizeAchievement, tetapi juga mengembalikan resource yang
+// dievaluasi (dibutuhkan verify/reject untuk mencatat dosen wali yang diwakili delegate).
+func (s *achievementService) authorizeAchievementOwner(c *gin.Context, pgRef *model.AchievementReference, action policy.Action) (*policy.Subject, policy.Resource, bool) {
+	owner, err := s.studentRepo.FindByID(pgRef.StudentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to load achievement owner: " + err.Error()})
+		return nil, policy.Resource{}, false
+	}
+
+	res := policy.AchievementResource(owner, pgRef)
+	sub, allowed, err := s.enforcer.Authorize(c, action, res)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return nil, policy.Resource{}, false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Forbidden: you are not allowed to access this achievement"})
+		return nil, policy.Resource{}, false
+	}
+	if action == policy.ActionModifyAchievement && !s.ensurePortfolioWritable(c, owner) {
+		return nil, policy.Resource{}, false
+	}
+	return sub, res, true
+}
+
+// ensurePortfolioWritable menolak (403) perubahan dan pengajuan prestasi oleh mahasiswa non-aktif
+// setelah masa tenggang. Membaca tetap diizinkan, sehingga alumni masih bisa melihat portofolionya.
+func (s *achievementService) ensurePortfolioWritable(c *gin.Context, student *model.Student) bool {
+	grace := time.Duration(s.studentCfg.SubmissionGraceDays) * 24 * time.Hour
+	if student.PortfolioWritable(time.Now(), grace) {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": fmt.Sprintf("Student status is %s: achievements are read-only", student.CurrentStatus())})
+	return false
+}
+
+// recordValidator mengisi VerifiedBy dengan user yang bertindak dan, bila ia bertindak
+// sebagai delegate, VerifiedOnBehalfOf dengan user dosen wali (principal) pemilik wewenang.
+// Principal diambil dari resource, sehingga pengajuan yang di-pin ke dosen wali lama tercatat benar.
+func (s *achievementService) recordValidator(pgRef *model.AchievementReference, sub *policy.Subject, res policy.Resource) {
+	validatorID := sub.UserID
+	pgRef.VerifiedBy = &validatorID
+	pgRef.VerifiedOnBehalfOf = nil
+
+	if policy.ActsAsDelegate(sub, res) {
+		if principal, err := s.lecturerRepo.FindByID(*res.AdvisorID); err == nil {
+			principalUserID := principal.UserID
+			pgRef.VerifiedOnBehalfOf = &principalUserID
+		}
+	}
+}
+
+// attributePeriod menetapkan periode akademik prestasi dari tanggal acuannya (ACADEMIC_PERIOD_ATTRIBUTION).
+// Prestasi tanpa tanggal acuan atau di luar semua periode dibiarkan tanpa periode.
+func (s *achievementService) attributePeriod(pgRef *model.AchievementReference) error {
+	pgRef.AcademicPeriodID = nil
+	pgRef.AcademicPeriod = nil
+
+	date := pgRef.PeriodAttributionDate(s.periodCfg.AttributeBy)
+	if date == nil {
+		return nil
+	}
+	period, err := s.periodRepo.FindByDate(*date)
+	if err != nil {
+		return err
+	}
+	if period != nil {
+		pgRef.AcademicPeriodID = &period.ID
+	}
+	return nil
+}
+
 // Helper untuk menggabungkan data PGSQL dan MongoDB
 func (s *achievementService) mergeAchievement(ctx context.Context, pgRef *model.AchievementReference) (*model.AchievementDetailResponse, error) {
 	mongoAch, err := s.achievementRepo.FindMongoByID(ctx, pgRef.MongoAchievementID)
@@ -123,7 +216,9 @@ func (s *achievementService) mergeAchievement(ctx context.Context, pgRef *model.
 		SubmittedAt:   pgRef.SubmittedAt,
 		VerifiedAt:    pgRef.VerifiedAt,
 		VerifiedBy:    pgRef.VerifiedBy,
+		VerifiedOnBehalfOf: pgRef.VerifiedOnBehalfOf,
 		RejectionNote: pgRef.RejectionNote,
+		AcademicPeriodID: pgRef.AcademicPeriodID,
 
 		// MongoDB (Content)
 		MongoAchievementID: pgRef.MongoAchievementID,
@@ -162,6 +257,9 @@ func (s *achievementService) CreateAchievement(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Only students can create achievements: " + err.Error()})
 		return
 	}
+	if !s.ensurePortfolioWritable(c, student) {
+		return
+	}
 
 	var req model.AchievementCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -182,7 +280,18 @@ func (s *achievementService) CreateAchievement(c *gin.Context) {
 		return
 	}
 
-	// 1B. SIAPKAN DOKUMEN MONGO (Data Konten)
+	// 1B. TENTUKAN PERIODE AKADEMIK (sebelum menulis apa pun)
+	pgReference := &model.AchievementReference{
+		StudentID: student.ID,
+		Status:    model.StatusDraft,
+		EventDate: req.Details.EventDate,
+	}
+	if err := s.attributePeriod(pgReference); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to resolve academic period: " + err.Error()})
+		return
+	}
+
+	// 1C. SIAPKAN DOKUMEN MONGO (Data Konten)
 	mongoAchievement := &model.Achievement{
 		StudentUUID:     student.ID,
 		AchievementType: req.AchievementType,
@@ -203,14 +312,10 @@ func (s *achievementService) CreateAchievement(c *gin.Context) {
 		return
 	}
 
-	// 3. SIAPKAN REFERENSI PGSQL (Workflow)
-	pgReference := &model.AchievementReference{
-		StudentID:          student.ID,
-		MongoAchievementID: mongoObjectID.Hex(),
-		Status:             model.StatusDraft,
-		CreatedAt:          time.Now(),
-		UpdatedAt:          time.Now(),
-	}
+	// 3. LENGKAPI REFERENSI PGSQL (Workflow)
+	pgReference.MongoAchievementID = mongoObjectID.Hex()
+	pgReference.CreatedAt = time.Now()
+	pgReference.UpdatedAt = time.Now()
 
 	// 4. INSERT KE POSTGRESQL
 	if err := s.achievementRepo.CreateReference(pgReference); err != nil {
@@ -226,18 +331,20 @@ func (s *achievementService) CreateAchievement(c *gin.Context) {
 }
 
 // @Summary List Achievements (Filtered by Role)
-// @Description Mahasiswa: List achievements sendiri (read_own). Dosen/Admin: List semua (read_list). (Saat ini mengembalikan 501)
+// @Description Mahasiswa: List achievements sendiri (read_own). Dosen/Admin: List prestasi yang boleh dibaca sesuai policy (read_list).
 // @Tags Achievements
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
+// @Param period_id query string false "UUID periode akademik atau 'active'"
+// @Param status query string false "Filter status (draft, submitted, verified, rejected)"
 // @Success 200 {object} object{status=string,data=[]model.AchievementDetailResponse}
+// @Failure 400 {object} object{status=string,message=string}
 // @Failure 403 {object} object{status=string,message=string}
+// @Failure 404 {object} object{status=string,message=string}
 // @Failure 500 {object} object{status=string,message=string}
 // @Router /achievements [get]
 func (s *achievementService) GetAchievementsList(c *gin.Context) {
-	// Implementasi List Hibrid (kompleks, perlu iterasi dan merge)
-
 	permissionsAny, exists := c.Get("permissions")
 	if !exists {
 		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Permissions not found in context"})
@@ -255,36 +362,72 @@ func (s *achievementService) GetAchievementsList(c *gin.Context) {
 			hasReadOwn = true
 		}
 	}
+	if !hasReadList && !hasReadOwn {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Access denied: insufficient permissions (need read_list or read_own)"})
+		return
+	}
+
+	status := model.AchievementStatus(strings.ToLower(strings.TrimSpace(c.Query("status"))))
+	switch status {
+	case "", model.StatusDraft, model.StatusSubmitted, model.StatusVerified, model.StatusRejected:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid status filter"})
+		return
+	}
 
-	var err error
+	period, ok := periodQuery(c, s.periodRepo)
+	if !ok {
+		return
+	}
 
+	var refs []model.AchievementReference
 	if hasReadList {
-		// Implementasi s.achievementRepo.FindAllReferences() diperlukan
-		err = errors.New("FindAllReferences not implemented in hybrid mode")
-	} else if hasReadOwn {
-		student, studentErr := s.getStudentByUserID(c)
-		if studentErr != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to retrieve student profile: " + studentErr.Error()})
+		sub, err := s.enforcer.Subject(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
 			return
 		}
-
-		// Implementasi s.achievementRepo.FindReferencesByStudentID(student.ID) diperlukan
-		if student.ID == uuid.Nil {
-			err = errors.New("student ID is nil, cannot fetch own achievements")
+		all, err := s.achievementRepo.FindReferences(status, periodID(period))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch achievements: " + err.Error()})
+			return
+		}
+		// read_list tidak berarti semua: dosen wali hanya melihat prestasi yang boleh dibacanya
+		for i := range all {
+			ref := &all[i]
+			if ref.Student != nil && policy.Allowed(sub, policy.ActionReadAchievement, policy.AchievementResource(ref.Student, ref)) {
+				refs = append(refs, *ref)
+			}
 		}
-		err = errors.New("FindReferencesByStudentID not implemented in hybrid mode")
 	} else {
-		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Access denied: insufficient permissions (need read_list or read_own)"})
-		return
+		student, err := s.getStudentByUserID(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to retrieve student profile: " + err.Error()})
+			return
+		}
+		own, err := s.achievementRepo.FindReferencesByStudentID(student.ID, periodID(period))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch achievements: " + err.Error()})
+			return
+		}
+		for _, ref := range own {
+			if status == "" || ref.Status == status {
+				refs = append(refs, ref)
+			}
+		}
 	}
 
-	if err != nil && err.Error() != "FindReferencesByStudentID not implemented in hybrid mode" && err.Error() != "FindAllReferences not implemented in hybrid mode" {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch achievements: " + err.Error()})
-		return
+	achievements := make([]model.AchievementDetailResponse, 0, len(refs))
+	for i := range refs {
+		merged, err := s.mergeAchievement(c.Request.Context(), &refs[i])
+		if err != nil {
+			// Konten MongoDB hilang/rusak: lewati agar satu data tidak menggagalkan seluruh daftar
+			continue
+		}
+		achievements = append(achievements, *merged)
 	}
 
-	// Mengembalikan 501 karena implementasi list hibrid kompleks dan belum selesai.
-	c.JSON(http.StatusNotImplemented, gin.H{"status": "info", "message": "Listing achievements not fully implemented yet in hybrid mode. Repository functions need implementation."})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": achievements})
 }
 
 // @Summary Get Achievement Detail by ID
@@ -317,6 +460,10 @@ func (s *achievementService) GetAchievementByID(c *gin.Context) {
 		return
 	}
 
+	if _, ok := s.authorizeAchievement(c, pgRef, policy.ActionReadAchievement); !ok {
+		return
+	}
+
 	// 2. Gabungkan data
 	mergedAch, err := s.mergeAchievement(c.Request.Context(), pgRef)
 	if err != nil {
@@ -348,12 +495,6 @@ func (s *achievementService) UpdateAchievement(c *gin.Context) {
 		return
 	}
 
-	student, err := s.getStudentByUserID(c)
-	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Only students can update their achievements: " + err.Error()})
-		return
-	}
-
 	// 1. Ambil Referensi PGSQL
 	pgRef, err := s.achievementRepo.FindReferenceByID(achID)
 	if err != nil {
@@ -365,8 +506,7 @@ func (s *achievementService) UpdateAchievement(c *gin.Context) {
 		return
 	}
 
-	if pgRef.StudentID != student.ID {
-		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Forbidden: Not the owner of this achievement"})
+	if _, ok := s.authorizeAchievement(c, pgRef, policy.ActionModifyAchievement); !ok {
 		return
 	}
 
@@ -406,6 +546,13 @@ func (s *achievementService) UpdateAchievement(c *gin.Context) {
 			return
 		}
 		updates["details"] = detailsMap
+
+		// Details diganti utuh, begitu pula salinan tanggal event dan periodenya
+		pgRef.EventDate = req.Details.EventDate
+		if err := s.attributePeriod(pgRef); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to resolve academic period: " + err.Error()})
+			return
+		}
 	}
 
 	if req.Tags != nil {
@@ -460,12 +607,6 @@ func (s *achievementService) DeleteAchievement(c *gin.Context) {
 		return
 	}
 
-	student, err := s.getStudentByUserID(c)
-	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Only students can delete their achievements: " + err.Error()})
-		return
-	}
-
 	// 1. Ambil Referensi PGSQL
 	pgRef, err := s.achievementRepo.FindReferenceByID(achID)
 	if err != nil {
@@ -477,8 +618,7 @@ func (s *achievementService) DeleteAchievement(c *gin.Context) {
 		return
 	}
 
-	if pgRef.StudentID != student.ID {
-		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Forbidden: Not the owner of this achievement"})
+	if _, ok := s.authorizeAchievement(c, pgRef, policy.ActionModifyAchievement); !ok {
 		return
 	}
 
@@ -522,12 +662,6 @@ func (s *achievementService) SubmitAchievement(c *gin.Context) {
 		return
 	}
 
-	student, err := s.getStudentByUserID(c)
-	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Forbidden: Only students can submit: " + err.Error()})
-		return
-	}
-
 	// 1. Ambil Referensi PGSQL
 	pgRef, err := s.achievementRepo.FindReferenceByID(achID)
 	if err != nil {
@@ -539,8 +673,7 @@ func (s *achievementService) SubmitAchievement(c *gin.Context) {
 		return
 	}
 
-	if pgRef.StudentID != student.ID {
-		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Forbidden: Not the owner"})
+	if _, ok := s.authorizeAchievement(c, pgRef, policy.ActionModifyAchievement); !ok {
 		return
 	}
 
@@ -565,7 +698,13 @@ func (s *achievementService) SubmitAchievement(c *gin.Context) {
 	submitTime := time.Now()
 	pgRef.Status = model.StatusSubmitted
 	pgRef.SubmittedAt = &submitTime
+	// Pengajuan baru selalu ke dosen wali saat ini
+	pgRef.PinnedAdvisorID = nil
 	pgRef.UpdatedAt = time.Now()
+	if err := s.attributePeriod(pgRef); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to resolve academic period: " + err.Error()})
+		return
+	}
 
 	if err := s.achievementRepo.UpdateReference(pgRef); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to submit achievement (PGSQL): " + err.Error()})
@@ -576,7 +715,7 @@ func (s *achievementService) SubmitAchievement(c *gin.Context) {
 }
 
 // @Summary Verify Achievement (Approve)
-// @Description Dosen Wali/Admin menyetujui prestasi (Status SUBMITTED -> VERIFIED).
+// @Description Dosen Wali, delegate aktifnya, atau Admin menyetujui prestasi (Status SUBMITTED -> VERIFIED).
 // @Tags Achievements
 // @Accept json
 // @Produce json
@@ -594,15 +733,6 @@ func (s *achievementService) VerifyAchievement(c *gin.Context) {
 		return
 	}
 
-	userIDStr := c.GetString("userID")
-	userUUID, _ := uuid.Parse(userIDStr)
-
-	lecturer, err := s.lecturerRepo.FindByUserID(userUUID)
-	if err != nil || lecturer == nil {
-		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Only authorized validators (Lecturers/Admins) can verify"})
-		return
-	}
-
 	// 1. Ambil Referensi PGSQL
 	pgRef, err := s.achievementRepo.FindReferenceByID(achID)
 	if err != nil {
@@ -614,6 +744,12 @@ func (s *achievementService) VerifyAchievement(c *gin.Context) {
 		return
 	}
 
+	// Hanya dosen wali pemilik prestasi, delegate aktifnya, atau admin
+	sub, res, ok := s.authorizeAchievementOwner(c, pgRef, policy.ActionVerifyAchievement)
+	if !ok {
+		return
+	}
+
 	// STATUS CHECK
 	if pgRef.Status != model.StatusSubmitted {
 		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Achievement is not in SUBMITTED status"})
@@ -623,7 +759,7 @@ func (s *achievementService) VerifyAchievement(c *gin.Context) {
 	// 2. Update Status, Validator ID, dan Waktu di PGSQL (Pointer Manual)
 	verifyTime := time.Now()
 	pgRef.Status = model.StatusVerified
-	pgRef.VerifiedBy = &lecturer.ID
+	s.recordValidator(pgRef, sub, res)
 	pgRef.VerifiedAt = &verifyTime
 	pgRef.UpdatedAt = time.Now()
 
@@ -638,7 +774,7 @@ func (s *achievementService) VerifyAchievement(c *gin.Context) {
 }
 
 // @Summary Reject Achievement
-// @Description Dosen Wali/Admin menolak prestasi (Status SUBMITTED -> REJECTED).
+// @Description Dosen Wali, delegate aktifnya, atau Admin menolak prestasi (Status SUBMITTED -> REJECTED).
 // @Tags Achievements
 // @Accept json
 // @Produce json
@@ -657,15 +793,6 @@ func (s *achievementService) RejectAchievement(c *gin.Context) {
 		return
 	}
 
-	userIDStr := c.GetString("userID")
-	userUUID, _ := uuid.Parse(userIDStr)
-
-	lecturer, err := s.lecturerRepo.FindByUserID(userUUID)
-	if err != nil || lecturer == nil {
-		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Only authorized validators (Lecturers/Admins) can reject"})
-		return
-	}
-
 	var req model.AchievementRejectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error()})
@@ -688,6 +815,12 @@ func (s *achievementService) RejectAchievement(c *gin.Context) {
 		return
 	}
 
+	// Hanya dosen wali pemilik prestasi, delegate aktifnya, atau admin
+	sub, res, ok := s.authorizeAchievementOwner(c, pgRef, policy.ActionVerifyAchievement)
+	if !ok {
+		return
+	}
+
 	// STATUS CHECK
 	if pgRef.Status != model.StatusSubmitted {
 		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Achievement is not in SUBMITTED status"})
@@ -697,7 +830,7 @@ func (s *achievementService) RejectAchievement(c *gin.Context) {
 	// 2. Update Status, Validator ID, dan Alasan Penolakan di PGSQL (Pointer Manual)
 	rejectReason := req.RejectionNote
 	pgRef.Status = model.StatusRejected
-	pgRef.VerifiedBy = &lecturer.ID
+	s.recordValidator(pgRef, sub, res)
 	pgRef.RejectionNote = &rejectReason
 	pgRef.UpdatedAt = time.Now()
 
@@ -719,8 +852,31 @@ func (s *achievementService) RejectAchievement(c *gin.Context) {
 // @Param Authorization header string true "Bearer token"
 // @Param id path string true "Achievement ID (UUID)"
 // @Success 501 {object} object{status=string,message=string} "Not Implemented"
+// @Failure 403 {object} object{status=string,message=string}
+// @Failure 404 {object} object{status=string,message=string}
 // @Router /achievements/{id}/history [get]
 func (s *achievementService) GetAchievementHistory(c *gin.Context) {
+	achID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid achievement ID format"})
+		return
+	}
+
+	// Policy tetap dievaluasi walau belum diimplementasikan: keberadaan prestasi tidak boleh
+	// bocor ke user yang tidak berhak membacanya
+	pgRef, err := s.achievementRepo.FindReferenceByID(achID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch achievement reference: " + err.Error()})
+		return
+	}
+	if pgRef == nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Achievement not found"})
+		return
+	}
+	if _, ok := s.authorizeAchievement(c, pgRef, policy.ActionReadAchievement); !ok {
+		return
+	}
+
 	c.JSON(http.StatusNotImplemented, gin.H{"status": "info", "message": "Endpoint History Prestasi belum diimplementasikan. Membutuhkan model log/history terpisah."})
 }
 
@@ -757,14 +913,7 @@ func (s *achievementService) UploadAttachment(c *gin.Context) {
 	}
 
 	// 2. Lakukan Ownership dan Status Check
-	student, err := s.getStudentByUserID(c)
-	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Forbidden: Only students can upload: " + err.Error()})
-		return
-	}
-
-	if pgRef.StudentID != student.ID {
-		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "Forbidden: Not the owner"})
+	if _, ok := s.authorizeAchievement(c, pgRef, policy.ActionModifyAchievement); !ok {
 		return
 	}
 
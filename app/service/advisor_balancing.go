@@ -0,0 +1,162 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/google/uuid"
+)
+
+// advisorSlot adalah status seorang dosen selama usulan disusun
+type advisorSlot struct {
+	lecturer   *model.Lecturer
+	department string // sudah dinormalisasi
+	current    int
+	load       int
+}
+
+func (slot *advisorSlot) fullName() string {
+	if slot.lecturer.User == nil {
+		return ""
+	}
+	return slot.lecturer.User.FullName
+}
+
+// normalizeDepartment menyamakan ejaan department/program studi untuk pencocokan
+func normalizeDepartment(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// departmentUnitKey adalah kunci pencocokan lewat master data departemen
+func departmentUnitKey(id uuid.UUID) string {
+	return "id:" + id.String()
+}
+
+// studentDepartmentKeys: departemen dari master data program studi bila sudah tertaut,
+// lalu teks program studi sebagai cadangan untuk dosen yang belum tertaut
+func studentDepartmentKeys(st *model.Student) []string {
+	keys := make([]string, 0, 2)
+	if st.StudyProgram != nil {
+		keys = append(keys, departmentUnitKey(st.StudyProgram.DepartmentID))
+	}
+	return append(keys, normalizeDepartment(st.ProgramStudy))
+}
+
+// proposeAdvisors menyusun usulan dosen wali secara greedy: setiap mahasiswa mendapat dosen dengan
+// beban paling ringan di department yang sama dengan program studinya, tanpa melewati maxAdvisees
+// (0 = tanpa batas). Dosen lintas department baru dipakai pada putaran kedua, setelah semua
+// mahasiswa yang bisa dilayani department-nya sendiri mendapat dosen, dan hanya bila crossDepartment.
+func proposeAdvisors(students []model.Student, lecturers []*model.Lecturer, counts map[uuid.UUID]int, maxAdvisees int, crossDepartment bool) model.AdvisorProposal {
+	slots := make([]*advisorSlot, 0, len(lecturers))
+	byDepartment := make(map[string][]*advisorSlot)
+	for _, lecturer := range lecturers {
+		slot := &advisorSlot{
+			lecturer:   lecturer,
+			department: normalizeDepartment(lecturer.Department),
+			current:    counts[lecturer.ID],
+			load:       counts[lecturer.ID],
+		}
+		slots = append(slots, slot)
+		if lecturer.DepartmentID != nil {
+			key := departmentUnitKey(*lecturer.DepartmentID)
+			byDepartment[key] = append(byDepartment[key], slot)
+		}
+		if slot.department != "" {
+			byDepartment[slot.department] = append(byDepartment[slot.department], slot)
+		}
+	}
+
+	proposal := model.AdvisorProposal{
+		MaxAdvisees: maxAdvisees,
+		Assignments: []model.AdvisorProposalItem{},
+		Skipped:     []model.AdvisorProposalSkip{},
+	}
+	assign := func(st *model.Student, slot *advisorSlot, sameDepartment bool) {
+		slot.load++
+		proposal.Assignments = append(proposal.Assignments, model.AdvisorProposalItem{
+			StudentID:      st.ID,
+			NIM:            st.NIM,
+			FullName:       st.User.FullName,
+			ProgramStudy:   st.ProgramStudy,
+			AdvisorID:      slot.lecturer.ID,
+			AdvisorName:    slot.fullName(),
+			Department:     slot.lecturer.Department,
+			SameDepartment: sameDepartment,
+		})
+	}
+
+	// Putaran 1: department yang sama
+	var leftovers []*model.Student
+	reasons := make(map[uuid.UUID]string)
+	for i := range students {
+		st := &students[i]
+		var pool []*advisorSlot
+		for _, key := range studentDepartmentKeys(st) {
+			if pool = byDepartment[key]; len(pool) > 0 {
+				break
+			}
+		}
+		if slot := leastLoaded(pool, maxAdvisees); slot != nil {
+			assign(st, slot, true)
+			continue
+		}
+		leftovers = append(leftovers, st)
+		if len(pool) == 0 {
+			reasons[st.ID] = fmt.Sprintf("no lecturer in department %q", st.ProgramStudy)
+		} else {
+			reasons[st.ID] = fmt.Sprintf("all lecturers in department %q are at capacity", st.ProgramStudy)
+		}
+	}
+
+	// Putaran 2: lintas department
+	for _, st := range leftovers {
+		if crossDepartment {
+			if slot := leastLoaded(slots, maxAdvisees); slot != nil {
+				assign(st, slot, false)
+				continue
+			}
+			reasons[st.ID] = "all lecturers are at capacity"
+		}
+		proposal.Skipped = append(proposal.Skipped, model.AdvisorProposalSkip{
+			StudentID:    st.ID,
+			NIM:          st.NIM,
+			ProgramStudy: st.ProgramStudy,
+			Reason:       reasons[st.ID],
+		})
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].department != slots[j].department {
+			return slots[i].department < slots[j].department
+		}
+		return slots[i].lecturer.LecturerID < slots[j].lecturer.LecturerID
+	})
+	proposal.Loads = make([]model.AdvisorLoad, 0, len(slots))
+	for _, slot := range slots {
+		proposal.Loads = append(proposal.Loads, model.AdvisorLoad{
+			AdvisorID:  slot.lecturer.ID,
+			FullName:   slot.fullName(),
+			Department: slot.lecturer.Department,
+			Current:    slot.current,
+			Proposed:   slot.load,
+		})
+	}
+	return proposal
+}
+
+// leastLoaded memilih dosen dengan beban paling ringan yang masih di bawah batas (seri: NIP terkecil)
+func leastLoaded(pool []*advisorSlot, maxAdvisees int) *advisorSlot {
+	var best *advisorSlot
+	for _, slot := range pool {
+		if maxAdvisees > 0 && slot.load >= maxAdvisees {
+			continue
+		}
+		if best == nil || slot.load < best.load ||
+			(slot.load == best.load && slot.lecturer.LecturerID < best.lecturer.LecturerID) {
+			best = slot
+		}
+	}
+	return best
+}
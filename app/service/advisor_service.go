@@ -0,0 +1,532 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/policy"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/config"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AdvisorService interface {
+	AssignAdvisor(c *gin.Context)
+	BulkAssignAdvisors(c *gin.Context)
+	GetAdvisorHistory(c *gin.Context)
+	ProposeAdvisors(c *gin.Context)
+	ApplyAdvisorProposal(c *gin.Context)
+}
+
+type advisorService struct {
+	studentRepo    repository.StudentRepository
+	lecturerRepo   repository.LecturerRepository
+	assignmentRepo repository.AdvisorAssignmentRepository
+	enforcer       policy.Enforcer
+	cfg            config.AdvisorConfig
+}
+
+func NewAdvisorService(
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+	assignmentRepo repository.AdvisorAssignmentRepository,
+	enforcer policy.Enforcer,
+	cfg config.AdvisorConfig,
+) AdvisorService {
+	return &advisorService{
+		studentRepo:    studentRepo,
+		lecturerRepo:   lecturerRepo,
+		assignmentRepo: assignmentRepo,
+		enforcer:       enforcer,
+		cfg:            cfg,
+	}
+}
+
+//
+// =======================
+// ASSIGN ADVISOR
+// =======================
+// @Summary Assign Advisor
+// @Description Mengganti dosen wali satu mahasiswa dan mencatatnya di riwayat. in_flight_policy menentukan nasib
+// @Description prestasi SUBMITTED: transfer (ikut dosen wali baru), retain (tetap ke dosen wali lama), reset (kembali ke draft).
+// @Tags Students
+// @Security BearerAuth
+// @Param id path string true "Student UUID"
+// @Param request body model.AdvisorAssignRequest true "Advisor UUID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /students/{id}/advisor [put]
+func (s *advisorService) AssignAdvisor(c *gin.Context) {
+	studentUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid student id format"})
+		return
+	}
+
+	var req model.AdvisorAssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	advisorUUID, opts, status, msg := s.prepareAssignment(c, req.AdvisorID, req.EffectiveFrom, req.Reason, req.InFlightPolicy)
+	if status != 0 {
+		c.JSON(status, gin.H{"status": "error", "message": msg})
+		return
+	}
+
+	student, err := s.studentRepo.FindByID(studentUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "student not found"})
+		return
+	}
+	if student.AdvisorID != nil && *student.AdvisorID == advisorUUID {
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "student is already assigned to this advisor"})
+		return
+	}
+
+	change := model.AdvisorChange{StudentID: student.ID, PreviousAdvisorID: student.AdvisorID, AdvisorID: &advisorUUID}
+	inFlight, err := s.assignmentRepo.Reassign([]model.AdvisorChange{change}, opts)
+	if err != nil {
+		respondReassignError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "advisor assigned",
+		"data":    gin.H{"in_flight_policy": opts.InFlightPolicy, "in_flight": inFlight},
+	})
+}
+
+//
+// =======================
+// BULK ASSIGN ADVISOR
+// =======================
+// @Summary Bulk Assign Advisor
+// @Description Menugaskan satu dosen wali ke banyak mahasiswa sekaligus, dipilih lewat student_ids ATAU program_study/academic_year
+// @Description (angkatan). Semua pergantian ditulis dalam satu transaksi. dry_run=true hanya menghitung dampaknya.
+// @Tags Students
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body model.BulkAdvisorAssignRequest true "Bulk Assignment"
+// @Success 200 {object} model.BulkAdvisorAssignResult
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /advisor-assignments/bulk [post]
+func (s *advisorService) BulkAssignAdvisors(c *gin.Context) {
+	var req model.BulkAdvisorAssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	byList := len(req.StudentIDs) > 0
+	byCohort := req.ProgramStudy != "" || req.AcademicYear != ""
+	if byList == byCohort {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "select students either by student_ids or by program_study/academic_year"})
+		return
+	}
+
+	advisorUUID, opts, status, msg := s.prepareAssignment(c, req.AdvisorID, req.EffectiveFrom, req.Reason, req.InFlightPolicy)
+	if status != 0 {
+		c.JSON(status, gin.H{"status": "error", "message": msg})
+		return
+	}
+
+	result := model.BulkAdvisorAssignResult{DryRun: req.DryRun}
+
+	// 1. Pilih mahasiswa
+	var students []model.Student
+	if byList {
+		ids := make([]uuid.UUID, 0, len(req.StudentIDs))
+		requested := make(map[uuid.UUID]string, len(req.StudentIDs))
+		for _, raw := range req.StudentIDs {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid student id format: " + raw})
+				return
+			}
+			if _, dup := requested[id]; !dup {
+				requested[id] = raw
+				ids = append(ids, id)
+			}
+		}
+		found, err := s.studentRepo.FindByIDs(ids)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		for _, st := range found {
+			delete(requested, st.ID)
+			if req.OnlyUnassigned && st.AdvisorID != nil {
+				continue
+			}
+			students = append(students, st)
+		}
+		for _, id := range ids {
+			if raw, missing := requested[id]; missing {
+				result.NotFound = append(result.NotFound, raw)
+			}
+		}
+	} else {
+		found, err := s.studentRepo.FindByCohort(req.ProgramStudy, req.AcademicYear, req.OnlyUnassigned)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		students = found
+	}
+
+	// 2. Hanya mahasiswa yang dosen walinya benar-benar berubah
+	result.Matched = len(students)
+	changes := make([]model.AdvisorChange, 0, len(students))
+	changedIDs := make([]uuid.UUID, 0, len(students))
+	for _, st := range students {
+		if st.AdvisorID != nil && *st.AdvisorID == advisorUUID {
+			result.Unchanged++
+			continue
+		}
+		changes = append(changes, model.AdvisorChange{StudentID: st.ID, PreviousAdvisorID: st.AdvisorID, AdvisorID: &advisorUUID})
+		changedIDs = append(changedIDs, st.ID)
+	}
+	result.Changed = len(changes)
+
+	// 3. Dry-run hanya menghitung; selain itu tulis semua dalam satu transaksi
+	if req.DryRun {
+		inFlight, err := s.assignmentRepo.CountInFlight(changedIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		result.InFlight = int(inFlight)
+		c.JSON(http.StatusOK, gin.H{"status": "success", "data": result})
+		return
+	}
+
+	if len(changes) > 0 {
+		inFlight, err := s.assignmentRepo.Reassign(changes, opts)
+		if err != nil {
+			respondReassignError(c, err)
+			return
+		}
+		result.InFlight = inFlight
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": result})
+}
+
+//
+// =======================
+// ADVISOR HISTORY
+// =======================
+// @Summary Get Advisor History
+// @Description Riwayat dosen wali mahasiswa (terbaru lebih dulu). Baris dengan effective_to kosong adalah penugasan yang berlaku.
+// @Tags Students
+// @Security BearerAuth
+// @Param id path string true "Student UUID"
+// @Success 200 {array} model.AdvisorAssignment
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /students/{id}/advisor-history [get]
+func (s *advisorService) GetAdvisorHistory(c *gin.Context) {
+	studentUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid student id format"})
+		return
+	}
+
+	student, err := s.studentRepo.FindByID(studentUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "student not found"})
+		return
+	}
+	_, ok, err := s.enforcer.Authorize(c, policy.ActionReadStudent, policy.StudentResource(student))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "forbidden: you are not allowed to access this student"})
+		return
+	}
+
+	history, err := s.assignmentRepo.FindByStudentID(student.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": history})
+}
+
+//
+// =======================
+// WORKLOAD BALANCING
+// =======================
+// @Summary Propose Balanced Advisor Assignment
+// @Description Menyusun usulan dosen wali untuk mahasiswa yang belum punya dosen wali: dosen sejurusan (department = program studi)
+// @Description dengan bimbingan paling sedikit, tanpa melewati max_advisees. Tidak ada data yang diubah; kirim "assignments"
+// @Description ke /advisor-assignments/proposals/apply untuk menerapkannya.
+// @Tags Students
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body model.AdvisorProposalRequest false "Filter & Batas"
+// @Success 200 {object} model.AdvisorProposal
+// @Failure 400 {object} map[string]string
+// @Router /advisor-assignments/proposals [post]
+func (s *advisorService) ProposeAdvisors(c *gin.Context) {
+	var req model.AdvisorProposalRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+	}
+	maxAdvisees, ok := s.maxAdvisees(c, req.MaxAdvisees)
+	if !ok {
+		return
+	}
+
+	students, err := s.studentRepo.FindByCohort(req.ProgramStudy, req.AcademicYear, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	lecturers, err := s.lecturerRepo.FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	// Dosen yang akunnya non-aktif tidak diberi bimbingan baru
+	active := make([]*model.Lecturer, 0, len(lecturers))
+	for _, lecturer := range lecturers {
+		if lecturer.User == nil || lecturer.User.IsActive {
+			active = append(active, lecturer)
+		}
+	}
+
+	counts, err := s.adviseeCounts(active)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	proposal := proposeAdvisors(students, active, counts, maxAdvisees, req.CrossDepartment)
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": proposal})
+}
+
+// @Summary Apply Advisor Proposal
+// @Description Menerapkan usulan (boleh sudah diubah admin) dalam satu transaksi. Seluruh usulan ditolak (409) bila ada mahasiswa
+// @Description yang sementara itu sudah mendapat dosen wali atau bila batas bimbingan dosen terlampaui.
+// @Tags Students
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body model.ApplyAdvisorProposalRequest true "Usulan"
+// @Success 200 {object} model.BulkAdvisorAssignResult
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Router /advisor-assignments/proposals/apply [post]
+func (s *advisorService) ApplyAdvisorProposal(c *gin.Context) {
+	var req model.ApplyAdvisorProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	maxAdvisees, ok := s.maxAdvisees(c, req.MaxAdvisees)
+	if !ok {
+		return
+	}
+
+	// 1. Validasi pasangan
+	studentIDs := make([]uuid.UUID, 0, len(req.Assignments))
+	advisorOf := make(map[uuid.UUID]uuid.UUID, len(req.Assignments))
+	added := make(map[uuid.UUID]int)
+	for _, pair := range req.Assignments {
+		studentID, err := uuid.Parse(pair.StudentID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid student id format: " + pair.StudentID})
+			return
+		}
+		advisorID, err := uuid.Parse(pair.AdvisorID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid advisor id format: " + pair.AdvisorID})
+			return
+		}
+		if _, dup := advisorOf[studentID]; dup {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "student listed more than once: " + pair.StudentID})
+			return
+		}
+		advisorOf[studentID] = advisorID
+		studentIDs = append(studentIDs, studentID)
+		added[advisorID]++
+	}
+
+	lecturers := make([]*model.Lecturer, 0, len(added))
+	for advisorID := range added {
+		lecturer, err := s.lecturerRepo.FindByID(advisorID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "advisor not found: " + advisorID.String()})
+			return
+		}
+		lecturers = append(lecturers, lecturer)
+	}
+
+	// 2. Usulan harus masih berlaku: mahasiswa ada dan masih tanpa dosen wali
+	students, err := s.studentRepo.FindByIDs(studentIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if len(students) != len(studentIDs) {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "one or more students were not found"})
+		return
+	}
+	var stale []string
+	for _, st := range students {
+		if st.AdvisorID != nil {
+			stale = append(stale, st.NIM)
+		}
+	}
+	if len(stale) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "some students already have an advisor; request a new proposal", "data": gin.H{"nims": stale}})
+		return
+	}
+
+	// 3. Batas bimbingan dihitung ulang dari kondisi terkini
+	if maxAdvisees > 0 {
+		counts, err := s.adviseeCounts(lecturers)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		var over []string
+		for _, lecturer := range lecturers {
+			if counts[lecturer.ID]+added[lecturer.ID] > maxAdvisees {
+				over = append(over, lecturer.LecturerID)
+			}
+		}
+		if len(over) > 0 {
+			c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "proposal exceeds max_advisees for some lecturers", "data": gin.H{"nips": over}})
+			return
+		}
+	}
+
+	// 4. Satu transaksi; mahasiswa tanpa dosen wali tidak punya pengajuan yang perlu dipertahankan
+	reason := req.Reason
+	if reason == "" {
+		reason = "workload balancing"
+	}
+	// Pemeriksaan 2 dan 3 diulang repository di dalam transaksi, sebab keduanya bisa basi
+	// oleh penetapan lain yang berjalan bersamaan
+	opts := model.AdvisorChangeOptions{
+		Reason:         reason,
+		InFlightPolicy: model.InFlightTransfer,
+		EffectiveFrom:  time.Now(),
+		OnlyUnassigned: true,
+		MaxAdvisees:    maxAdvisees,
+	}
+	if req.EffectiveFrom != nil {
+		if req.EffectiveFrom.After(opts.EffectiveFrom) {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "effective_from cannot be in the future"})
+			return
+		}
+		opts.EffectiveFrom = *req.EffectiveFrom
+	}
+	if userID, err := uuid.Parse(c.GetString("userID")); err == nil {
+		opts.AssignedBy = &userID
+	}
+
+	changes := make([]model.AdvisorChange, 0, len(students))
+	for _, st := range students {
+		advisorID := advisorOf[st.ID]
+		changes = append(changes, model.AdvisorChange{StudentID: st.ID, AdvisorID: &advisorID})
+	}
+	inFlight, err := s.assignmentRepo.Reassign(changes, opts)
+	if err != nil {
+		respondReassignError(c, err)
+		return
+	}
+
+	result := model.BulkAdvisorAssignResult{Matched: len(students), Changed: len(changes), InFlight: inFlight}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": result})
+}
+
+// adviseeCounts menghitung jumlah bimbingan saat ini per dosen (mahasiswa lulus/keluar tidak dihitung)
+func (s *advisorService) adviseeCounts(lecturers []*model.Lecturer) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int, len(lecturers))
+	for _, lecturer := range lecturers {
+		advisees, err := s.studentRepo.FindByAdvisorID(lecturer.ID, false)
+		if err != nil {
+			return nil, err
+		}
+		counts[lecturer.ID] = len(advisees)
+	}
+	return counts, nil
+}
+
+// maxAdvisees memakai nilai request bila diisi, selain itu batas dari konfigurasi
+func (s *advisorService) maxAdvisees(c *gin.Context, requested int) (int, bool) {
+	if requested < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "max_advisees cannot be negative"})
+		return 0, false
+	}
+	if requested == 0 {
+		return s.cfg.MaxAdvisees, true
+	}
+	return requested, true
+}
+
+// prepareAssignment memvalidasi dosen wali tujuan, tanggal efektif, dan kebijakan in-flight.
+// Status non-nol berarti request ditolak dengan pesan msg.
+func (s *advisorService) prepareAssignment(c *gin.Context, advisorID string, effectiveFrom *time.Time, reason, inFlightPolicy string) (uuid.UUID, model.AdvisorChangeOptions, int, string) {
+	opts := model.AdvisorChangeOptions{Reason: reason, InFlightPolicy: inFlightPolicy, EffectiveFrom: time.Now()}
+
+	advisorUUID, err := uuid.Parse(advisorID)
+	if err != nil {
+		return uuid.Nil, opts, http.StatusBadRequest, "invalid advisor id format"
+	}
+	if _, err := s.lecturerRepo.FindByID(advisorUUID); err != nil {
+		return uuid.Nil, opts, http.StatusNotFound, "advisor not found"
+	}
+
+	if opts.InFlightPolicy == "" {
+		opts.InFlightPolicy = s.cfg.InFlightPolicy
+	}
+	if !model.IsValidInFlightPolicy(opts.InFlightPolicy) {
+		return uuid.Nil, opts, http.StatusBadRequest, "in_flight_policy must be one of transfer, retain, reset"
+	}
+
+	// Penugasan berlaku saat ini juga, jadi tanggal efektif hanya boleh mundur (pencatatan susulan)
+	if effectiveFrom != nil {
+		if effectiveFrom.After(opts.EffectiveFrom) {
+			return uuid.Nil, opts, http.StatusBadRequest, "effective_from cannot be in the future"
+		}
+		opts.EffectiveFrom = *effectiveFrom
+	}
+
+	if userID, err := uuid.Parse(c.GetString("userID")); err == nil {
+		opts.AssignedBy = &userID
+	}
+	return advisorUUID, opts, 0, ""
+}
+
+func respondReassignError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrAdvisorBackdated) {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if errors.Is(err, repository.ErrStudentAlreadyAssigned) || errors.Is(err, repository.ErrAdvisorCapacityExceeded) {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": err.Error() + "; request a new proposal"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+}
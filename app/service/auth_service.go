@@ -1,53 +1,81 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/fitrinovs/achievement_system/app/authn"
+	"github.com/fitrinovs/achievement_system/app/cache"
 	"github.com/fitrinovs/achievement_system/app/model"
 	"github.com/fitrinovs/achievement_system/app/repository"
 	"github.com/fitrinovs/achievement_system/app/utils"
+	"github.com/fitrinovs/achievement_system/config"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService interface {
 	Login(c *gin.Context)
+	VerifyMFA(c *gin.Context)
 	GetProfile(c *gin.Context)
 	RefreshToken(c *gin.Context)
 	Logout(c *gin.Context)
 }
 
 type authService struct {
-	userRepo     repository.UserRepository
-	studentRepo  repository.StudentRepository
-	lecturerRepo repository.LecturerRepository
+	userRepo         repository.UserRepository
+	studentRepo      repository.StudentRepository
+	lecturerRepo     repository.LecturerRepository
+	mfaRepo          repository.MFARepository
+	mfaCfg           config.MFAConfig
+	authRouter       *authn.Router
+	sessionRepo      repository.SessionRepository
+	accessCache      cache.AccessCache
+	loginThrottle
 }
 
 func NewAuthService(
 	userRepo repository.UserRepository,
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
+	loginAttemptRepo repository.LoginAttemptRepository,
+	mfaRepo repository.MFARepository,
+	loginCfg config.LoginSecurityConfig,
+	mfaCfg config.MFAConfig,
+	authRouter *authn.Router,
+	sessionRepo repository.SessionRepository,
+	accessCache cache.AccessCache,
 ) AuthService {
 	return &authService{
-		userRepo:     userRepo,
-		studentRepo:  studentRepo,
-		lecturerRepo: lecturerRepo,
+		userRepo:         userRepo,
+		studentRepo:      studentRepo,
+		lecturerRepo:     lecturerRepo,
+		mfaRepo:          mfaRepo,
+		mfaCfg:           mfaCfg,
+		authRouter:       authRouter,
+		sessionRepo:      sessionRepo,
+		accessCache:      accessCache,
+		loginThrottle:    loginThrottle{loginAttemptRepo: loginAttemptRepo, loginCfg: loginCfg},
 	}
 }
 
 // Login godoc
 // @Summary      Login User
-// @Description  Masuk sistem untuk mendapatkan Token JWT
+// @Description  Masuk sistem untuk mendapatkan Token JWT. Field username menerima username atau email.
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
-// @Param        request body model.LoginRequest true "Username & Password"
-// @Success      200  {object}  model.LoginResponse
+// @Param        request body model.LoginRequest true "Username/Email & Password"
+// @Success      200  {object}  model.LoginResponse "Atau model.MFAChallengeResponse jika MFA aktif"
 // @Failure      400  {object}  map[string]string
 // @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      423  {object}  object{status=string,message=string,retry_after=int}
+// @Failure      429  {object}  object{status=string,message=string,retry_after=int}
 // @Router       /auth/login [post]
 func (s *authService) Login(c *gin.Context) {
 	var req model.LoginRequest
@@ -56,42 +84,253 @@ func (s *authService) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := s.userRepo.FindByUsername(req.Username)
+	now := time.Now()
+	clientIP := c.ClientIP()
+
+	// 1. Limit per IP (berlaku juga untuk username yang tidak terdaftar)
+	ipStats, err := s.loginAttemptRepo.FailureStatsByIP(clientIP, now.Add(-s.loginWindow()))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "invalid username or password"})
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to check login attempts"})
+		return
+	}
+	if s.loginCfg.MaxIPFailures > 0 && ipStats.Count >= int64(s.loginCfg.MaxIPFailures) {
+		respondLoginThrottled(c, http.StatusTooManyRequests, "too many failed login attempts from this address, try again later", s.loginWindow())
 		return
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
+	// Bisa login dengan username ATAU email (tidak case-sensitive)
+	user, err := s.userRepo.FindByIdentifier(req.Username)
 	if err != nil {
+		// Format akun direktori (uid@domain) untuk domain yang memakai authenticator eksternal
+		if username, ok := s.authRouter.DirectoryUsername(req.Username); ok {
+			user, err = s.userRepo.FindByIdentifier(username)
+		}
+	}
+	if err != nil {
+		s.recordLoginFailure(nil, req.Username, clientIP)
 		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "invalid username or password"})
 		return
 	}
 
-	permissions, err := s.userRepo.GetUserPermissions(user.ID)
+	// 2. Lockout dan progressive delay per akun
+	wait, locked, err := s.checkAccountThrottle(user.ID, now)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to fetch user permissions"})
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to check login attempts"})
+		return
+	}
+	if locked {
+		respondLoginThrottled(c, http.StatusLocked, "account temporarily locked due to too many failed login attempts", wait)
+		return
+	}
+	if wait > 0 {
+		respondLoginThrottled(c, http.StatusTooManyRequests, "too many failed login attempts, please wait before retrying", wait)
+		return
+	}
+
+	// Service account tidak punya password yang bisa dipakai; autentikasi hanya lewat API key
+	if user.IsServiceAccount {
+		s.recordLoginFailure(&user.ID, req.Username, clientIP)
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "invalid username or password"})
 		return
 	}
 
+	// Verifikasi password lewat chain authenticator (local bcrypt, LDAP, ...) sesuai domain/role
 	roleName := ""
 	if user.Role != nil {
 		roleName = user.Role.Name
 	}
+	chain := s.authRouter.ChainFor(req.Username, user, roleName)
+	if _, err := chain.Authenticate(c.Request.Context(), user, req.Password); err != nil {
+		if errors.Is(err, authn.ErrUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "error", "message": "authentication service is temporarily unavailable"})
+			return
+		}
+		s.recordLoginFailure(&user.ID, req.Username, clientIP)
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "invalid username or password"})
+		return
+	}
+
+	// 3. Akun non-aktif tidak boleh login. Diperiksa setelah password agar status akun tidak bocor
+	// ke penebak password, dan tidak dicatat sebagai percobaan gagal karena password-nya benar.
+	if !user.IsActive {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "user is inactive"})
+		return
+	}
+
+	s.resetFailures(user.ID)
+
+	// 4. Jika MFA aktif, terbitkan challenge token dulu (JWT asli diberikan di /auth/mfa/verify)
+	data, err := completeLogin(c, s.userRepo, s.mfaRepo, s.sessionRepo, s.mfaCfg, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": data})
+}
+
+// completeLogin dipakai setelah identitas user terbukti (password, SSO, dsb.):
+// mengembalikan MFA challenge bila MFA aktif, atau membuka sesi baru beserta pasangan JWT.
+func completeLogin(c *gin.Context, userRepo repository.UserRepository, mfaRepo repository.MFARepository, sessionRepo repository.SessionRepository, mfaCfg config.MFAConfig, user *model.User) (interface{}, error) {
+	mfa, err := mfaRepo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, errors.New("failed to check mfa enrollment")
+	}
+	if mfa != nil && mfa.Enabled {
+		ttl := time.Duration(mfaCfg.ChallengeMinutes) * time.Minute
+		mfaToken, err := utils.GenerateMFAChallengeToken(*user, ttl)
+		if err != nil {
+			return nil, errors.New("failed to generate mfa token")
+		}
+		return model.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresIn:   int(ttl.Seconds()),
+		}, nil
+	}
+
+	session, err := startSession(c, sessionRepo, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return issueLoginTokens(userRepo, user, false, session.ID)
+}
+
+// startSession mencatat sesi login baru (perangkat, IP) yang ID-nya ditanam di token
+func startSession(c *gin.Context, sessionRepo repository.SessionRepository, userID uuid.UUID) (*model.UserSession, error) {
+	now := time.Now()
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+
+	session := &model.UserSession{
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IPAddress:  c.ClientIP(),
+		LastSeenIP: c.ClientIP(),
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(utils.RefreshTokenTTL),
+	}
+	if err := sessionRepo.Create(session); err != nil {
+		return nil, errors.New("failed to create session")
+	}
+	return session, nil
+}
+
+// VerifyMFA godoc
+// @Summary      Verify MFA (Login Step 2)
+// @Description  Menukar MFA challenge token + kode TOTP (atau recovery code) dengan pasangan JWT
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.MFAVerifyRequest true "Challenge token dan kode"
+// @Success      200  {object}  object{status=string,data=model.LoginResponse}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      423  {object}  object{status=string,message=string,retry_after=int}
+// @Router       /auth/mfa/verify [post]
+func (s *authService) VerifyMFA(c *gin.Context) {
+	var req model.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if req.Code == "" && req.RecoveryCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "code or recovery_code is required"})
+		return
+	}
+
+	claims, err := utils.ValidateMFAChallengeToken(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "invalid or expired mfa token"})
+		return
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil || !user.IsActive || claims.SecurityVersion != user.SecurityVersion {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "user is inactive or no longer exists"})
+		return
+	}
+
+	// Kode TOTP hanya 6 digit: kegagalan dihitung ke lockout akun yang sama dengan login
+	now := time.Now()
+	if s.rejectThrottled(c, user.ID, now) {
+		return
+	}
+
+	mfa, err := s.mfaRepo.FindByUserID(user.ID)
+	if err != nil || mfa == nil || !mfa.Enabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "mfa is not enabled for this user"})
+		return
+	}
 
-	tokenString, err := utils.GenerateToken(*user, roleName, permissions)
+	verified := false
+	if req.Code != "" {
+		step, ok := utils.ValidateTOTP(mfa.Secret, req.Code, now)
+		if ok && step > mfa.LastUsedStep {
+			mfa.LastUsedStep = step
+			if err := s.mfaRepo.Save(mfa); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to update mfa state"})
+				return
+			}
+			verified = true
+		}
+	} else {
+		verified, err = s.mfaRepo.UseRecoveryCode(user.ID, utils.HashRecoveryCode(req.RecoveryCode))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to check recovery code"})
+			return
+		}
+	}
+
+	if !verified {
+		s.recordLoginFailure(&user.ID, user.Username, c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "invalid mfa code"})
+		return
+	}
+
+	s.resetFailures(user.ID)
+
+	session, err := startSession(c, s.sessionRepo, user.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to generate token"})
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
 		return
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken(*user, roleName)
+	response, err := issueLoginTokens(s.userRepo, user, true, session.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to generate refresh token"})
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
 		return
 	}
 
-	response := &model.LoginResponse{
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": response})
+}
+
+// issueLoginTokens membuat pasangan access + refresh token beserta profil singkat user.
+// Dipakai oleh login biasa, verifikasi MFA, dan aktivasi MFA.
+func issueLoginTokens(userRepo repository.UserRepository, user *model.User, mfaVerified bool, sessionID uuid.UUID) (*model.LoginResponse, error) {
+	permissions, err := userRepo.GetUserPermissions(user.ID)
+	if err != nil {
+		return nil, errors.New("failed to fetch user permissions")
+	}
+
+	roleName := ""
+	if user.Role != nil {
+		roleName = user.Role.Name
+	}
+
+	tokenString, err := utils.GenerateToken(*user, roleName, permissions, mfaVerified, sessionID)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	refreshToken, err := utils.GenerateRefreshToken(*user, roleName, mfaVerified, sessionID)
+	if err != nil {
+		return nil, errors.New("failed to generate refresh token")
+	}
+
+	return &model.LoginResponse{
 		Token:        tokenString,
 		RefreshToken: refreshToken,
 		User: model.UserProfileResponse{
@@ -102,9 +341,7 @@ func (s *authService) Login(c *gin.Context) {
 			Role:        roleName,
 			Permissions: permissions,
 		},
-	}
-
-	c.JSON(http.StatusOK, gin.H{"status": "success", "data": response})
+	}, nil
 }
 
 // GetProfile godoc
@@ -145,9 +382,10 @@ func (s *authService) GetProfile(c *gin.Context) {
 	var studentData *model.Student
 	var lecturerData *model.Lecturer
 
-	if roleName == "Student" || roleName == "Mahasiswa" {
+	switch model.ProfileKindForRole(roleName) {
+	case model.ProfileKindStudent:
 		studentData, _ = s.studentRepo.FindByUserID(user.ID)
-	} else if roleName == "Lecturer" || roleName == "Dosen" {
+	case model.ProfileKindLecturer:
 		lecturerData, _ = s.lecturerRepo.FindByUserID(user.ID)
 	}
 
@@ -170,6 +408,11 @@ func (s *authService) GetProfile(c *gin.Context) {
 		profile["lecturer"] = lecturerData
 	}
 
+	// Frontend menampilkan banner "view as user" bila sesi ini hasil impersonation
+	if impersonatorID := c.GetString("impersonatorID"); impersonatorID != "" {
+		profile["impersonated_by"] = impersonatorID
+	}
+
 	c.JSON(http.StatusOK, gin.H{"status": "success", "data": profile})
 }
 
@@ -209,6 +452,12 @@ func (s *authService) RefreshToken(c *gin.Context) {
 		return
 	}
 
+	// Refresh token terbit sebelum role/status/password berubah tidak boleh dipakai lagi
+	if claims.SecurityVersion != user.SecurityVersion {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "refresh token has been revoked"})
+		return
+	}
+
 	permissions, err := s.userRepo.GetUserPermissions(user.ID)
 	if err != nil {
 		permissions = []string{}
@@ -219,13 +468,36 @@ func (s *authService) RefreshToken(c *gin.Context) {
 		roleName = user.Role.Name
 	}
 
-	newToken, err := utils.GenerateToken(*user, roleName, permissions)
+	// Refresh token harus milik sesi yang masih aktif; token lama tanpa sid dipindahkan ke sesi baru
+	now := time.Now()
+	var sessionID uuid.UUID
+	if claims.SessionID != nil {
+		session, err := s.sessionRepo.FindByID(*claims.SessionID)
+		if err != nil || session.UserID != user.ID || !session.IsActiveAt(now) {
+			c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "session has been revoked or has expired"})
+			return
+		}
+		if err := s.sessionRepo.Extend(session.ID, now.Add(utils.RefreshTokenTTL)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to extend session"})
+			return
+		}
+		sessionID = session.ID
+	} else {
+		session, err := startSession(c, s.sessionRepo, user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		sessionID = session.ID
+	}
+
+	newToken, err := utils.GenerateToken(*user, roleName, permissions, claims.MFAVerified, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to generate new token"})
 		return
 	}
 
-	newRefreshToken, err := utils.GenerateRefreshToken(*user, roleName)
+	newRefreshToken, err := utils.GenerateRefreshToken(*user, roleName, claims.MFAVerified, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to generate new refresh token"})
 		return
@@ -258,13 +530,16 @@ func (s *authService) Logout(c *gin.Context) {
 
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-	// OPTIONAL: Implementasi Token Blacklist
-	// Untuk production, simpan token ke Redis/Database dengan expiry time
-	// Contoh: utils.BlacklistToken(tokenString, expiryTime)
-
-	// NOTE: Untuk sekarang, logout hanya mengandalkan client menghapus token
-	// Server tidak perlu melakukan apa-apa karena JWT adalah stateless
-	// Client bertanggung jawab untuk menghapus token dari localStorage/cookie
+	// Cabut sesi saat ini sehingga access & refresh token-nya langsung tidak berlaku.
+	// Token lama tanpa sesi tetap mengandalkan client menghapus token.
+	if sessionID, err := uuid.Parse(c.GetString("sessionID")); err == nil {
+		if userID, err := uuid.Parse(c.GetString("userID")); err == nil {
+			if err := s.sessionRepo.Revoke(sessionID, userID, userID, time.Now()); err != nil {
+				fmt.Printf("Warning: failed to revoke session %s on logout: %v\n", sessionID, err)
+			}
+			s.accessCache.InvalidateSession(sessionID)
+		}
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
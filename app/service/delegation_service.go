@@ -0,0 +1,239 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DelegationService interface {
+	CreateDelegation(c *gin.Context)
+	GetDelegations(c *gin.Context)
+	RevokeDelegation(c *gin.Context)
+}
+
+type delegationService struct {
+	delegationRepo repository.DelegationRepository
+	lecturerRepo   repository.LecturerRepository
+}
+
+func NewDelegationService(delegationRepo repository.DelegationRepository, lecturerRepo repository.LecturerRepository) DelegationService {
+	return &delegationService{
+		delegationRepo: delegationRepo,
+		lecturerRepo:   lecturerRepo,
+	}
+}
+
+// currentActor mengembalikan user ID, status admin, dan profil dosen (nil bila bukan dosen)
+func (s *delegationService) currentActor(c *gin.Context) (uuid.UUID, bool, *model.Lecturer, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "invalid user session"})
+		return uuid.Nil, false, nil, false
+	}
+
+	isAdmin := strings.EqualFold(c.GetString("role"), model.RoleAdmin)
+	lecturer, err := s.lecturerRepo.FindByUserID(userID)
+	if err != nil {
+		lecturer = nil
+	}
+
+	if !isAdmin && lecturer == nil {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "only lecturers or admins can manage delegations"})
+		return uuid.Nil, false, nil, false
+	}
+	return userID, isAdmin, lecturer, true
+}
+
+// CreateDelegation godoc
+// @Summary      Create Verification Delegation
+// @Description  Dosen wali mendelegasikan wewenang verify/reject kepada dosen lain untuk rentang waktu tertentu. Admin dapat membuat delegasi untuk dosen mana pun lewat principal_id.
+// @Tags         Delegations
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.DelegationCreateRequest true "Delegation Data"
+// @Success      201 {object} model.VerificationDelegation
+// @Failure      400 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /delegations [post]
+func (s *delegationService) CreateDelegation(c *gin.Context) {
+	userID, isAdmin, lecturer, ok := s.currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.DelegationCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	// Principal: dosen hanya boleh mendelegasikan wewenangnya sendiri
+	var principalID uuid.UUID
+	switch {
+	case req.PrincipalID != "" && isAdmin:
+		id, err := uuid.Parse(req.PrincipalID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid principal id format"})
+			return
+		}
+		principalID = id
+	case req.PrincipalID != "" && (lecturer == nil || req.PrincipalID != lecturer.ID.String()):
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "lecturers can only delegate their own authority"})
+		return
+	case lecturer != nil:
+		principalID = lecturer.ID
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "principal_id is required"})
+		return
+	}
+
+	delegateID, err := uuid.Parse(req.DelegateID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid delegate id format"})
+		return
+	}
+	if delegateID == principalID {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "cannot delegate to the same lecturer"})
+		return
+	}
+
+	if !req.EndsAt.After(req.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "ends_at must be after starts_at"})
+		return
+	}
+	if !req.EndsAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "ends_at must be in the future"})
+		return
+	}
+
+	if _, err := s.lecturerRepo.FindByID(principalID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "principal lecturer not found"})
+		return
+	}
+	if _, err := s.lecturerRepo.FindByID(delegateID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "delegate lecturer not found"})
+		return
+	}
+
+	overlap, err := s.delegationRepo.HasOverlap(principalID, delegateID, req.StartsAt, req.EndsAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if overlap {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "an overlapping delegation to this lecturer already exists"})
+		return
+	}
+
+	delegation := model.VerificationDelegation{
+		PrincipalID: principalID,
+		DelegateID:  delegateID,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		Reason:      req.Reason,
+		CreatedBy:   userID,
+	}
+	if err := s.delegationRepo.Create(&delegation); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	result, _ := s.delegationRepo.FindByID(delegation.ID)
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": result})
+}
+
+// GetDelegations godoc
+// @Summary      List Verification Delegations
+// @Description  Dosen melihat delegasi di mana ia menjadi principal atau delegate. Admin melihat semua (opsional filter lecturer_id).
+// @Tags         Delegations
+// @Security     BearerAuth
+// @Produce      json
+// @Param        active query bool false "Hanya delegasi yang sedang berlaku"
+// @Param        lecturer_id query string false "Filter dosen (admin saja)"
+// @Success      200 {array} model.VerificationDelegation
+// @Router       /delegations [get]
+func (s *delegationService) GetDelegations(c *gin.Context) {
+	_, isAdmin, lecturer, ok := s.currentActor(c)
+	if !ok {
+		return
+	}
+
+	var filter model.DelegationFilter
+	if isAdmin {
+		if raw := c.Query("lecturer_id"); raw != "" {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid lecturer_id format"})
+				return
+			}
+			filter.LecturerID = &id
+		}
+	} else {
+		filter.LecturerID = &lecturer.ID
+	}
+
+	if active, _ := strconv.ParseBool(c.Query("active")); active {
+		now := time.Now()
+		filter.ActiveAt = &now
+	}
+
+	delegations, err := s.delegationRepo.FindAll(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": delegations})
+}
+
+// RevokeDelegation godoc
+// @Summary      Revoke Verification Delegation
+// @Description  Mencabut delegasi lebih awal. Dapat dilakukan oleh principal, pembuat delegasi, atau admin.
+// @Tags         Delegations
+// @Security     BearerAuth
+// @Param        id path string true "Delegation UUID"
+// @Success      200 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /delegations/{id} [delete]
+func (s *delegationService) RevokeDelegation(c *gin.Context) {
+	userID, isAdmin, lecturer, ok := s.currentActor(c)
+	if !ok {
+		return
+	}
+
+	delegationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid delegation id format"})
+		return
+	}
+
+	delegation, err := s.delegationRepo.FindByID(delegationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	isPrincipal := lecturer != nil && delegation.PrincipalID == lecturer.ID
+	if !isAdmin && !isPrincipal && delegation.CreatedBy != userID {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "only the principal, the creator, or an admin can revoke this delegation"})
+		return
+	}
+
+	if err := s.delegationRepo.Revoke(delegationID, userID, time.Now()); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "delegation revoked"})
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/cache"
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/app/utils"
+	"github.com/fitrinovs/achievement_system/config"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ImpersonationService interface {
+	StartImpersonation(c *gin.Context)
+	GetAuditLogs(c *gin.Context)
+}
+
+type impersonationService struct {
+	userRepo    repository.UserRepository
+	auditRepo   repository.AuditLogRepository
+	accessCache cache.AccessCache
+	cfg         config.ImpersonationConfig
+}
+
+func NewImpersonationService(
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditLogRepository,
+	accessCache cache.AccessCache,
+	cfg config.ImpersonationConfig,
+) ImpersonationService {
+	return &impersonationService{
+		userRepo:    userRepo,
+		auditRepo:   auditRepo,
+		accessCache: accessCache,
+		cfg:         cfg,
+	}
+}
+
+// StartImpersonation godoc
+// @Summary      Impersonate User
+// @Description  Menerbitkan token "view as user" yang membawa ID admin asli dan ID user yang ditiru. Secara default hanya request baca yang diizinkan; semua request dicatat di audit log.
+// @Tags         Admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User UUID yang akan ditiru"
+// @Param        request body model.ImpersonationRequest true "Alasan impersonation"
+// @Success      200 {object} object{status=string,data=model.ImpersonationResponse}
+// @Failure      400 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /admin/impersonate/{id} [post]
+func (s *impersonationService) StartImpersonation(c *gin.Context) {
+	if c.GetBool("impersonating") {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "cannot start impersonation from an impersonated session"})
+		return
+	}
+
+	actorID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "invalid user session"})
+		return
+	}
+
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid user id format"})
+		return
+	}
+	if targetID == actorID {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "cannot impersonate yourself"})
+		return
+	}
+
+	var req model.ImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	target, err := s.userRepo.FindByID(targetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "user not found"})
+		return
+	}
+
+	if target.IsServiceAccount {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "service accounts cannot be impersonated"})
+		return
+	}
+
+	roleAccess, err := s.accessCache.RoleAccess(target.RoleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to resolve user role"})
+		return
+	}
+
+	// Mencegah eskalasi: sesama admin tidak bisa saling meniru
+	if strings.EqualFold(roleAccess.Name, model.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "administrators cannot be impersonated"})
+		return
+	}
+
+	ttl := time.Duration(s.cfg.TokenMinutes) * time.Minute
+	token, err := utils.GenerateImpersonationToken(*target, roleAccess.Name, roleAccess.Permissions, actorID, c.GetBool("mfaVerified"), ttl)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to generate impersonation token"})
+		return
+	}
+
+	// Sesi impersonation tanpa jejak audit tidak boleh diterbitkan
+	entry := &model.AuditLog{
+		Action:        model.AuditActionImpersonationStart,
+		ActorID:       actorID,
+		SubjectUserID: &target.ID,
+		Method:        c.Request.Method,
+		Path:          c.Request.URL.Path,
+		StatusCode:    http.StatusOK,
+		IPAddress:     c.ClientIP(),
+		Detail:        req.Reason,
+	}
+	if err := s.auditRepo.Create(entry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to record audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": model.ImpersonationResponse{
+		Token:          token,
+		ExpiresIn:      int(ttl.Seconds()),
+		ImpersonatorID: actorID,
+		ImpersonatedUser: model.UserProfileResponse{
+			ID:          target.ID,
+			Username:    target.Username,
+			Fullname:    target.FullName,
+			Email:       target.Email,
+			Role:        roleAccess.Name,
+			Permissions: roleAccess.Permissions,
+		},
+		WritesAllowed: s.cfg.AllowWrites,
+	}})
+}
+
+// GetAuditLogs godoc
+// @Summary      List Audit Logs
+// @Tags         Admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        actor_id query string false "Filter admin/aktor asli"
+// @Param        user_id query string false "Filter user yang dikenai aksi"
+// @Param        action query string false "Filter aksi (mis. impersonation.start)"
+// @Param        limit query int false "Jumlah maksimum (default 100, maks 500)"
+// @Success      200 {array} model.AuditLog
+// @Failure      400 {object} map[string]string
+// @Router       /admin/audit-logs [get]
+func (s *impersonationService) GetAuditLogs(c *gin.Context) {
+	filter := model.AuditLogFilter{Action: c.Query("action"), Limit: 100}
+
+	if raw := c.Query("actor_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid actor_id format"})
+			return
+		}
+		filter.ActorID = &id
+	}
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid user_id format"})
+			return
+		}
+		filter.SubjectUserID = &id
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "limit must be a positive number"})
+			return
+		}
+		if limit > 500 {
+			limit = 500
+		}
+		filter.Limit = limit
+	}
+
+	logs, err := s.auditRepo.FindAll(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": logs})
+}
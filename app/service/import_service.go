@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/fitrinovs/achievement_system/app/importer"
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/gin-gonic/gin"
+)
+
+type ImportService interface {
+	ImportStudents(c *gin.Context)
+	ImportLecturers(c *gin.Context)
+	DownloadErrorFile(c *gin.Context)
+}
+
+type importService struct {
+	importer   *importer.Importer
+	errorFiles *importer.ErrorFileStore
+	maxRows    int
+	maxSize    int64
+}
+
+func NewImportService(imp *importer.Importer, errorFiles *importer.ErrorFileStore, maxRows int, maxSize int64) ImportService {
+	return &importService{
+		importer:   imp,
+		errorFiles: errorFiles,
+		maxRows:    maxRows,
+		maxSize:    maxSize,
+	}
+}
+
+// ImportStudents godoc
+// @Summary      Import Students (CSV/XLSX)
+// @Description  Membuat user (role Mahasiswa) + profil mahasiswa secara massal. Kolom: nim, name, email, program_study, academic_year, advisor_nip (opsional).
+// @Description  Semua baris divalidasi dulu; bila ada yang gagal tidak ada yang ditulis (422) dan CSV baris gagal bisa diunduh lewat error_file_id.
+// @Tags         Students
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file formData file true "File .csv atau .xlsx (baris pertama header)"
+// @Param        dry_run query bool false "Hanya validasi, tanpa menulis"
+// @Param        upsert query bool false "Perbarui mahasiswa yang NIM-nya sudah ada"
+// @Success      200 {object} model.ImportReport
+// @Failure      400 {object} map[string]string
+// @Failure      422 {object} model.ImportReport
+// @Router       /students/import [post]
+func (s *importService) ImportStudents(c *gin.Context) {
+	s.runImport(c, model.ImportKindStudents)
+}
+
+// ImportLecturers godoc
+// @Summary      Import Lecturers (CSV/XLSX)
+// @Description  Membuat user (role Dosen Wali) + profil dosen secara massal. Kolom: nip, name, email, department (opsional).
+// @Tags         Lecturers
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file formData file true "File .csv atau .xlsx (baris pertama header)"
+// @Param        dry_run query bool false "Hanya validasi, tanpa menulis"
+// @Param        upsert query bool false "Perbarui dosen yang NIP-nya sudah ada"
+// @Success      200 {object} model.ImportReport
+// @Failure      400 {object} map[string]string
+// @Failure      422 {object} model.ImportReport
+// @Router       /lecturers/import [post]
+func (s *importService) ImportLecturers(c *gin.Context) {
+	s.runImport(c, model.ImportKindLecturers)
+}
+
+// DownloadErrorFile godoc
+// @Summary      Download Import Error File
+// @Description  CSV berisi baris yang gagal (kolom row dan errors di depan kolom asli). Berlaku selama IMPORT_ERROR_FILE_HOURS.
+// @Tags         Imports
+// @Security     BearerAuth
+// @Produce      text/csv
+// @Param        id path string true "error_file_id dari laporan import"
+// @Success      200 {file} file
+// @Failure      404 {object} map[string]string
+// @Router       /imports/errors/{id} [get]
+func (s *importService) DownloadErrorFile(c *gin.Context) {
+	path, err := s.errorFiles.Path(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.FileAttachment(path, "import-errors-"+c.Param("id")+".csv")
+}
+
+func (s *importService) runImport(c *gin.Context, kind string) {
+	opts, err := parseImportOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "file is required"})
+		return
+	}
+	if fileHeader.Size > s.maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"status": "error", "message": fmt.Sprintf("file exceeds %d bytes", s.maxSize)})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	table, err := importer.ReadTable(fileHeader.Filename, file, s.maxRows)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	report, err := s.importer.Run(kind, table, opts)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, importer.ErrInvalidFile) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	if report.Failed > 0 {
+		if id, err := s.errorFiles.Save(table, report); err != nil {
+			fmt.Printf("Warning: failed to store import error file: %v\n", err)
+		} else {
+			report.ErrorFileID = id
+		}
+	}
+
+	if report.Failed > 0 && !opts.DryRun {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"status":  "error",
+			"message": fmt.Sprintf("import rejected: %d of %d rows failed validation, nothing was written", report.Failed, report.TotalRows),
+			"data":    report,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": report})
+}
+
+func parseImportOptions(c *gin.Context) (importer.Options, error) {
+	var opts importer.Options
+	for name, target := range map[string]*bool{"dry_run": &opts.DryRun, "upsert": &opts.Upsert} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return opts, fmt.Errorf("%s must be true or false", name)
+		}
+		*target = value
+	}
+	return opts, nil
+}
@@ -2,10 +2,13 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/fitrinovs/achievement_system/app/model"
 	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/app/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -25,17 +28,20 @@ type lecturerService struct {
 	lecturerRepo repository.LecturerRepository
 	userRepo     repository.UserRepository
 	studentRepo  repository.StudentRepository
+	units        academicUnitLinker
 }
 
 func NewLecturerService(
 	lecturerRepo repository.LecturerRepository,
 	userRepo repository.UserRepository,
 	studentRepo repository.StudentRepository,
+	unitRepo repository.AcademicUnitRepository,
 ) LecturerService {
 	return &lecturerService{
 		lecturerRepo: lecturerRepo,
 		userRepo:     userRepo,
 		studentRepo:  studentRepo,
+		units:        academicUnitLinker{unitRepo: unitRepo},
 	}
 }
 
@@ -80,12 +86,20 @@ func (s *lecturerService) CreateLecturer(c *gin.Context) {
 		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "Lecturer already exists for this user"})
 		return
 	}
+	// Satu user hanya boleh punya satu jenis profil
+	if _, err := s.studentRepo.FindByUserID(userUUID); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "User already has a student profile"})
+		return
+	}
 
 	newLecturer := model.Lecturer{
 		UserID:     userUUID,
 		LecturerID: req.LecturerID, // NIP/NIDN
 		Department: req.Department,
 	}
+	if !s.units.linkLecturer(c, &newLecturer, req.DepartmentID) {
+		return
+	}
 
 	if err := s.lecturerRepo.Create(&newLecturer); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
@@ -153,17 +167,27 @@ func (s *lecturerService) GetLecturerByUserID(c *gin.Context) {
 // @Summary      Get All Lecturers
 // @Tags         Lecturers
 // @Security     BearerAuth
-// @Success      200 {array} model.Lecturer
+// @Param        page query int false "Nomor halaman (default 1)"
+// @Param        limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Param        cursor query string false "Cursor dari meta.next_cursor (keyset pagination, mengabaikan page)"
+// @Param        q query string false "Cari nama, NIP, atau email"
+// @Param        sort query string false "name | nip | department | created_at (awali - untuk descending)"
+// @Param        department query string false "Departemen"
+// @Param        department_id query string false "UUID departemen, atau none untuk yang belum terpetakan"
+// @Param        active query string false "true | false | all"
+// @Success      200 {object} utils.Response{data=[]model.Lecturer,meta=model.PageMeta}
+// @Failure      400 {object} map[string]string
 // @Failure      500 {object} map[string]string
 // @Router       /lecturers [get]
 func (s *lecturerService) GetAllLecturers(c *gin.Context) {
-	lecturers, err := s.lecturerRepo.FindAll()
+	query, err := utils.ParseListQuery(c, "department", "department_id", "active")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "data": lecturers})
+	lecturers, meta, err := s.lecturerRepo.List(query)
+	respondList(c, "lecturers retrieved", lecturers, meta, err)
 }
 
 // UpdateLecturer godoc
@@ -199,8 +223,11 @@ func (s *lecturerService) UpdateLecturer(c *gin.Context) {
 	if req.LecturerID != "" {
 		lecturer.LecturerID = req.LecturerID
 	}
-	if req.Department != "" {
+	if req.Department != "" || req.DepartmentID != "" {
 		lecturer.Department = req.Department
+		if !s.units.linkLecturer(c, lecturer, req.DepartmentID) {
+			return
+		}
 	}
 
 	if err := s.lecturerRepo.Update(lecturer); err != nil {
@@ -211,14 +238,29 @@ func (s *lecturerService) UpdateLecturer(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Lecturer updated successfully", "data": lecturer})
 }
 
+// lecturerAdviseeBlocker menolak penghapusan/deaktivasi dosen yang masih punya mahasiswa bimbingan
+// aktif (mahasiswa lulus/keluar tidak dihitung). Mengembalikan "" bila tidak ada yang menghalangi.
+func lecturerAdviseeBlocker(studentRepo repository.StudentRepository, lecturerID uuid.UUID) (string, error) {
+	advisees, err := studentRepo.FindByAdvisorID(lecturerID, false)
+	if err != nil {
+		return "", err
+	}
+	if len(advisees) > 0 {
+		return fmt.Sprintf("lecturer still has %d active advisee(s); reassign them first", len(advisees)), nil
+	}
+	return "", nil
+}
+
 // DeleteLecturer godoc
 // @Summary      Delete Lecturer
+// @Description  Soft delete profil dosen. Ditolak 409 bila dosen masih punya mahasiswa bimbingan aktif.
 // @Tags         Lecturers
 // @Security     BearerAuth
 // @Param        id   path      string  true  "Lecturer ID (UUID)"
 // @Success      200  {object}  map[string]string
 // @Failure      400  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
 // @Router      /lecturers/{id} [delete]
 func (s *lecturerService) DeleteLecturer(c *gin.Context) {
 	id := c.Param("id")
@@ -228,6 +270,20 @@ func (s *lecturerService) DeleteLecturer(c *gin.Context) {
 		return
 	}
 
+	if _, err := s.lecturerRepo.FindByID(lecturerUUID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "lecturer not found"})
+		return
+	}
+	reason, err := lecturerAdviseeBlocker(s.studentRepo, lecturerUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if reason != "" {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": reason})
+		return
+	}
+
 	if err := s.lecturerRepo.Delete(lecturerUUID); err != nil {
 		if errors.Is(err, errors.New("lecturer not found")) {
 			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "lecturer not found"})
@@ -245,9 +301,11 @@ func (s *lecturerService) DeleteLecturer(c *gin.Context) {
 // GET /lecturers/:id/advisees
 // ===================================
 // @Summary      Get Advisees (Students) by Lecturer ID
+// @Description  Mahasiswa lulus/keluar diarsipkan dan hanya ikut bila include_archived=true.
 // @Tags         Lecturers
 // @Security     BearerAuth
 // @Param        id path string true "Lecturer UUID"
+// @Param        include_archived query bool false "Sertakan mahasiswa lulus/keluar"
 // @Success      200 {array} model.Advisee
 // @Failure      400 {object} map[string]string
 // @Failure      404 {object} map[string]string
@@ -268,8 +326,17 @@ func (s *lecturerService) GetAdviseesByLecturerID(c *gin.Context) {
 		return
 	}
 
+	includeArchived := false
+	if raw := c.Query("include_archived"); raw != "" {
+		includeArchived, err = strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "include_archived must be true or false"})
+			return
+		}
+	}
+
 	// 2. Ambil students berdasarkan advisor_id
-	students, err := s.studentRepo.FindByAdvisorID(lecturerUUID)
+	students, err := s.studentRepo.FindByAdvisorID(lecturerUUID, includeArchived)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
 		return
@@ -291,6 +358,7 @@ func (s *lecturerService) GetAdviseesByLecturerID(c *gin.Context) {
 			FullName:     fullname,
 			ProgramStudy: student.ProgramStudy,
 			AcademicYear: student.AcademicYear,
+			Status:       student.CurrentStatus(),
 		})
 	}
 
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/config"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// loginThrottle adalah proteksi brute-force bersama: login password, verifikasi MFA saat login,
+// dan setiap endpoint lain yang menerima kode TOTP memakai hitungan kegagalan per akun yang sama.
+type loginThrottle struct {
+	loginAttemptRepo repository.LoginAttemptRepository
+	loginCfg         config.LoginSecurityConfig
+}
+
+func (t *loginThrottle) loginWindow() time.Duration {
+	return time.Duration(t.loginCfg.WindowMinutes) * time.Minute
+}
+
+// checkAccountThrottle mengembalikan sisa waktu tunggu untuk akun (0 jika boleh mencoba login)
+// dan true jika akun sedang terkunci (bukan sekadar progressive delay).
+func (t *loginThrottle) checkAccountThrottle(userID uuid.UUID, now time.Time) (time.Duration, bool, error) {
+	stats, err := t.loginAttemptRepo.FailureStatsByUserID(userID, now.Add(-t.loginWindow()))
+	if err != nil || stats.LastAttemptAt == nil {
+		return 0, false, err
+	}
+
+	if t.loginCfg.MaxAccountFailures > 0 && stats.Count >= int64(t.loginCfg.MaxAccountFailures) {
+		lockedUntil := stats.LastAttemptAt.Add(time.Duration(t.loginCfg.LockoutMinutes) * time.Minute)
+		if now.Before(lockedUntil) {
+			return lockedUntil.Sub(now), true, nil
+		}
+		return 0, false, nil
+	}
+
+	if t.loginCfg.DelayAfterFailures > 0 && stats.Count >= int64(t.loginCfg.DelayAfterFailures) {
+		// Delay berlipat dua setiap kegagalan: base, 2x base, 4x base, ... (dibatasi MaxDelay)
+		exponent := float64(stats.Count - int64(t.loginCfg.DelayAfterFailures))
+		delay := time.Duration(math.Min(
+			float64(t.loginCfg.BaseDelaySeconds)*math.Pow(2, exponent),
+			float64(t.loginCfg.MaxDelaySeconds),
+		) * float64(time.Second))
+
+		nextAllowed := stats.LastAttemptAt.Add(delay)
+		if now.Before(nextAllowed) {
+			return nextAllowed.Sub(now), false, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// recordLoginFailure mencatat percobaan gagal. userID nil berarti akun tidak dikenal
+// (tetap dicatat supaya limit per IP berlaku).
+func (t *loginThrottle) recordLoginFailure(userID *uuid.UUID, identifier, ip string) {
+	attempt := &model.LoginAttempt{
+		UserID:     userID,
+		Identifier: strings.ToLower(strings.TrimSpace(identifier)),
+		IPAddress:  ip,
+	}
+	if err := t.loginAttemptRepo.Create(attempt); err != nil {
+		fmt.Printf("Warning: failed to record login attempt for %s from %s: %v\n", attempt.Identifier, ip, err)
+	}
+}
+
+func respondLoginThrottled(c *gin.Context, status int, message string, wait time.Duration) {
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", fmt.Sprintf("%d", retryAfter))
+	c.JSON(status, gin.H{"status": "error", "message": message, "retry_after": retryAfter})
+}
+
+// rejectThrottled menulis response 423/429 (atau 500) dan mengembalikan true bila akun
+// sedang dikunci atau masih dalam progressive delay; dipakai semua endpoint yang memeriksa kode MFA
+func (t *loginThrottle) rejectThrottled(c *gin.Context, userID uuid.UUID, now time.Time) bool {
+	wait, locked, err := t.checkAccountThrottle(userID, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to check login attempts"})
+		return true
+	}
+	if locked {
+		respondLoginThrottled(c, http.StatusLocked, "account temporarily locked due to too many failed login attempts", wait)
+		return true
+	}
+	if wait > 0 {
+		respondLoginThrottled(c, http.StatusTooManyRequests, "too many failed attempts, please wait before retrying", wait)
+		return true
+	}
+	return false
+}
+
+// resetFailures menghapus hitungan kegagalan akun setelah verifikasi berhasil
+func (t *loginThrottle) resetFailures(userID uuid.UUID) {
+	if err := t.loginAttemptRepo.DeleteByUserID(userID); err != nil {
+		fmt.Printf("Warning: failed to reset login attempts for user %s: %v\n", userID, err)
+	}
+}
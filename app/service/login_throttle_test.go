@@ -0,0 +1,184 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/authn"
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/config"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeLoginAttemptRepo menyimpan percobaan gagal di memori dengan semantik yang sama seperti tabel login_attempts
+type fakeLoginAttemptRepo struct {
+	attempts []model.LoginAttempt
+}
+
+func (r *fakeLoginAttemptRepo) Create(attempt *model.LoginAttempt) error {
+	attempt.CreatedAt = time.Now()
+	r.attempts = append(r.attempts, *attempt)
+	return nil
+}
+
+func (r *fakeLoginAttemptRepo) stats(match func(model.LoginAttempt) bool, since time.Time) *model.LoginFailureStats {
+	stats := &model.LoginFailureStats{}
+	for _, attempt := range r.attempts {
+		if !match(attempt) || attempt.CreatedAt.Before(since) {
+			continue
+		}
+		stats.Count++
+		createdAt := attempt.CreatedAt
+		if stats.LastAttemptAt == nil || createdAt.After(*stats.LastAttemptAt) {
+			stats.LastAttemptAt = &createdAt
+		}
+	}
+	return stats
+}
+
+func (r *fakeLoginAttemptRepo) FailureStatsByUserID(userID uuid.UUID, since time.Time) (*model.LoginFailureStats, error) {
+	return r.stats(func(a model.LoginAttempt) bool { return a.UserID != nil && *a.UserID == userID }, since), nil
+}
+
+func (r *fakeLoginAttemptRepo) FailureStatsByIP(ip string, since time.Time) (*model.LoginFailureStats, error) {
+	return r.stats(func(a model.LoginAttempt) bool { return a.IPAddress == ip }, since), nil
+}
+
+func (r *fakeLoginAttemptRepo) DeleteByUserID(userID uuid.UUID) error {
+	kept := r.attempts[:0]
+	for _, attempt := range r.attempts {
+		if attempt.UserID == nil || *attempt.UserID != userID {
+			kept = append(kept, attempt)
+		}
+	}
+	r.attempts = kept
+	return nil
+}
+
+type throttleFakeUserRepo struct {
+	repository.UserRepository
+	user *model.User
+}
+
+func (r *throttleFakeUserRepo) FindByID(uuid.UUID) (*model.User, error) {
+	return r.user, nil
+}
+
+func (r *throttleFakeUserRepo) FindByIdentifier(string) (*model.User, error) {
+	return nil, errors.New("user not found")
+}
+
+type throttleFakeMFARepo struct {
+	repository.MFARepository
+	mfa *model.UserMFA
+}
+
+func (r *throttleFakeMFARepo) FindByUserID(uuid.UUID) (*model.UserMFA, error) {
+	return r.mfa, nil
+}
+
+func (r *throttleFakeMFARepo) Save(mfa *model.UserMFA) error {
+	r.mfa = mfa
+	return nil
+}
+
+var testLoginSecurity = config.LoginSecurityConfig{
+	MaxAccountFailures: 3,
+	LockoutMinutes:     15,
+	MaxIPFailures:      5,
+	WindowMinutes:      15,
+}
+
+func TestMFAManagementCodesShareAccountLockout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := &model.User{ID: uuid.New(), Username: "budi", IsActive: true}
+	attempts := &fakeLoginAttemptRepo{}
+	mfaRepo := &throttleFakeMFARepo{mfa: &model.UserMFA{UserID: user.ID, Secret: "JBSWY3DPEHPK3PXP", Enabled: true}}
+	svc := NewMFAService(mfaRepo, &throttleFakeUserRepo{user: user}, nil, attempts, testLoginSecurity, config.MFAConfig{}, nil)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("userID", user.ID.String()) })
+	r.POST("/auth/mfa/disable", svc.DisableMFA)
+	r.POST("/auth/mfa/recovery-codes", svc.RegenerateRecoveryCodes)
+
+	post := func(path string) int {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(`{"code":"000000"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Kegagalan di dua endpoint berbeda dihitung ke counter akun yang sama
+	for i, path := range []string{"/auth/mfa/disable", "/auth/mfa/recovery-codes", "/auth/mfa/disable"} {
+		if code := post(path); code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d on %s: status = %d, want 401", i+1, path, code)
+		}
+	}
+	if code := post("/auth/mfa/recovery-codes"); code != http.StatusLocked {
+		t.Fatalf("status after %d failures = %d, want 423", testLoginSecurity.MaxAccountFailures, code)
+	}
+	if len(attempts.attempts) != testLoginSecurity.MaxAccountFailures {
+		t.Fatalf("recorded %d failures, want %d (locked requests must not be counted)", len(attempts.attempts), testLoginSecurity.MaxAccountFailures)
+	}
+}
+
+func TestLoginPerIPLimitIgnoresForgedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		wantLimited    bool
+	}{
+		// Default TRUSTED_PROXIES kosong: header dari klien tidak mengubah IP yang dihitung
+		{name: "untrusted header", trustedProxies: nil, wantLimited: true},
+		// Di belakang proxy terdaftar, IP klien asli diambil dari header
+		{name: "trusted proxy", trustedProxies: []string{"203.0.113.7"}, wantLimited: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authRouter, err := authn.NewRouter(config.AuthChainConfig{Default: []string{authn.BackendLocal}}, authn.NewLocalAuthenticator())
+			if err != nil {
+				t.Fatal(err)
+			}
+			svc := NewAuthService(&throttleFakeUserRepo{}, nil, nil, &fakeLoginAttemptRepo{}, nil,
+				testLoginSecurity, config.MFAConfig{}, authRouter, nil, nil)
+
+			// Engine dikonfigurasi seperti main.go
+			r := gin.New()
+			if err := r.SetTrustedProxies(tt.trustedProxies); err != nil {
+				t.Fatal(err)
+			}
+			r.POST("/auth/login", svc.Login)
+
+			var last int
+			for i := 0; i <= testLoginSecurity.MaxIPFailures; i++ {
+				req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(`{"username":"nobody","password":"wrong"}`))
+				req.Header.Set("Content-Type", "application/json")
+				req.RemoteAddr = "203.0.113.7:40000"
+				// Setiap request memalsukan alamat berbeda untuk mereset counter per IP
+				req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i+1))
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				last = w.Code
+			}
+
+			want := http.StatusUnauthorized
+			if tt.wantLimited {
+				want = http.StatusTooManyRequests
+			}
+			if last != want {
+				t.Fatalf("status after %d failures = %d, want %d", testLoginSecurity.MaxIPFailures, last, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,428 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/cache"
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/app/utils"
+	"github.com/fitrinovs/achievement_system/config"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const recoveryCodeCount = 10
+
+type MFAService interface {
+	GetMFAStatus(c *gin.Context)
+	EnrollMFA(c *gin.Context)
+	EnableMFA(c *gin.Context)
+	DisableMFA(c *gin.Context)
+	RegenerateRecoveryCodes(c *gin.Context)
+	// Admin: reset MFA user yang kehilangan perangkat dan recovery code
+	ResetUserMFA(c *gin.Context)
+}
+
+type mfaService struct {
+	mfaRepo     repository.MFARepository
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	mfaCfg      config.MFAConfig
+	accessCache cache.AccessCache
+	// Kode TOTP di endpoint manajemen MFA ikut throttle/lockout akun yang sama dengan login
+	loginThrottle
+}
+
+func NewMFAService(mfaRepo repository.MFARepository, userRepo repository.UserRepository, sessionRepo repository.SessionRepository, loginAttemptRepo repository.LoginAttemptRepository, loginCfg config.LoginSecurityConfig, mfaCfg config.MFAConfig, accessCache cache.AccessCache) MFAService {
+	return &mfaService{
+		mfaRepo:       mfaRepo,
+		userRepo:      userRepo,
+		sessionRepo:   sessionRepo,
+		mfaCfg:        mfaCfg,
+		accessCache:   accessCache,
+		loginThrottle: loginThrottle{loginAttemptRepo: loginAttemptRepo, loginCfg: loginCfg},
+	}
+}
+
+// =================================================================
+// PRIVATE UTILITY
+// =================================================================
+
+func (s *mfaService) isRequiredForRole(role string) bool {
+	for _, r := range s.mfaCfg.RequiredRoles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *mfaService) currentUser(c *gin.Context) (*model.User, bool) {
+	userUUID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "invalid user session"})
+		return nil, false
+	}
+	user, err := s.userRepo.FindByID(userUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "user not found"})
+		return nil, false
+	}
+	return user, true
+}
+
+// verifyCode memvalidasi kode TOTP dan menyimpan step terakhir (anti-replay)
+func (s *mfaService) verifyCode(mfa *model.UserMFA, code string) (bool, error) {
+	step, ok := utils.ValidateTOTP(mfa.Secret, code, time.Now())
+	if !ok || step <= mfa.LastUsedStep {
+		return false, nil
+	}
+	mfa.LastUsedStep = step
+	return true, s.mfaRepo.Save(mfa)
+}
+
+// checkCode memeriksa throttle akun lalu kode TOTP; kegagalan dicatat seperti login gagal.
+// Mengembalikan false bila response error sudah ditulis.
+func (s *mfaService) checkCode(c *gin.Context, user *model.User, mfa *model.UserMFA, code string) bool {
+	if s.rejectThrottled(c, user.ID, time.Now()) {
+		return false
+	}
+
+	valid, err := s.verifyCode(mfa, code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return false
+	}
+	if !valid {
+		s.recordLoginFailure(&user.ID, user.Username, c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "invalid mfa code"})
+		return false
+	}
+
+	s.resetFailures(user.ID)
+	return true
+}
+
+func (s *mfaService) issueRecoveryCodes(userID uuid.UUID) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, utils.HashRecoveryCode(code))
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// =================================================================
+// HANDLERS
+// =================================================================
+
+// GetMFAStatus godoc
+// @Summary      Get MFA Status
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  object{status=string,data=model.MFAStatusResponse}
+// @Router       /auth/mfa [get]
+func (s *mfaService) GetMFAStatus(c *gin.Context) {
+	user, ok := s.currentUser(c)
+	if !ok {
+		return
+	}
+
+	mfa, err := s.mfaRepo.FindByUserID(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	status := model.MFAStatusResponse{Required: s.isRequiredForRole(c.GetString("role"))}
+	if mfa != nil && mfa.Enabled {
+		status.Enabled = true
+		status.EnabledAt = mfa.EnabledAt
+		status.RemainingRecoveryCodes, _ = s.mfaRepo.CountUnusedRecoveryCodes(user.ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": status})
+}
+
+// EnrollMFA godoc
+// @Summary      Start MFA Enrollment
+// @Description  Membuat secret TOTP baru dan provisioning URI (untuk QR code). MFA belum aktif sampai dikonfirmasi lewat /auth/mfa/enable.
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  object{status=string,data=model.MFAEnrollResponse}
+// @Failure      409  {object}  map[string]string
+// @Router       /auth/mfa/enroll [post]
+func (s *mfaService) EnrollMFA(c *gin.Context) {
+	user, ok := s.currentUser(c)
+	if !ok {
+		return
+	}
+
+	existing, err := s.mfaRepo.FindByUserID(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if existing != nil && existing.Enabled {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "mfa is already enabled, disable it first to re-enroll"})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to generate mfa secret"})
+		return
+	}
+
+	mfa := &model.UserMFA{UserID: user.ID, Secret: secret}
+	if existing != nil {
+		mfa = existing
+		mfa.Secret = secret
+		mfa.LastUsedStep = 0
+	}
+	if err := s.mfaRepo.Save(mfa); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": model.MFAEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(s.mfaCfg.Issuer, user.Username, secret),
+	}})
+}
+
+// EnableMFA godoc
+// @Summary      Confirm MFA Enrollment
+// @Description  Mengaktifkan MFA dengan kode TOTP pertama. Mengembalikan recovery codes (sekali tampil) dan token baru yang sudah terverifikasi MFA.
+// @Tags         Auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.MFACodeRequest true "Kode TOTP"
+// @Success      200  {object}  object{status=string,data=model.MFAEnableResponse}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      423  {object}  object{status=string,message=string,retry_after=int}
+// @Failure      429  {object}  object{status=string,message=string,retry_after=int}
+// @Router       /auth/mfa/enable [post]
+func (s *mfaService) EnableMFA(c *gin.Context) {
+	user, ok := s.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req model.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	mfa, err := s.mfaRepo.FindByUserID(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if mfa == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "start enrollment via /auth/mfa/enroll first"})
+		return
+	}
+	if mfa.Enabled {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "mfa is already enabled"})
+		return
+	}
+
+	if !s.checkCode(c, user, mfa, req.Code) {
+		return
+	}
+
+	enabledAt := time.Now()
+	mfa.Enabled = true
+	mfa.EnabledAt = &enabledAt
+	if err := s.mfaRepo.Save(mfa); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	codes, err := s.issueRecoveryCodes(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to generate recovery codes"})
+		return
+	}
+
+	// Token baru (mfa_verified) tetap terikat ke sesi yang sedang dipakai
+	sessionID, err := uuid.Parse(c.GetString("sessionID"))
+	if err != nil {
+		session, err := startSession(c, s.sessionRepo, user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		sessionID = session.ID
+	}
+
+	tokens, err := issueLoginTokens(s.userRepo, user, true, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "MFA enabled successfully", "data": model.MFAEnableResponse{
+		RecoveryCodes: codes,
+		Tokens:        *tokens,
+	}})
+}
+
+// DisableMFA godoc
+// @Summary      Disable MFA
+// @Description  Menonaktifkan MFA (butuh kode TOTP). Tidak diizinkan untuk role yang wajib MFA.
+// @Tags         Auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.MFACodeRequest true "Kode TOTP"
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      423  {object}  object{status=string,message=string,retry_after=int}
+// @Failure      429  {object}  object{status=string,message=string,retry_after=int}
+// @Router       /auth/mfa/disable [post]
+func (s *mfaService) DisableMFA(c *gin.Context) {
+	user, ok := s.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if s.isRequiredForRole(c.GetString("role")) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "mfa is mandatory for your role"})
+		return
+	}
+
+	var req model.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	mfa, err := s.mfaRepo.FindByUserID(user.ID)
+	if err != nil || mfa == nil || !mfa.Enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "mfa is not enabled"})
+		return
+	}
+
+	if !s.checkCode(c, user, mfa, req.Code) {
+		return
+	}
+
+	if err := s.mfaRepo.Delete(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "MFA disabled successfully"})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Regenerate MFA Recovery Codes
+// @Description  Mengganti seluruh recovery code lama (butuh kode TOTP).
+// @Tags         Auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.MFACodeRequest true "Kode TOTP"
+// @Success      200  {object}  object{status=string,data=[]string}
+// @Failure      401  {object}  map[string]string
+// @Failure      423  {object}  object{status=string,message=string,retry_after=int}
+// @Failure      429  {object}  object{status=string,message=string,retry_after=int}
+// @Router       /auth/mfa/recovery-codes [post]
+func (s *mfaService) RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := s.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req model.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	mfa, err := s.mfaRepo.FindByUserID(user.ID)
+	if err != nil || mfa == nil || !mfa.Enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "mfa is not enabled"})
+		return
+	}
+
+	if !s.checkCode(c, user, mfa, req.Code) {
+		return
+	}
+
+	codes, err := s.issueRecoveryCodes(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to generate recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": codes})
+}
+
+// ResetUserMFA godoc
+// @Summary      Reset User MFA (Admin)
+// @Description  Menghapus enrollment MFA user sehingga user harus enroll ulang. Semua token dan sesi user ikut dicabut.
+// @Tags         Users
+// @Security     BearerAuth
+// @Param        id path string true "User UUID"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /users/{id}/mfa [delete]
+func (s *mfaService) ResetUserMFA(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid user id format"})
+		return
+	}
+
+	if _, err := s.userRepo.FindByID(userUUID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "user not found"})
+		return
+	}
+
+	if err := s.mfaRepo.Delete(userUUID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	// Token dan sesi lama mungkin dipegang pemilik perangkat yang hilang: semuanya dicabut
+	if err := s.userRepo.BumpSecurityVersion(userUUID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	s.accessCache.InvalidateUser(userUUID)
+
+	revoked, err := s.sessionRepo.RevokeAllExcept(userUUID, uuid.Nil, actorID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	for _, id := range revoked {
+		s.accessCache.InvalidateSession(id)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "User MFA reset successfully"})
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/app/utils"
+	"github.com/fitrinovs/achievement_system/config"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// oidcStateCookie menyimpan state/nonce/PKCE verifier (JWT ber-tanda tangan) selama redirect ke IdP
+const oidcStateCookie = "oidc_state"
+
+type OIDCService interface {
+	OIDCLogin(c *gin.Context)
+	OIDCCallback(c *gin.Context)
+}
+
+type oidcService struct {
+	provider     *utils.OIDCProvider
+	userRepo     repository.UserRepository
+	studentRepo  repository.StudentRepository
+	lecturerRepo repository.LecturerRepository
+	roleRepo     repository.RoleRepository
+	mfaRepo      repository.MFARepository
+	identityRepo repository.UserIdentityRepository
+	sessionRepo  repository.SessionRepository
+	provisioning repository.ProvisioningRepository
+	cfg          config.OIDCConfig
+	mfaCfg       config.MFAConfig
+	secureCookie bool
+}
+
+func NewOIDCService(
+	userRepo repository.UserRepository,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+	roleRepo repository.RoleRepository,
+	mfaRepo repository.MFARepository,
+	identityRepo repository.UserIdentityRepository,
+	sessionRepo repository.SessionRepository,
+	provisioning repository.ProvisioningRepository,
+	cfg config.OIDCConfig,
+	mfaCfg config.MFAConfig,
+	secureCookie bool,
+) OIDCService {
+	return &oidcService{
+		provider:     utils.NewOIDCProvider(cfg.IssuerURL, cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, cfg.Scopes),
+		userRepo:     userRepo,
+		studentRepo:  studentRepo,
+		lecturerRepo: lecturerRepo,
+		roleRepo:     roleRepo,
+		mfaRepo:      mfaRepo,
+		identityRepo: identityRepo,
+		sessionRepo:  sessionRepo,
+		provisioning: provisioning,
+		cfg:          cfg,
+		mfaCfg:       mfaCfg,
+		secureCookie: secureCookie,
+	}
+}
+
+// OIDCLogin godoc
+// @Summary      Start SSO Login (OIDC)
+// @Description  Memulai authorization code flow + PKCE ke identity provider kampus. Default redirect 302; tambahkan ?mode=json untuk menerima authorization_url (SPA).
+// @Tags         Auth
+// @Produce      json
+// @Param        mode query string false "json = kembalikan authorization_url alih-alih redirect"
+// @Success      200  {object}  object{status=string,data=model.OIDCAuthorizationResponse}
+// @Success      302
+// @Failure      404  {object}  map[string]string
+// @Failure      502  {object}  map[string]string
+// @Router       /auth/oidc/login [get]
+func (s *oidcService) OIDCLogin(c *gin.Context) {
+	if !s.cfg.Enabled {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "single sign-on is not enabled"})
+		return
+	}
+
+	state, errState := utils.RandomURLToken(24)
+	nonce, errNonce := utils.RandomURLToken(24)
+	verifier, errVerifier := utils.RandomURLToken(48)
+	if errState != nil || errNonce != nil || errVerifier != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to start sso login"})
+		return
+	}
+
+	authURL, err := s.provider.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"status": "error", "message": "identity provider is unavailable"})
+		return
+	}
+
+	ttl := time.Duration(s.cfg.StateMinutes) * time.Minute
+	stateToken, err := utils.GenerateOIDCStateToken(state, nonce, verifier, ttl)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to start sso login"})
+		return
+	}
+
+	// SameSite=Lax agar cookie tetap terkirim pada redirect GET dari IdP
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, stateToken, int(ttl.Seconds()), "/api/v1/auth/oidc", "", s.secureCookie, true)
+
+	if c.Query("mode") == "json" {
+		c.JSON(http.StatusOK, gin.H{"status": "success", "data": model.OIDCAuthorizationResponse{
+			AuthorizationURL: authURL,
+			ExpiresIn:        int(ttl.Seconds()),
+		}})
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback godoc
+// @Summary      SSO Callback (OIDC)
+// @Description  Dipanggil identity provider setelah login. Memverifikasi ID token, memetakan claim NIM/NIP/email ke user lokal (opsional membuat akun baru), lalu menerbitkan JWT seperti /auth/login.
+// @Tags         Auth
+// @Produce      json
+// @Param        code query string true "Authorization code"
+// @Param        state query string true "State"
+// @Success      200  {object}  model.LoginResponse "Atau model.MFAChallengeResponse jika MFA aktif"
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /auth/oidc/callback [get]
+func (s *oidcService) OIDCCallback(c *gin.Context) {
+	if !s.cfg.Enabled {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "single sign-on is not enabled"})
+		return
+	}
+
+	if idpErr := c.Query("error"); idpErr != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": fmt.Sprintf("identity provider returned an error: %s", idpErr)})
+		return
+	}
+
+	// Cookie state hanya sekali pakai
+	stateCookie, err := c.Cookie(oidcStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, "/api/v1/auth/oidc", "", s.secureCookie, true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "sso session not found or expired, please start again"})
+		return
+	}
+	stateClaims, err := utils.ValidateOIDCStateToken(stateCookie)
+	if err != nil || c.Query("state") == "" || c.Query("state") != stateClaims.State {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid sso state"})
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "authorization code is required"})
+		return
+	}
+
+	rawIDToken, err := s.provider.Exchange(c.Request.Context(), code, stateClaims.CodeVerifier)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "failed to exchange authorization code"})
+		return
+	}
+	claims, err := s.provider.VerifyIDToken(c.Request.Context(), rawIDToken, stateClaims.Nonce)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	user, status, err := s.resolveUser(claims)
+	if err != nil {
+		c.JSON(status, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	data, err := completeLogin(c, s.userRepo, s.mfaRepo, s.sessionRepo, s.mfaCfg, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	if s.cfg.PostLoginRedirectURL != "" {
+		c.Redirect(http.StatusFound, s.cfg.PostLoginRedirectURL+"#"+loginFragment(data).Encode())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": data})
+}
+
+// resolveUser mencari user lokal untuk identitas IdP dengan urutan:
+// identitas yang sudah tertaut (sub) -> NIM -> NIP -> email terverifikasi -> JIT provisioning.
+func (s *oidcService) resolveUser(claims jwt.MapClaims) (*model.User, int, error) {
+	subject := claimString(claims, "sub")
+	if subject == "" {
+		return nil, http.StatusUnauthorized, errors.New("id token has no subject")
+	}
+
+	if identity, err := s.identityRepo.FindBySubject(model.IdentityProviderOIDC, s.cfg.IssuerURL, subject); err == nil {
+		user, err := s.userRepo.FindByID(identity.UserID)
+		if err != nil {
+			return nil, http.StatusForbidden, errors.New("linked account is inactive or no longer exists")
+		}
+		if err := s.identityRepo.TouchLastLogin(identity.ID, time.Now()); err != nil {
+			fmt.Printf("Warning: failed to update identity last login for user %s: %v\n", user.ID, err)
+		}
+		return s.checkLoginAllowed(user)
+	}
+
+	nim := claimString(claims, s.cfg.NIMClaim)
+	nip := claimString(claims, s.cfg.NIPClaim)
+	email := claimString(claims, s.cfg.EmailClaim)
+
+	user, err := s.matchExistingUser(claims, nim, nip, email)
+	if err != nil {
+		return nil, http.StatusForbidden, err
+	}
+	if user == nil {
+		if !s.cfg.JITProvisioning {
+			return nil, http.StatusForbidden, errors.New("no local account is linked to this identity, please contact the administrator")
+		}
+		if user, err = s.provision(claims, nim, nip, email); err != nil {
+			return nil, http.StatusForbidden, err
+		}
+	}
+
+	identity := &model.UserIdentity{
+		UserID:   user.ID,
+		Provider: model.IdentityProviderOIDC,
+		Issuer:   s.cfg.IssuerURL,
+		Subject:  subject,
+		Email:    email,
+	}
+	now := time.Now()
+	identity.LastLoginAt = &now
+	if err := s.identityRepo.Create(identity); err != nil {
+		return nil, http.StatusInternalServerError, errors.New("failed to link identity")
+	}
+
+	return s.checkLoginAllowed(user)
+}
+
+func (s *oidcService) matchExistingUser(claims jwt.MapClaims, nim, nip, email string) (*model.User, error) {
+	if nim != "" {
+		if student, err := s.studentRepo.FindByStudentID(nim); err == nil {
+			return s.linkedUser(student.UserID)
+		}
+	}
+	if nip != "" {
+		if lecturer, err := s.lecturerRepo.FindByLecturerID(nip); err == nil {
+			return s.linkedUser(lecturer.UserID)
+		}
+	}
+	// Email hanya dipercaya bila IdP menyatakannya terverifikasi
+	if email != "" && claimBool(claims, "email_verified") {
+		if user, err := s.userRepo.FindByEmail(email); err == nil {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *oidcService) linkedUser(userID uuid.UUID) (*model.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("linked account is inactive or no longer exists")
+	}
+	return user, nil
+}
+
+// provision membuat User beserta profil Student (claim NIM) atau Lecturer (claim NIP)
+func (s *oidcService) provision(claims jwt.MapClaims, nim, nip, email string) (*model.User, error) {
+	if email == "" {
+		return nil, errors.New("cannot create an account: identity provider did not supply an email")
+	}
+
+	var roleName, username string
+	switch {
+	case nim != "":
+		roleName, username = model.RoleMahasiswa, nim
+	case nip != "":
+		roleName, username = model.RoleDosenWali, nip
+	default:
+		return nil, errors.New("cannot create an account: identity has neither NIM nor NIP")
+	}
+
+	role, err := s.roleRepo.FindByName(roleName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create an account: role %s not found", roleName)
+	}
+	if taken, err := s.userRepo.IsUsernameTaken(username, uuid.Nil); err != nil || taken {
+		return nil, errors.New("cannot create an account: username already taken")
+	}
+	if taken, err := s.userRepo.IsEmailTaken(email, uuid.Nil); err != nil || taken {
+		return nil, errors.New("cannot create an account: email already registered")
+	}
+
+	passwordHash, err := utils.UnusablePasswordHash()
+	if err != nil {
+		return nil, errors.New("failed to create account")
+	}
+
+	fullName := claimString(claims, s.cfg.NameClaim)
+	if fullName == "" {
+		fullName = username
+	}
+	user := &model.User{
+		Username:     username,
+		Email:        email,
+		FullName:     fullName,
+		PasswordHash: passwordHash,
+		RoleID:       role.ID,
+		IsActive:     true,
+	}
+	// User dan profil dibuat dalam satu transaksi: tidak ada user tanpa profil bila salah satunya gagal
+	record := model.ProvisionRecord{User: user}
+	if nim != "" {
+		record.Student = &model.Student{
+			NIM:          nim,
+			ProgramStudy: claimString(claims, s.cfg.ProgramStudyClaim),
+		}
+	} else {
+		record.Lecturer = &model.Lecturer{
+			LecturerID: nip,
+			Department: claimString(claims, s.cfg.DepartmentClaim),
+		}
+	}
+	if err := s.provisioning.SaveAll([]model.ProvisionRecord{record}); err != nil {
+		return nil, errors.New("failed to create account")
+	}
+
+	return s.userRepo.FindByID(user.ID)
+}
+
+func (s *oidcService) checkLoginAllowed(user *model.User) (*model.User, int, error) {
+	if user.IsServiceAccount {
+		return nil, http.StatusForbidden, errors.New("service accounts cannot sign in with sso")
+	}
+	if !user.IsActive {
+		return nil, http.StatusForbidden, errors.New("user is inactive")
+	}
+	return user, http.StatusOK, nil
+}
+
+// loginFragment mengubah hasil login menjadi parameter URL fragment untuk redirect ke frontend
+func loginFragment(data interface{}) url.Values {
+	values := url.Values{}
+	switch v := data.(type) {
+	case *model.LoginResponse:
+		values.Set("token", v.Token)
+		values.Set("refresh_token", v.RefreshToken)
+	case model.MFAChallengeResponse:
+		values.Set("mfa_required", "true")
+		values.Set("mfa_token", v.MFAToken)
+	}
+	return values
+}
+
+func claimString(claims jwt.MapClaims, name string) string {
+	if name == "" {
+		return ""
+	}
+	switch v := claims[name].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		// NIM/NIP kadang dikirim IdP sebagai angka
+		return fmt.Sprintf("%.0f", v)
+	}
+	return ""
+}
+
+func claimBool(claims jwt.MapClaims, name string) bool {
+	switch v := claims[name].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
@@ -0,0 +1,442 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/app/utils"
+	"github.com/fitrinovs/achievement_system/config"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const oidcTestClientID = "achievement-system"
+
+// oidcTestIdP adalah identity provider tiruan; token endpoint mengembalikan ID token dengan claim idToken
+type oidcTestIdP struct {
+	server  *httptest.Server
+	key     *rsa.PrivateKey
+	idToken jwt.MapClaims
+}
+
+func newOIDCTestIdP(t *testing.T) *oidcTestIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &oidcTestIdP{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "test-key",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.idToken)
+		token.Header["kid"] = "test-key"
+		signed, err := token.SignedString(idp.key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *oidcTestIdP) claims(nonce string, extra jwt.MapClaims) jwt.MapClaims {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"sub":   "subject-1",
+		"aud":   oidcTestClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": nonce,
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	return claims
+}
+
+// =================================================================
+// FAKE REPOSITORIES (hanya method yang dipakai alur OIDC)
+// =================================================================
+
+type oidcFakeUserRepo struct {
+	repository.UserRepository
+	users map[uuid.UUID]*model.User
+}
+
+func (r *oidcFakeUserRepo) FindByID(id uuid.UUID) (*model.User, error) {
+	if user, ok := r.users[id]; ok && user.IsActive {
+		return user, nil
+	}
+	return nil, errors.New("user not found")
+}
+
+func (r *oidcFakeUserRepo) FindByEmail(email string) (*model.User, error) {
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (r *oidcFakeUserRepo) isTaken(value string) bool {
+	for _, user := range r.users {
+		if strings.EqualFold(user.Username, value) || strings.EqualFold(user.Email, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *oidcFakeUserRepo) IsUsernameTaken(username string, _ uuid.UUID) (bool, error) {
+	return r.isTaken(username), nil
+}
+
+func (r *oidcFakeUserRepo) IsEmailTaken(email string, _ uuid.UUID) (bool, error) {
+	return r.isTaken(email), nil
+}
+
+func (r *oidcFakeUserRepo) GetUserPermissions(uuid.UUID) ([]string, error) {
+	return []string{"achievement:read"}, nil
+}
+
+type oidcFakeStudentRepo struct{ repository.StudentRepository }
+
+func (oidcFakeStudentRepo) FindByStudentID(string) (*model.Student, error) {
+	return nil, errors.New("student not found")
+}
+
+type oidcFakeLecturerRepo struct{ repository.LecturerRepository }
+
+func (oidcFakeLecturerRepo) FindByLecturerID(string) (*model.Lecturer, error) {
+	return nil, errors.New("lecturer not found")
+}
+
+type oidcFakeRoleRepo struct {
+	repository.RoleRepository
+	roles map[string]*model.Role
+}
+
+func (r *oidcFakeRoleRepo) FindByName(name string) (*model.Role, error) {
+	if role, ok := r.roles[name]; ok {
+		return role, nil
+	}
+	return nil, errors.New("role not found")
+}
+
+type oidcFakeMFARepo struct{ repository.MFARepository }
+
+func (oidcFakeMFARepo) FindByUserID(uuid.UUID) (*model.UserMFA, error) {
+	return nil, nil
+}
+
+type oidcFakeIdentityRepo struct {
+	repository.UserIdentityRepository
+	identities []*model.UserIdentity
+}
+
+func (r *oidcFakeIdentityRepo) FindBySubject(provider, issuer, subject string) (*model.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Issuer == issuer && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, errors.New("identity not found")
+}
+
+func (r *oidcFakeIdentityRepo) Create(identity *model.UserIdentity) error {
+	identity.ID = uuid.New()
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *oidcFakeIdentityRepo) TouchLastLogin(id uuid.UUID, at time.Time) error {
+	for _, identity := range r.identities {
+		if identity.ID == id {
+			identity.LastLoginAt = &at
+		}
+	}
+	return nil
+}
+
+type oidcFakeSessionRepo struct{ repository.SessionRepository }
+
+func (oidcFakeSessionRepo) Create(session *model.UserSession) error {
+	session.ID = uuid.New()
+	return nil
+}
+
+// oidcFakeProvisioningRepo menyimpan user hasil JIT provisioning ke oidcFakeUserRepo
+type oidcFakeProvisioningRepo struct {
+	users   *oidcFakeUserRepo
+	roles   *oidcFakeRoleRepo
+	records []model.ProvisionRecord
+}
+
+func (r *oidcFakeProvisioningRepo) SaveAll(records []model.ProvisionRecord) error {
+	for _, record := range records {
+		record.User.ID = uuid.New()
+		for _, role := range r.roles.roles {
+			if role.ID == record.User.RoleID {
+				record.User.Role = role
+			}
+		}
+		r.users.users[record.User.ID] = record.User
+	}
+	r.records = append(r.records, records...)
+	return nil
+}
+
+// =================================================================
+// HELPERS
+// =================================================================
+
+type oidcTestEnv struct {
+	idp          *oidcTestIdP
+	router       *gin.Engine
+	users        *oidcFakeUserRepo
+	identities   *oidcFakeIdentityRepo
+	provisioning *oidcFakeProvisioningRepo
+}
+
+func newOIDCTestEnv(t *testing.T, jitProvisioning bool) *oidcTestEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	idp := newOIDCTestIdP(t)
+	users := &oidcFakeUserRepo{users: map[uuid.UUID]*model.User{}}
+	roles := &oidcFakeRoleRepo{roles: map[string]*model.Role{
+		model.RoleMahasiswa: {ID: uuid.New(), Name: model.RoleMahasiswa},
+		model.RoleDosenWali: {ID: uuid.New(), Name: model.RoleDosenWali},
+	}}
+	identities := &oidcFakeIdentityRepo{}
+	provisioning := &oidcFakeProvisioningRepo{users: users, roles: roles}
+
+	svc := NewOIDCService(
+		users, oidcFakeStudentRepo{}, oidcFakeLecturerRepo{}, roles, oidcFakeMFARepo{},
+		identities, oidcFakeSessionRepo{}, provisioning,
+		config.OIDCConfig{
+			Enabled:           true,
+			IssuerURL:         idp.server.URL,
+			ClientID:          oidcTestClientID,
+			RedirectURL:       "http://localhost/api/v1/auth/oidc/callback",
+			StateMinutes:      10,
+			NIMClaim:          "nim",
+			NIPClaim:          "nip",
+			EmailClaim:        "email",
+			NameClaim:         "name",
+			ProgramStudyClaim: "program_study",
+			JITProvisioning:   jitProvisioning,
+		},
+		config.MFAConfig{},
+		false,
+	)
+
+	r := gin.New()
+	r.GET("/api/v1/auth/oidc/callback", svc.OIDCCallback)
+	return &oidcTestEnv{idp: idp, router: r, users: users, identities: identities, provisioning: provisioning}
+}
+
+// callback mensimulasikan redirect IdP: cookie state berisi cookieState/nonce, query membawa queryState
+func (env *oidcTestEnv) callback(t *testing.T, cookieState, queryState, nonce string) *httptest.ResponseRecorder {
+	t.Helper()
+	stateToken, err := utils.GenerateOIDCStateToken(cookieState, nonce, "verifier-1", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := url.Values{"code": {"code-1"}, "state": {queryState}}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/callback?"+query.Encode(), nil)
+	req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: stateToken})
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	return w
+}
+
+func decodeOIDCResponse(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid json response %q: %v", w.Body.String(), err)
+	}
+	return body
+}
+
+// =================================================================
+// TESTS
+// =================================================================
+
+func TestOIDCCallbackStateMismatch(t *testing.T) {
+	env := newOIDCTestEnv(t, true)
+	env.idp.idToken = env.idp.claims("nonce-1", nil)
+
+	w := env.callback(t, "state-1", "state-2", "nonce-1")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+	if msg := decodeOIDCResponse(t, w)["message"]; msg != "invalid sso state" {
+		t.Fatalf("message = %v", msg)
+	}
+}
+
+func TestOIDCCallbackMissingStateCookie(t *testing.T) {
+	env := newOIDCTestEnv(t, true)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/callback?code=code-1&state=state-1", nil)
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestOIDCCallbackNonceMismatch(t *testing.T) {
+	env := newOIDCTestEnv(t, true)
+	env.idp.idToken = env.idp.claims("nonce-from-another-login", jwt.MapClaims{"nim": "2101", "email": "a@kampus.ac.id"})
+
+	w := env.callback(t, "state-1", "state-1", "nonce-1")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusUnauthorized, w.Body.String())
+	}
+	if msg, _ := decodeOIDCResponse(t, w)["message"].(string); !strings.Contains(msg, "nonce") {
+		t.Fatalf("message = %q, want nonce error", msg)
+	}
+	if len(env.provisioning.records) != 0 {
+		t.Fatal("no account may be provisioned for a rejected id token")
+	}
+}
+
+func TestOIDCCallbackBadAudience(t *testing.T) {
+	env := newOIDCTestEnv(t, true)
+	env.idp.idToken = env.idp.claims("nonce-1", jwt.MapClaims{"aud": "another-client", "nim": "2101", "email": "a@kampus.ac.id"})
+
+	w := env.callback(t, "state-1", "state-1", "nonce-1")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusUnauthorized, w.Body.String())
+	}
+	if msg, _ := decodeOIDCResponse(t, w)["message"].(string); !strings.Contains(msg, "audience") {
+		t.Fatalf("message = %q, want audience error", msg)
+	}
+	if len(env.provisioning.records) != 0 {
+		t.Fatal("no account may be provisioned for a rejected id token")
+	}
+}
+
+func TestOIDCCallbackJITProvisioning(t *testing.T) {
+	env := newOIDCTestEnv(t, true)
+	env.idp.idToken = env.idp.claims("nonce-1", jwt.MapClaims{
+		"nim":           "2101001",
+		"email":         "budi@kampus.ac.id",
+		"name":          "Budi Santoso",
+		"program_study": "Informatika",
+	})
+
+	w := env.callback(t, "state-1", "state-1", "nonce-1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	if len(env.provisioning.records) != 1 {
+		t.Fatalf("provisioned %d records, want 1", len(env.provisioning.records))
+	}
+	record := env.provisioning.records[0]
+	if record.User.Username != "2101001" || record.User.Email != "budi@kampus.ac.id" || record.User.FullName != "Budi Santoso" {
+		t.Fatalf("provisioned user = %+v", record.User)
+	}
+	if record.User.Role == nil || record.User.Role.Name != model.RoleMahasiswa {
+		t.Fatalf("provisioned role = %+v, want %s", record.User.Role, model.RoleMahasiswa)
+	}
+	if record.Student == nil || record.Student.NIM != "2101001" || record.Student.ProgramStudy != "Informatika" {
+		t.Fatalf("provisioned student = %+v", record.Student)
+	}
+	if record.Lecturer != nil {
+		t.Fatal("student claim must not provision a lecturer profile")
+	}
+
+	if len(env.identities.identities) != 1 {
+		t.Fatalf("linked %d identities, want 1", len(env.identities.identities))
+	}
+	identity := env.identities.identities[0]
+	if identity.UserID != record.User.ID || identity.Subject != "subject-1" || identity.Issuer != env.idp.server.URL {
+		t.Fatalf("linked identity = %+v", identity)
+	}
+
+	data, _ := decodeOIDCResponse(t, w)["data"].(map[string]interface{})
+	if token, _ := data["token"].(string); token == "" {
+		t.Fatalf("response has no token: %s", w.Body.String())
+	}
+
+	// Login berikutnya memakai identitas yang sudah tertaut, tanpa provisioning ulang
+	w = env.callback(t, "state-2", "state-2", "nonce-1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("second login status = %d: %s", w.Code, w.Body.String())
+	}
+	if len(env.provisioning.records) != 1 || len(env.identities.identities) != 1 {
+		t.Fatal("second login must reuse the linked identity")
+	}
+}
+
+func TestOIDCCallbackJITProvisioningDisabled(t *testing.T) {
+	env := newOIDCTestEnv(t, false)
+	env.idp.idToken = env.idp.claims("nonce-1", jwt.MapClaims{"nim": "2101001", "email": "budi@kampus.ac.id"})
+
+	w := env.callback(t, "state-1", "state-1", "nonce-1")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+	if len(env.provisioning.records) != 0 || len(env.identities.identities) != 0 {
+		t.Fatal("nothing may be provisioned or linked when JIT provisioning is disabled")
+	}
+}
+
+func TestOIDCCallbackJITProvisioningRejectsTakenEmail(t *testing.T) {
+	env := newOIDCTestEnv(t, true)
+	existing := &model.User{ID: uuid.New(), Username: "budi", Email: "budi@kampus.ac.id", IsActive: true}
+	env.users.users[existing.ID] = existing
+	// email_verified tidak diisi: email tidak dipakai untuk menautkan akun yang sudah ada
+	env.idp.idToken = env.idp.claims("nonce-1", jwt.MapClaims{"nim": "2101001", "email": "budi@kampus.ac.id"})
+
+	w := env.callback(t, "state-1", "state-1", "nonce-1")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+	if len(env.provisioning.records) != 0 {
+		t.Fatal("no account may be provisioned with an email that is already registered")
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/fitrinovs/achievement_system/app/cache"
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PermissionService interface {
+	GetAllPermissions(c *gin.Context)
+	GetPermissionByID(c *gin.Context)
+	CreatePermission(c *gin.Context)
+	UpdatePermission(c *gin.Context)
+	DeletePermission(c *gin.Context)
+}
+
+type permissionService struct {
+	permissionRepo repository.PermissionRepository
+	accessCache    cache.AccessCache
+}
+
+func NewPermissionService(permissionRepo repository.PermissionRepository, accessCache cache.AccessCache) PermissionService {
+	return &permissionService{permissionRepo: permissionRepo, accessCache: accessCache}
+}
+
+// GetAllPermissions godoc
+// @Summary      Get All Permissions
+// @Tags         Permissions
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {array} model.Permission
+// @Router       /permissions [get]
+func (s *permissionService) GetAllPermissions(c *gin.Context) {
+	permissions, err := s.permissionRepo.FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": permissions})
+}
+
+// GetPermissionByID godoc
+// @Summary      Get Permission by ID
+// @Tags         Permissions
+// @Security     BearerAuth
+// @Param        id path string true "Permission UUID"
+// @Success      200 {object} model.Permission
+// @Failure      404 {object} map[string]string
+// @Router       /permissions/{id} [get]
+func (s *permissionService) GetPermissionByID(c *gin.Context) {
+	permUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid permission id format"})
+		return
+	}
+
+	permission, err := s.permissionRepo.FindByID(permUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "permission not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": permission})
+}
+
+// CreatePermission godoc
+// @Summary      Create Permission
+// @Tags         Permissions
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.PermissionCreateRequest true "Permission Data"
+// @Success      201 {object} model.Permission
+// @Failure      400 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /permissions [post]
+func (s *permissionService) CreatePermission(c *gin.Context) {
+	var req model.PermissionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	// Nama permission harus konsisten dengan resource:action yang dicek di route
+	if req.Name != strings.TrimSpace(req.Resource)+":"+strings.TrimSpace(req.Action) {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "permission name must be formatted as resource:action"})
+		return
+	}
+
+	if existing, _ := s.permissionRepo.FindByName(req.Name); existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "permission already exists"})
+		return
+	}
+
+	permission := model.Permission{
+		Name:        req.Name,
+		Resource:    strings.TrimSpace(req.Resource),
+		Action:      strings.TrimSpace(req.Action),
+		Description: req.Description,
+	}
+	if err := s.permissionRepo.Create(&permission); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": permission})
+}
+
+// UpdatePermission godoc
+// @Summary      Update Permission
+// @Description  Hanya deskripsi yang bisa diubah; nama permission dipakai sebagai kunci di route.
+// @Tags         Permissions
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Permission UUID"
+// @Param        request body model.PermissionUpdateRequest true "Permission Data"
+// @Success      200 {object} model.Permission
+// @Failure      404 {object} map[string]string
+// @Router       /permissions/{id} [put]
+func (s *permissionService) UpdatePermission(c *gin.Context) {
+	permUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid permission id format"})
+		return
+	}
+
+	var req model.PermissionUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	permission, err := s.permissionRepo.FindByID(permUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "permission not found"})
+		return
+	}
+
+	permission.Description = req.Description
+	if err := s.permissionRepo.Update(permission); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Permission updated successfully", "data": permission})
+}
+
+// DeletePermission godoc
+// @Summary      Delete Permission
+// @Description  Menghapus permission dan mencabutnya dari semua role. Permission katalog (dipakai route) tidak bisa dihapus.
+// @Tags         Permissions
+// @Security     BearerAuth
+// @Param        id path string true "Permission UUID"
+// @Success      200 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /permissions/{id} [delete]
+func (s *permissionService) DeletePermission(c *gin.Context) {
+	permUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid permission id format"})
+		return
+	}
+
+	permission, err := s.permissionRepo.FindByID(permUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "permission not found"})
+		return
+	}
+	// Menghapus permission katalog mengunci semua route yang memakainya
+	if model.IsCatalogPermission(permission.Name) {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "catalog permission " + permission.Name + " is used by routes and cannot be deleted"})
+		return
+	}
+
+	if err := s.permissionRepo.Delete(permUUID); err != nil {
+		if err.Error() == "permission not found" {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	// Permission bisa terpasang di role mana pun
+	s.accessCache.InvalidateAllRoles()
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Permission deleted successfully"})
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/config"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+// maxProfilePhotoSize membatasi foto profil, terlepas dari MAX_UPLOAD_SIZE yang lebih longgar
+const maxProfilePhotoSize int64 = 2 << 20
+
+// profilePhotoTypes: content type hasil sniffing -> ekstensi file yang disimpan
+var profilePhotoTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+const profilePhotoDir = "profile-photos"
+
+type ProfileService interface {
+	GetMyProfile(c *gin.Context)
+	UpdateMyProfile(c *gin.Context)
+	UploadMyPhoto(c *gin.Context)
+	DeleteMyPhoto(c *gin.Context)
+}
+
+type profileService struct {
+	userRepo     repository.UserRepository
+	studentRepo  repository.StudentRepository
+	lecturerRepo repository.LecturerRepository
+	uploadCfg    config.UploadConfig
+}
+
+func NewProfileService(
+	userRepo repository.UserRepository,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+	uploadCfg config.UploadConfig,
+) ProfileService {
+	return &profileService{
+		userRepo:     userRepo,
+		studentRepo:  studentRepo,
+		lecturerRepo: lecturerRepo,
+		uploadCfg:    uploadCfg,
+	}
+}
+
+// currentUser memuat user yang sedang login; response error sudah ditulis bila ok == false
+func (s *profileService) currentUser(c *gin.Context) (*model.User, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "invalid user session"})
+		return nil, false
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "user not found"})
+		return nil, false
+	}
+	return user, true
+}
+
+func profileKind(user *model.User) string {
+	if user.Role == nil {
+		return ""
+	}
+	return model.ProfileKindForRole(user.Role.Name)
+}
+
+func (s *profileService) buildProfile(user *model.User) model.SelfProfileResponse {
+	kind := profileKind(user)
+	profile := model.SelfProfileResponse{
+		ID:               user.ID,
+		Username:         user.Username,
+		FullName:         user.FullName,
+		Email:            user.Email,
+		ContactEmail:     user.ContactEmail,
+		Phone:            user.Phone,
+		PhotoURL:         user.PhotoURL,
+		Bio:              user.Bio,
+		SelfProfileRules: model.SelfProfileRulesFor(kind),
+	}
+	if user.Role != nil {
+		profile.Role = user.Role.Name
+	}
+
+	switch kind {
+	case model.ProfileKindStudent:
+		profile.Student, _ = s.studentRepo.FindByUserID(user.ID)
+	case model.ProfileKindLecturer:
+		profile.Lecturer, _ = s.lecturerRepo.FindByUserID(user.ID)
+	}
+	return profile
+}
+
+// GetMyProfile godoc
+// @Summary      Get My Profile
+// @Description  Profil user yang sedang login beserta daftar field yang boleh diubah sendiri (editable_fields) dan yang hanya bisa diubah admin (admin_only_fields).
+// @Tags         Me
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} model.SelfProfileResponse
+// @Failure      401 {object} map[string]string
+// @Router       /me [get]
+func (s *profileService) GetMyProfile(c *gin.Context) {
+	user, ok := s.currentUser(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": s.buildProfile(user)})
+}
+
+// UpdateMyProfile godoc
+// @Summary      Update My Profile
+// @Description  Mengubah contact_email, phone, dan bio milik sendiri. Field admin-only (mis. nim, program_study, advisor_id) ditolak 403; foto lewat POST /me/photo.
+// @Tags         Me
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.SelfProfileUpdateRequest true "Profile fields"
+// @Success      200 {object} model.SelfProfileResponse
+// @Failure      400 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Router       /me [put]
+func (s *profileService) UpdateMyProfile(c *gin.Context) {
+	user, ok := s.currentUser(c)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "failed to read request body"})
+		return
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "request body must be a JSON object"})
+		return
+	}
+
+	// Cek per field sebelum binding: field admin-only -> 403, field tak dikenal -> 400
+	rules := model.SelfProfileRulesFor(profileKind(user))
+	var adminOnly, unknown []string
+	for field := range raw {
+		switch {
+		case field == model.SelfFieldPhoto:
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "photo must be uploaded via POST /me/photo"})
+			return
+		case rules.IsAdminOnly(field):
+			adminOnly = append(adminOnly, field)
+		case !rules.CanEdit(field):
+			unknown = append(unknown, field)
+		}
+	}
+	if len(adminOnly) > 0 {
+		sort.Strings(adminOnly)
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  "error",
+			"message": "fields can only be changed by an administrator: " + strings.Join(adminOnly, ", "),
+			"fields":  adminOnly,
+		})
+		return
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "unknown fields: " + strings.Join(unknown, ", ")})
+		return
+	}
+
+	var req model.SelfProfileUpdateRequest
+	if err := binding.JSON.BindBody(body, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	req.Trim()
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	if err := s.userRepo.UpdateSelfProfile(user.ID, req.Fields()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	updated, ok := s.currentUser(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": s.buildProfile(updated)})
+}
+
+// UploadMyPhoto godoc
+// @Summary      Upload My Profile Photo
+// @Description  Mengganti foto profil sendiri (JPEG, PNG, atau WebP, maks. 2 MB). Foto lama dihapus.
+// @Tags         Me
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        photo formData file true "Profile photo"
+// @Success      200 {object} model.SelfProfileResponse
+// @Failure      400 {object} map[string]string
+// @Failure      413 {object} map[string]string
+// @Router       /me/photo [post]
+func (s *profileService) UploadMyPhoto(c *gin.Context) {
+	user, ok := s.currentUser(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("photo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "File is required in 'photo' form field"})
+		return
+	}
+	limit := maxProfilePhotoSize
+	if s.uploadCfg.MaxSize > 0 && s.uploadCfg.MaxSize < limit {
+		limit = s.uploadCfg.MaxSize
+	}
+	if file.Size > limit {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"status": "error", "message": fmt.Sprintf("photo exceeds the maximum size of %d bytes", limit)})
+		return
+	}
+
+	// Tipe file ditentukan dari isinya, bukan dari ekstensi atau header yang dikirim client
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "failed to read photo"})
+		return
+	}
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(src, head)
+	src.Close()
+	ext, allowed := profilePhotoTypes[http.DetectContentType(head[:n])]
+	if !allowed {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "photo must be a JPEG, PNG, or WebP image"})
+		return
+	}
+
+	dir := filepath.Join(s.uploadCfg.Path, profilePhotoDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to create upload directory: " + err.Error()})
+		return
+	}
+	filename := user.ID.String() + "-" + uuid.New().String() + ext
+	if err := c.SaveUploadedFile(file, filepath.Join(dir, filename)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to save file: " + err.Error()})
+		return
+	}
+
+	photoURL := "/uploads/" + profilePhotoDir + "/" + filename
+	if err := s.userRepo.UpdateSelfProfile(user.ID, map[string]interface{}{"photo_url": photoURL}); err != nil {
+		os.Remove(filepath.Join(dir, filename))
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	s.removePhotoFile(user.PhotoURL)
+
+	user.PhotoURL = photoURL
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": s.buildProfile(user)})
+}
+
+// DeleteMyPhoto godoc
+// @Summary      Delete My Profile Photo
+// @Tags         Me
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} model.SelfProfileResponse
+// @Router       /me/photo [delete]
+func (s *profileService) DeleteMyPhoto(c *gin.Context) {
+	user, ok := s.currentUser(c)
+	if !ok {
+		return
+	}
+	if user.PhotoURL != "" {
+		if err := s.userRepo.UpdateSelfProfile(user.ID, map[string]interface{}{"photo_url": ""}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		s.removePhotoFile(user.PhotoURL)
+		user.PhotoURL = ""
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": s.buildProfile(user)})
+}
+
+// removePhotoFile menghapus file foto lama; hanya file di folder foto profil yang disentuh
+func (s *profileService) removePhotoFile(photoURL string) {
+	prefix := "/uploads/" + profilePhotoDir + "/"
+	if !strings.HasPrefix(photoURL, prefix) {
+		return
+	}
+	name := filepath.Base(strings.TrimPrefix(photoURL, prefix))
+	if name == "." || name == string(filepath.Separator) {
+		return
+	}
+	os.Remove(filepath.Join(s.uploadCfg.Path, profilePhotoDir, name))
+}
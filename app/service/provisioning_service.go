@@ -0,0 +1,211 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type ProvisioningService interface {
+	ProvisionUser(c *gin.Context)
+}
+
+type provisioningService struct {
+	userRepo         repository.UserRepository
+	roleRepo         repository.RoleRepository
+	studentRepo      repository.StudentRepository
+	lecturerRepo     repository.LecturerRepository
+	provisioningRepo repository.ProvisioningRepository
+	units            academicUnitLinker
+}
+
+func NewProvisioningService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+	provisioningRepo repository.ProvisioningRepository,
+	unitRepo repository.AcademicUnitRepository,
+) ProvisioningService {
+	return &provisioningService{
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		studentRepo:      studentRepo,
+		lecturerRepo:     lecturerRepo,
+		provisioningRepo: provisioningRepo,
+		units:            academicUnitLinker{unitRepo: unitRepo},
+	}
+}
+
+// ProvisionUser godoc
+// @Summary      Provision User with Profile (Admin)
+// @Description  Membuat user beserta profil mahasiswa/dosen dalam satu transaksi. Role Mahasiswa wajib mengisi "student",
+// @Description  role Dosen Wali wajib mengisi "lecturer", role lain tidak boleh mengisi profil. Gagal di tengah = tidak ada yang tersimpan.
+// @Tags         Users
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.ProvisionUserRequest true "User + Profile"
+// @Success      201 {object} model.ProvisionUserResponse
+// @Failure      400 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /users/provision [post]
+func (s *provisioningService) ProvisionUser(c *gin.Context) {
+	var req model.ProvisionUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	// 1. Role menentukan jenis profil yang wajib ada
+	roleID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid role id format"})
+		return
+	}
+	role, err := s.roleRepo.FindByID(roleID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "role not found"})
+		return
+	}
+	if msg := profileMismatch(role.Name, req.Student != nil, req.Lecturer != nil); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": msg})
+		return
+	}
+
+	// 2. Keunikan akun (case-insensitive, termasuk user non-aktif)
+	if status, msg := s.checkAccountFree(req.Username, req.Email); status != 0 {
+		c.JSON(status, gin.H{"status": "error", "message": msg})
+		return
+	}
+
+	record := model.ProvisionRecord{}
+
+	// 3. Profil: NIM/NIP unik, dosen wali harus ada
+	if req.Student != nil {
+		if _, err := s.studentRepo.FindByStudentID(req.Student.NIM); err == nil {
+			c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "nim already registered"})
+			return
+		}
+		student := &model.Student{
+			NIM:          req.Student.NIM,
+			ProgramStudy: req.Student.ProgramStudy,
+			AcademicYear: req.Student.AcademicYear,
+		}
+		// Tanpa study_program_id, teks program_study ditautkan saat disimpan
+		if req.Student.StudyProgramID != "" && !s.units.linkStudent(c, student, req.Student.StudyProgramID) {
+			return
+		}
+		if req.Student.AdvisorID != "" {
+			advisorID, err := uuid.Parse(req.Student.AdvisorID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid advisor id format"})
+				return
+			}
+			if _, err := s.lecturerRepo.FindByID(advisorID); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "advisor not found"})
+				return
+			}
+			student.AdvisorID = &advisorID
+		}
+		record.Student = student
+	}
+	if req.Lecturer != nil {
+		if _, err := s.lecturerRepo.FindByLecturerID(req.Lecturer.LecturerID); err == nil {
+			c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "lecturer id (NIP) already registered"})
+			return
+		}
+		record.Lecturer = &model.Lecturer{
+			LecturerID: req.Lecturer.LecturerID,
+			Department: req.Lecturer.Department,
+		}
+		if req.Lecturer.DepartmentID != "" && !s.units.linkLecturer(c, record.Lecturer, req.Lecturer.DepartmentID) {
+			return
+		}
+	}
+
+	// 4. User
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to encrypt password"})
+		return
+	}
+	record.User = &model.User{
+		Username:     req.Username,
+		Email:        req.Email,
+		FullName:     req.FullName,
+		PasswordHash: string(hashedPassword),
+		RoleID:       role.ID,
+		IsActive:     true,
+	}
+
+	// 5. Satu transaksi: user + profil
+	if err := s.provisioningRepo.SaveAll([]model.ProvisionRecord{record}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	response := model.ProvisionUserResponse{User: record.User, Student: record.Student, Lecturer: record.Lecturer}
+	if user, err := s.userRepo.FindByID(record.User.ID); err == nil {
+		response.User = user
+	}
+	if record.Student != nil {
+		if student, err := s.studentRepo.FindByID(record.Student.ID); err == nil {
+			response.Student = student
+		}
+	}
+	if record.Lecturer != nil {
+		if lecturer, err := s.lecturerRepo.FindByID(record.Lecturer.ID); err == nil {
+			response.Lecturer = lecturer
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": response})
+}
+
+func (s *provisioningService) checkAccountFree(username, email string) (int, string) {
+	usernameTaken, err := s.userRepo.IsUsernameTaken(username, uuid.Nil)
+	if err != nil {
+		return http.StatusInternalServerError, err.Error()
+	}
+	if usernameTaken {
+		return http.StatusConflict, "username already taken"
+	}
+
+	emailTaken, err := s.userRepo.IsEmailTaken(email, uuid.Nil)
+	if err != nil {
+		return http.StatusInternalServerError, err.Error()
+	}
+	if emailTaken {
+		return http.StatusConflict, "email already registered"
+	}
+	return 0, ""
+}
+
+// profileMismatch mengembalikan pesan error bila profil yang dikirim tidak sesuai role
+func profileMismatch(roleName string, hasStudent, hasLecturer bool) string {
+	if hasStudent && hasLecturer {
+		return "a user can have either a student or a lecturer profile, not both"
+	}
+
+	switch model.ProfileKindForRole(roleName) {
+	case model.ProfileKindStudent:
+		if !hasStudent {
+			return fmt.Sprintf("role %s requires a student profile", roleName)
+		}
+	case model.ProfileKindLecturer:
+		if !hasLecturer {
+			return fmt.Sprintf("role %s requires a lecturer profile", roleName)
+		}
+	default:
+		if hasStudent || hasLecturer {
+			return fmt.Sprintf("role %s does not take a student or lecturer profile", roleName)
+		}
+	}
+	return ""
+}
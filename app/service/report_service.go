@@ -6,8 +6,10 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"sort"
 
 	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/policy"
 	"github.com/fitrinovs/achievement_system/app/repository"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -34,15 +36,21 @@ type ReportService interface {
 type reportService struct {
 	achievementRepo repository.AchievementRepository
 	studentRepo     repository.StudentRepository
+	enforcer        policy.Enforcer
+	periodRepo      repository.AcademicPeriodRepository
 }
 
 func NewReportService(
 	achievementRepo repository.AchievementRepository,
 	studentRepo repository.StudentRepository,
+	enforcer policy.Enforcer,
+	periodRepo repository.AcademicPeriodRepository,
 ) ReportService {
 	return &reportService{
 		achievementRepo: achievementRepo,
 		studentRepo:     studentRepo,
+		enforcer:        enforcer,
+		periodRepo:      periodRepo,
 	}
 }
 
@@ -67,7 +75,9 @@ func (s *reportService) mergeAchievement(ctx context.Context, pgRef *model.Achie
 		SubmittedAt:   pgRef.SubmittedAt,
 		VerifiedAt:    pgRef.VerifiedAt,
 		VerifiedBy:    pgRef.VerifiedBy,
+		VerifiedOnBehalfOf: pgRef.VerifiedOnBehalfOf,
 		RejectionNote: pgRef.RejectionNote,
+		AcademicPeriodID: pgRef.AcademicPeriodID,
 		
 		MongoAchievementID: pgRef.MongoAchievementID,
 		AchievementType:    mongoAch.AchievementType,
@@ -103,7 +113,22 @@ func (s *reportService) GetReportByStudentID(c *gin.Context) {
 		return
 	}
 
-	achievementsRef, err := s.achievementRepo.FindReferencesByStudentID(studentID)
+	_, ok, err := s.enforcer.Authorize(c, policy.ActionReadStudentReport, policy.StudentResource(student))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "forbidden: you are not allowed to view this student's report"})
+		return
+	}
+
+	period, ok := periodQuery(c, s.periodRepo)
+	if !ok {
+		return
+	}
+
+	achievementsRef, err := s.achievementRepo.FindReferencesByStudentID(studentID, periodID(period))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to retrieve achievements references"})
 		return
@@ -128,6 +153,7 @@ func (s *reportService) GetReportByStudentID(c *gin.Context) {
 		"data": gin.H{
 			"student_id":            student.ID,
 			"student_name":          student.NIM, 
+			"academic_period":       period,
 			"total_verified_points": totalPoints,
 			"verified_achievements": verifiedAchievements,
 		},
@@ -138,24 +164,154 @@ func (s *reportService) GetReportByStudentID(c *gin.Context) {
 // IMPLEMENTASI BARU UNTUK MENGHILANGKAN ERROR ROUTER
 // =================================================================
 
+// topStudentLimit: jumlah mahasiswa di daftar top_students statistik global
+const topStudentLimit = 10
+
+// unattributedPeriod: label by_period untuk prestasi yang tanggal kegiatannya di luar periode akademik mana pun
+const unattributedPeriod = "unattributed"
+
 // @Summary Get Global Achievement Statistics
-// @Description Endpoint placeholder untuk menampilkan statistik prestasi global.
+// @Description Statistik prestasi terverifikasi: total, per tipe, per tingkat kompetisi, per periode akademik, dan top mahasiswa.
 // @Tags Reports
 // @Security BearerAuth
-// @Success 501 {object} object{status=string,message=string}
+// @Param period_id query string false "UUID periode akademik, atau active untuk periode aktif"
+// @Success 200 {object} object{status=string,data=model.AchievementStatisticsResponse}
+// @Failure 400 {object} object{status=string,message=string}
+// @Failure 404 {object} object{status=string,message=string}
+// @Failure 500 {object} object{status=string,message=string}
 // @Router /reports/statistics [get]
 func (s *reportService) GetAchievementStatistics(c *gin.Context) {
-	// Pemasangan sementara (Stub) agar router tidak error.
-	c.JSON(http.StatusNotImplemented, gin.H{"status": "info", "message": "Endpoint GetAchievementStatistics belum diimplementasikan."})
+	period, ok := periodQuery(c, s.periodRepo)
+	if !ok {
+		return
+	}
+
+	refs, err := s.achievementRepo.FindReferences(model.StatusVerified, periodID(period))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch achievements: " + err.Error()})
+		return
+	}
+
+	byType := map[string]int{}
+	byLevel := map[string]int{}
+	// by_period dikelompokkan per AcademicPeriodID (bukan per nama) agar periode bernama sama tidak tergabung
+	byPeriod := map[uuid.UUID]int{}
+	periods := map[uuid.UUID]*model.AcademicPeriod{}
+	unattributed := 0
+	points := map[uuid.UUID]int{}
+	students := map[uuid.UUID]*model.Student{}
+	total := 0
+
+	for i := range refs {
+		ref := &refs[i]
+		merged, err := s.mergeAchievement(c.Request.Context(), ref)
+		if err != nil {
+			continue // Lewati data yang rusak
+		}
+		total++
+		byType[merged.AchievementType]++
+		if level, ok := merged.Details["competitionLevel"].(string); ok && level != "" {
+			byLevel[level]++
+		}
+
+		if ref.AcademicPeriodID != nil {
+			byPeriod[*ref.AcademicPeriodID]++
+			if ref.AcademicPeriod != nil {
+				periods[*ref.AcademicPeriodID] = ref.AcademicPeriod
+			}
+		} else {
+			unattributed++
+		}
+
+		points[ref.StudentID] += merged.Points
+		if ref.Student != nil {
+			students[ref.StudentID] = ref.Student
+		}
+	}
+
+	// Periode diurutkan kronologis; prestasi tanpa periode di akhir
+	periodIDs := make([]uuid.UUID, 0, len(byPeriod))
+	for id := range byPeriod {
+		periodIDs = append(periodIDs, id)
+	}
+	sort.Slice(periodIDs, func(i, j int) bool {
+		pi, pj := periods[periodIDs[i]], periods[periodIDs[j]]
+		if pi != nil && pj != nil {
+			return pi.StartDate.Before(pj.StartDate)
+		}
+		if (pi == nil) != (pj == nil) {
+			return pi != nil
+		}
+		return periodIDs[i].String() < periodIDs[j].String()
+	})
+	byPeriodStats := make([]model.StatisticsByGroup, 0, len(periodIDs)+1)
+	for _, id := range periodIDs {
+		name := id.String()
+		if p := periods[id]; p != nil {
+			name = p.Name
+		}
+		byPeriodStats = append(byPeriodStats, model.StatisticsByGroup{Group: name, Count: byPeriod[id]})
+	}
+	if unattributed > 0 {
+		byPeriodStats = append(byPeriodStats, model.StatisticsByGroup{Group: unattributedPeriod, Count: unattributed})
+	}
+
+	topStudents := make([]model.TopStudentDetail, 0, len(points))
+	for studentID, sum := range points {
+		detail := model.TopStudentDetail{TotalPoints: sum}
+		if student := students[studentID]; student != nil {
+			detail.NIM = student.NIM
+			detail.FullName = student.User.FullName
+		}
+		topStudents = append(topStudents, detail)
+	}
+	sort.Slice(topStudents, func(i, j int) bool {
+		if topStudents[i].TotalPoints != topStudents[j].TotalPoints {
+			return topStudents[i].TotalPoints > topStudents[j].TotalPoints
+		}
+		return topStudents[i].NIM < topStudents[j].NIM
+	})
+	if len(topStudents) > topStudentLimit {
+		topStudents = topStudents[:topStudentLimit]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": model.AchievementStatisticsResponse{
+			TotalAchievementCount: total,
+			ByType:                countsByGroup(byType),
+			ByLevel:               countsByGroup(byLevel),
+			ByPeriod:              byPeriodStats,
+			TopStudents:           topStudents,
+		},
+	})
+}
+
+// countsByGroup mengubah hitungan map menjadi slice terurut (terbanyak dulu, lalu nama grup)
+func countsByGroup(counts map[string]int) []model.StatisticsByGroup {
+	stats := make([]model.StatisticsByGroup, 0, len(counts))
+	for group, count := range counts {
+		stats = append(stats, model.StatisticsByGroup{Group: group, Count: count})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Group < stats[j].Group
+	})
+	return stats
 }
 
-// @Summary Get Student Achievement Report (List)
-// @Description Endpoint placeholder untuk mendapatkan daftar laporan prestasi mahasiswa (Mungkin sama dengan GetReportByStudentID jika ID dihilangkan).
+// @Summary Get Student Achievement Report
+// @Description Laporan prestasi terverifikasi milik satu mahasiswa. Hanya dapat diakses oleh mahasiswa tersebut, dosen walinya, dosen satu department, atau admin.
 // @Tags Reports
 // @Security BearerAuth
-// @Success 501 {object} object{status=string,message=string}
-// @Router /reports/student-report [get]
+// @Param id path string true "Student UUID"
+// @Param period_id query string false "UUID periode akademik, atau active untuk periode aktif"
+// @Success 200 {object} object{status=string,data=object}
+// @Failure 403 {object} object{status=string,message=string}
+// @Failure 404 {object} object{status=string,message=string}
+// @Router /reports/student/{id} [get]
 func (s *reportService) GetStudentAchievementReport(c *gin.Context) {
-	// Pemasangan sementara (Stub) agar router tidak error.
-	c.JSON(http.StatusNotImplemented, gin.H{"status": "info", "message": "Endpoint GetStudentAchievementReport belum diimplementasikan."})
+	s.GetReportByStudentID(c)
 }
\ No newline at end of file
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type statsFakeAchievementRepo struct {
+	repository.AchievementRepository
+	refs    []model.AchievementReference
+	content map[string]*model.Achievement
+}
+
+func (r *statsFakeAchievementRepo) FindReferences(status model.AchievementStatus, periodID *uuid.UUID) ([]model.AchievementReference, error) {
+	var refs []model.AchievementReference
+	for _, ref := range r.refs {
+		if status != "" && ref.Status != status {
+			continue
+		}
+		if periodID != nil && (ref.AcademicPeriodID == nil || *ref.AcademicPeriodID != *periodID) {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func (r *statsFakeAchievementRepo) FindMongoByID(_ context.Context, objectID string) (*model.Achievement, error) {
+	return r.content[objectID], nil
+}
+
+func TestGetAchievementStatisticsGroupsByAttributedPeriod(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// by_period mengikuti AcademicPeriodID hasil atribusi, diurutkan kronologis
+	odd := &model.AcademicPeriod{ID: uuid.New(), Name: "Ganjil 2024/2025", StartDate: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)}
+	even := &model.AcademicPeriod{ID: uuid.New(), Name: "Genap 2024/2025", StartDate: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)}
+	budi := &model.Student{ID: uuid.New(), NIM: "2101", User: model.User{FullName: "Budi"}}
+	sari := &model.Student{ID: uuid.New(), NIM: "2102", User: model.User{FullName: "Sari"}}
+
+	ref := func(mongoID string, student *model.Student, period *model.AcademicPeriod, status model.AchievementStatus) model.AchievementReference {
+		r := model.AchievementReference{ID: uuid.New(), StudentID: student.ID, Student: student, MongoAchievementID: mongoID, Status: status}
+		if period != nil {
+			r.AcademicPeriodID, r.AcademicPeriod = &period.ID, period
+		}
+		return r
+	}
+	repo := &statsFakeAchievementRepo{
+		refs: []model.AchievementReference{
+			ref("a", budi, even, model.StatusVerified),
+			ref("b", budi, odd, model.StatusVerified),
+			ref("c", sari, odd, model.StatusVerified),
+			ref("d", sari, nil, model.StatusVerified),
+			ref("e", sari, odd, model.StatusSubmitted),
+		},
+		content: map[string]*model.Achievement{
+			"a": {AchievementType: "competition", Points: 50, Details: map[string]interface{}{"competitionLevel": "national"}},
+			"b": {AchievementType: "competition", Points: 30, Details: map[string]interface{}{"competitionLevel": "regional"}},
+			"c": {AchievementType: "publication", Points: 20},
+			"d": {AchievementType: "competition", Points: 5, Details: map[string]interface{}{"competitionLevel": "national"}},
+			"e": {AchievementType: "publication", Points: 100},
+		},
+	}
+	svc := NewReportService(repo, nil, nil, nil)
+
+	r := gin.New()
+	r.GET("/reports/statistics", svc.GetAchievementStatistics)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reports/statistics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Data model.AchievementStatisticsResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	stats := body.Data
+
+	if stats.TotalAchievementCount != 4 {
+		t.Errorf("total = %d, want 4 (submitted excluded)", stats.TotalAchievementCount)
+	}
+	wantPeriods := []model.StatisticsByGroup{{Group: odd.Name, Count: 2}, {Group: even.Name, Count: 1}, {Group: unattributedPeriod, Count: 1}}
+	if len(stats.ByPeriod) != len(wantPeriods) {
+		t.Fatalf("by_period = %+v, want %+v", stats.ByPeriod, wantPeriods)
+	}
+	for i, want := range wantPeriods {
+		if stats.ByPeriod[i] != want {
+			t.Errorf("by_period[%d] = %+v, want %+v", i, stats.ByPeriod[i], want)
+		}
+	}
+	if len(stats.ByLevel) != 2 || stats.ByLevel[0] != (model.StatisticsByGroup{Group: "national", Count: 2}) {
+		t.Errorf("by_level = %+v", stats.ByLevel)
+	}
+	if len(stats.TopStudents) != 2 || stats.TopStudents[0].NIM != budi.NIM || stats.TopStudents[0].TotalPoints != 80 {
+		t.Errorf("top_students = %+v", stats.TopStudents)
+	}
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fitrinovs/achievement_system/app/cache"
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RoleService interface {
+	GetAllRoles(c *gin.Context)
+	GetRoleByID(c *gin.Context)
+	CreateRole(c *gin.Context)
+	UpdateRole(c *gin.Context)
+	DeleteRole(c *gin.Context)
+	AssignRolePermissions(c *gin.Context)
+	GetRoleUsers(c *gin.Context)
+}
+
+type roleService struct {
+	roleRepo       repository.RoleRepository
+	permissionRepo repository.PermissionRepository
+	userRepo       repository.UserRepository
+	accessCache    cache.AccessCache
+}
+
+func NewRoleService(
+	roleRepo repository.RoleRepository,
+	permissionRepo repository.PermissionRepository,
+	userRepo repository.UserRepository,
+	accessCache cache.AccessCache,
+) RoleService {
+	return &roleService{
+		roleRepo:       roleRepo,
+		permissionRepo: permissionRepo,
+		userRepo:       userRepo,
+		accessCache:    accessCache,
+	}
+}
+
+// rolePermissionGuard: permission yang tidak boleh dicabut dari Admin agar role tetap bisa diperbaiki
+const rolePermissionGuard = "role:manage"
+
+// resolvePermissionIDs mem-parse dan memastikan semua permission ID ada di database
+func (s *roleService) resolvePermissionIDs(rawIDs []string) ([]uuid.UUID, []model.Permission, error) {
+	ids, err := parseUUIDs(rawIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permissions, err := s.permissionRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(permissions) != len(ids) {
+		return nil, nil, fmt.Errorf("one or more permissions not found")
+	}
+	return ids, permissions, nil
+}
+
+// parseUUIDs mengubah daftar string menjadi UUID unik (duplikat diabaikan)
+func parseUUIDs(rawIDs []string) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool, len(rawIDs))
+	ids := make([]uuid.UUID, 0, len(rawIDs))
+	for _, raw := range rawIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id format: %s", raw)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// GetAllRoles godoc
+// @Summary      Get All Roles
+// @Tags         Roles
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {array} model.Role
+// @Router       /roles [get]
+func (s *roleService) GetAllRoles(c *gin.Context) {
+	roles, err := s.roleRepo.FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": roles})
+}
+
+// GetRoleByID godoc
+// @Summary      Get Role by ID
+// @Tags         Roles
+// @Security     BearerAuth
+// @Param        id path string true "Role UUID"
+// @Success      200 {object} model.Role
+// @Failure      404 {object} map[string]string
+// @Router       /roles/{id} [get]
+func (s *roleService) GetRoleByID(c *gin.Context) {
+	roleUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid role id format"})
+		return
+	}
+
+	role, err := s.roleRepo.FindByID(roleUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "role not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": role})
+}
+
+// CreateRole godoc
+// @Summary      Create Role
+// @Tags         Roles
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.RoleCreateRequest true "Role Data"
+// @Success      201 {object} model.Role
+// @Failure      400 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /roles [post]
+func (s *roleService) CreateRole(c *gin.Context) {
+	var req model.RoleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	if existing, _ := s.roleRepo.FindByName(req.Name); existing != nil || model.IsDefaultRoleName(req.Name) {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "role name already exists"})
+		return
+	}
+
+	permissionIDs, _, err := s.resolvePermissionIDs(req.PermissionIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	role := model.Role{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.roleRepo.Create(&role, permissionIDs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	result, _ := s.roleRepo.FindByID(role.ID)
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": result})
+}
+
+// UpdateRole godoc
+// @Summary      Update Role
+// @Description  Role default (Admin, Mahasiswa, Dosen Wali) tidak bisa diganti namanya.
+// @Tags         Roles
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Role UUID"
+// @Param        request body model.RoleUpdateRequest true "Role Data"
+// @Success      200 {object} model.Role
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /roles/{id} [put]
+func (s *roleService) UpdateRole(c *gin.Context) {
+	roleUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid role id format"})
+		return
+	}
+
+	var req model.RoleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	role, err := s.roleRepo.FindByID(roleUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "role not found"})
+		return
+	}
+
+	if req.Name != "" && req.Name != role.Name {
+		if model.IsDefaultRoleName(role.Name) {
+			c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "default role " + role.Name + " cannot be renamed"})
+			return
+		}
+		if existing, _ := s.roleRepo.FindByName(req.Name); existing != nil || model.IsDefaultRoleName(req.Name) {
+			c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "role name already exists"})
+			return
+		}
+		role.Name = req.Name
+	}
+	if req.Description != "" {
+		role.Description = req.Description
+	}
+
+	if err := s.roleRepo.Update(role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	s.accessCache.InvalidateRole(role.ID)
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Role updated successfully", "data": role})
+}
+
+// DeleteRole godoc
+// @Summary      Delete Role
+// @Description  Menghapus role. Ditolak jika role default atau masih ada user yang memakai role tersebut.
+// @Tags         Roles
+// @Security     BearerAuth
+// @Param        id path string true "Role UUID"
+// @Success      200 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /roles/{id} [delete]
+func (s *roleService) DeleteRole(c *gin.Context) {
+	roleUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid role id format"})
+		return
+	}
+
+	role, err := s.roleRepo.FindByID(roleUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "role not found"})
+		return
+	}
+	if model.IsDefaultRoleName(role.Name) {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "default role " + role.Name + " cannot be deleted"})
+		return
+	}
+
+	users, err := s.userRepo.FindByRoleID(roleUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if len(users) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": fmt.Sprintf("role is still assigned to %d user(s)", len(users))})
+		return
+	}
+
+	if err := s.roleRepo.Delete(roleUUID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	s.accessCache.InvalidateRole(roleUUID)
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Role deleted successfully"})
+}
+
+// AssignRolePermissions godoc
+// @Summary      Assign Role Permissions
+// @Description  Mengganti seluruh permission milik role dengan daftar yang dikirim. role:manage tidak bisa dicabut dari Admin.
+// @Tags         Roles
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Role UUID"
+// @Param        request body model.RoleAssignPermissionsRequest true "Permission IDs"
+// @Success      200 {object} model.Role
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /roles/{id}/permissions [put]
+func (s *roleService) AssignRolePermissions(c *gin.Context) {
+	roleUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid role id format"})
+		return
+	}
+
+	var req model.RoleAssignPermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	existing, err := s.roleRepo.FindByID(roleUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "role not found"})
+		return
+	}
+
+	permissionIDs, permissions, err := s.resolvePermissionIDs(req.PermissionIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	// Tanpa role:manage di Admin, tidak ada lagi yang bisa memperbaiki konfigurasi role
+	if strings.EqualFold(existing.Name, model.RoleAdmin) && !hasPermissionNamed(permissions, rolePermissionGuard) {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": rolePermissionGuard + " cannot be removed from the " + model.RoleAdmin + " role"})
+		return
+	}
+
+	if err := s.roleRepo.AssignPermissions(roleUUID, permissionIDs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	s.accessCache.InvalidateRole(roleUUID)
+
+	role, _ := s.roleRepo.FindByID(roleUUID)
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Role permissions updated successfully", "data": role})
+}
+
+func hasPermissionNamed(permissions []model.Permission, name string) bool {
+	for _, permission := range permissions {
+		if permission.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRoleUsers godoc
+// @Summary      List Users Holding a Role
+// @Tags         Roles
+// @Security     BearerAuth
+// @Param        id path string true "Role UUID"
+// @Success      200 {array} model.User
+// @Failure      404 {object} map[string]string
+// @Router       /roles/{id}/users [get]
+func (s *roleService) GetRoleUsers(c *gin.Context) {
+	roleUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid role id format"})
+		return
+	}
+
+	if _, err := s.roleRepo.FindByID(roleUUID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "role not found"})
+		return
+	}
+
+	users, err := s.userRepo.FindByRoleID(roleUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if users == nil {
+		users = []*model.User{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": users})
+}
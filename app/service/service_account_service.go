@@ -0,0 +1,299 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/cache"
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/app/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ServiceAccountService interface {
+	GetServiceAccounts(c *gin.Context)
+	CreateServiceAccount(c *gin.Context)
+	GetAPIKeys(c *gin.Context)
+	CreateAPIKey(c *gin.Context)
+	RevokeAPIKey(c *gin.Context)
+}
+
+type serviceAccountService struct {
+	userRepo    repository.UserRepository
+	apiKeyRepo  repository.APIKeyRepository
+	accessCache cache.AccessCache
+}
+
+func NewServiceAccountService(
+	userRepo repository.UserRepository,
+	apiKeyRepo repository.APIKeyRepository,
+	accessCache cache.AccessCache,
+) ServiceAccountService {
+	return &serviceAccountService{
+		userRepo:    userRepo,
+		apiKeyRepo:  apiKeyRepo,
+		accessCache: accessCache,
+	}
+}
+
+// GetServiceAccounts godoc
+// @Summary      List Service Accounts
+// @Description  Daftar akun mesin untuk integrasi (website fakultas, sinkronisasi SIAKAD, dll)
+// @Tags         Service Accounts
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {array} model.User
+// @Router       /service-accounts [get]
+func (s *serviceAccountService) GetServiceAccounts(c *gin.Context) {
+	accounts, err := s.userRepo.FindServiceAccounts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": accounts})
+}
+
+// CreateServiceAccount godoc
+// @Summary      Create Service Account
+// @Description  Membuat akun mesin tanpa password yang bisa dipakai login. Akses diberikan lewat API key.
+// @Tags         Service Accounts
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.ServiceAccountCreateRequest true "Service Account Data"
+// @Success      201 {object} model.User
+// @Failure      400 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /service-accounts [post]
+func (s *serviceAccountService) CreateServiceAccount(c *gin.Context) {
+	var req model.ServiceAccountCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	roleID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid role id format"})
+		return
+	}
+	roleAccess, err := s.accessCache.RoleAccess(roleID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "role not found"})
+		return
+	}
+	// Key yang bocor tidak boleh memberi akses administrator penuh
+	if strings.EqualFold(roleAccess.Name, model.RoleAdmin) {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "service accounts cannot be assigned the admin role"})
+		return
+	}
+
+	email := req.Email
+	if email == "" {
+		email = model.NormalizeIdentifier(req.Username) + "@service-account.local"
+	}
+
+	usernameTaken, err := s.userRepo.IsUsernameTaken(req.Username, uuid.Nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if usernameTaken {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "username already taken"})
+		return
+	}
+	emailTaken, err := s.userRepo.IsEmailTaken(email, uuid.Nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if emailTaken {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "email already registered"})
+		return
+	}
+
+	// Password acak yang tidak pernah diketahui siapa pun (kolom password_hash wajib terisi)
+	passwordHash, err := utils.UnusablePasswordHash()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to create service account"})
+		return
+	}
+
+	account := &model.User{
+		Username:         req.Username,
+		Email:            email,
+		FullName:         req.FullName,
+		PasswordHash:     passwordHash,
+		RoleID:           roleID,
+		IsActive:         true,
+		IsServiceAccount: true,
+	}
+	if err := s.userRepo.Create(account); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": account})
+}
+
+// GetAPIKeys godoc
+// @Summary      List API Keys
+// @Description  Daftar API key milik service account beserta waktu dan IP pemakaian terakhir (tanpa nilai key)
+// @Tags         Service Accounts
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Service Account UUID"
+// @Success      200 {array} model.APIKey
+// @Failure      404 {object} map[string]string
+// @Router       /service-accounts/{id}/keys [get]
+func (s *serviceAccountService) GetAPIKeys(c *gin.Context) {
+	account, ok := s.findServiceAccount(c)
+	if !ok {
+		return
+	}
+
+	keys, err := s.apiKeyRepo.FindByServiceAccountID(account.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": keys})
+}
+
+// CreateAPIKey godoc
+// @Summary      Create API Key
+// @Description  Menerbitkan API key ber-scope untuk service account. Nilai key hanya ditampilkan sekali; server hanya menyimpan hash-nya. Scope harus termasuk permission role service account.
+// @Tags         Service Accounts
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Service Account UUID"
+// @Param        request body model.APIKeyCreateRequest true "API Key Data"
+// @Success      201 {object} model.APIKeyCreateResponse
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /service-accounts/{id}/keys [post]
+func (s *serviceAccountService) CreateAPIKey(c *gin.Context) {
+	account, ok := s.findServiceAccount(c)
+	if !ok {
+		return
+	}
+
+	actorID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "invalid user session"})
+		return
+	}
+
+	var req model.APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	roleAccess, err := s.accessCache.RoleAccess(account.RoleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to resolve service account role"})
+		return
+	}
+	granted := make(map[string]bool, len(roleAccess.Permissions))
+	for _, p := range roleAccess.Permissions {
+		granted[p] = true
+	}
+	for _, scope := range req.Scopes {
+		if !model.IsCatalogPermission(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": fmt.Sprintf("unknown scope: %s", scope)})
+			return
+		}
+		if !granted[scope] {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": fmt.Sprintf("scope %s is not granted to the service account role", scope)})
+			return
+		}
+	}
+	for _, entry := range req.AllowedIPs {
+		if !utils.ValidIPOrCIDR(entry) {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": fmt.Sprintf("invalid ip or cidr: %s", entry)})
+			return
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "expires_at must be in the future"})
+		return
+	}
+
+	plainKey, prefix, keyHash, err := utils.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to generate api key"})
+		return
+	}
+
+	key := model.APIKey{
+		ServiceAccountID: account.ID,
+		Name:             req.Name,
+		Prefix:           prefix,
+		KeyHash:          keyHash,
+		Scopes:           req.Scopes,
+		AllowedIPs:       req.AllowedIPs,
+		ExpiresAt:        req.ExpiresAt,
+		CreatedBy:        actorID,
+	}
+	if err := s.apiKeyRepo.Create(&key); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": model.APIKeyCreateResponse{
+		Key:    plainKey,
+		APIKey: key,
+	}})
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke API Key
+// @Tags         Service Accounts
+// @Security     BearerAuth
+// @Param        id path string true "Service Account UUID"
+// @Param        keyId path string true "API Key UUID"
+// @Success      200 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /service-accounts/{id}/keys/{keyId} [delete]
+func (s *serviceAccountService) RevokeAPIKey(c *gin.Context) {
+	account, ok := s.findServiceAccount(c)
+	if !ok {
+		return
+	}
+
+	keyID, err := uuid.Parse(c.Param("keyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid api key id format"})
+		return
+	}
+
+	if err := s.apiKeyRepo.Revoke(keyID, account.ID, time.Now()); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "api key revoked"})
+}
+
+func (s *serviceAccountService) findServiceAccount(c *gin.Context) (*model.User, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid service account id format"})
+		return nil, false
+	}
+
+	account, err := s.userRepo.FindByID(id)
+	if err != nil || !account.IsServiceAccount {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "service account not found"})
+		return nil, false
+	}
+	return account, true
+}
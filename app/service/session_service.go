@@ -0,0 +1,220 @@
+package service
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/cache"
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SessionService interface {
+	// Self-service
+	GetMySessions(c *gin.Context)
+	RevokeMySession(c *gin.Context)
+	RevokeMyOtherSessions(c *gin.Context)
+
+	// Admin (manajemen user)
+	GetUserSessions(c *gin.Context)
+	RevokeUserSession(c *gin.Context)
+	RevokeAllUserSessions(c *gin.Context)
+}
+
+type sessionService struct {
+	sessionRepo repository.SessionRepository
+	userRepo    repository.UserRepository
+	accessCache cache.AccessCache
+}
+
+func NewSessionService(sessionRepo repository.SessionRepository, userRepo repository.UserRepository, accessCache cache.AccessCache) SessionService {
+	return &sessionService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		accessCache: accessCache,
+	}
+}
+
+// GetMySessions godoc
+// @Summary      List My Sessions
+// @Description  Daftar sesi login aktif milik user (user agent, IP, waktu dibuat, terakhir aktif). Sesi yang dipakai request ini ditandai current=true.
+// @Tags         Auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {array} model.UserSessionResponse
+// @Router       /auth/sessions [get]
+func (s *sessionService) GetMySessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	s.respondSessions(c, userID)
+}
+
+// RevokeMySession godoc
+// @Summary      Revoke My Session
+// @Description  Mencabut satu sesi login milik user (mis. perangkat yang hilang). Token sesi tersebut langsung tidak berlaku.
+// @Tags         Auth
+// @Security     BearerAuth
+// @Param        id path string true "Session UUID"
+// @Success      200 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /auth/sessions/{id} [delete]
+func (s *sessionService) RevokeMySession(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	s.revokeOne(c, userID, c.Param("id"), userID)
+}
+
+// RevokeMyOtherSessions godoc
+// @Summary      Revoke My Other Sessions
+// @Description  Mencabut semua sesi login milik user kecuali sesi yang sedang dipakai
+// @Tags         Auth
+// @Security     BearerAuth
+// @Success      200 {object} object{status=string,message=string,revoked=int}
+// @Router       /auth/sessions [delete]
+func (s *sessionService) RevokeMyOtherSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	currentID, err := uuid.Parse(c.GetString("sessionID"))
+	if err != nil {
+		currentID = uuid.Nil
+	}
+	s.revokeAll(c, userID, currentID, userID)
+}
+
+// GetUserSessions godoc
+// @Summary      List User Sessions (Admin)
+// @Tags         Users
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "User UUID"
+// @Success      200 {array} model.UserSessionResponse
+// @Failure      404 {object} map[string]string
+// @Router       /users/{id}/sessions [get]
+func (s *sessionService) GetUserSessions(c *gin.Context) {
+	userID, ok := s.targetUserID(c)
+	if !ok {
+		return
+	}
+	s.respondSessions(c, userID)
+}
+
+// RevokeUserSession godoc
+// @Summary      Revoke User Session (Admin)
+// @Tags         Users
+// @Security     BearerAuth
+// @Param        id path string true "User UUID"
+// @Param        sessionId path string true "Session UUID"
+// @Success      200 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /users/{id}/sessions/{sessionId} [delete]
+func (s *sessionService) RevokeUserSession(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	userID, ok := s.targetUserID(c)
+	if !ok {
+		return
+	}
+	s.revokeOne(c, userID, c.Param("sessionId"), actorID)
+}
+
+// RevokeAllUserSessions godoc
+// @Summary      Revoke All User Sessions (Admin)
+// @Description  Memaksa user logout dari semua perangkat
+// @Tags         Users
+// @Security     BearerAuth
+// @Param        id path string true "User UUID"
+// @Success      200 {object} object{status=string,message=string,revoked=int}
+// @Failure      404 {object} map[string]string
+// @Router       /users/{id}/sessions [delete]
+func (s *sessionService) RevokeAllUserSessions(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	userID, ok := s.targetUserID(c)
+	if !ok {
+		return
+	}
+	s.revokeAll(c, userID, uuid.Nil, actorID)
+}
+
+func (s *sessionService) respondSessions(c *gin.Context, userID uuid.UUID) {
+	sessions, err := s.sessionRepo.FindActiveByUserID(userID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	currentID := c.GetString("sessionID")
+	result := make([]model.UserSessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, model.UserSessionResponse{
+			UserSession: session,
+			Current:     session.ID.String() == currentID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": result})
+}
+
+func (s *sessionService) revokeOne(c *gin.Context, userID uuid.UUID, rawSessionID string, actorID uuid.UUID) {
+	sessionID, err := uuid.Parse(rawSessionID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid session id format"})
+		return
+	}
+
+	if err := s.sessionRepo.Revoke(sessionID, userID, actorID, time.Now()); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	s.accessCache.InvalidateSession(sessionID)
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "session revoked"})
+}
+
+func (s *sessionService) revokeAll(c *gin.Context, userID, keepID, actorID uuid.UUID) {
+	revoked, err := s.sessionRepo.RevokeAllExcept(userID, keepID, actorID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	for _, id := range revoked {
+		s.accessCache.InvalidateSession(id)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "sessions revoked", "revoked": len(revoked)})
+}
+
+func (s *sessionService) targetUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid user id format"})
+		return uuid.Nil, false
+	}
+	if _, err := s.accessCache.UserState(userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "user not found"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "invalid user session"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -1,10 +1,15 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/policy"
 	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/app/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -20,7 +25,10 @@ type StudentService interface {
 	UpdateStudent(c *gin.Context)
 	DeleteStudent(c *gin.Context)
 
-	AssignAdvisor(c *gin.Context)
+	// Status studi (active, on_leave, graduated, dropped_out)
+	ChangeStudentStatus(c *gin.Context)
+	GetStudentStatusHistory(c *gin.Context)
+
 	GetAchievementsByStudentID(c *gin.Context)
 }
 
@@ -33,6 +41,9 @@ type studentService struct {
 	userRepo        repository.UserRepository
 	lecturerRepo    repository.LecturerRepository
 	achievementRepo repository.AchievementRepository // Mengambil AchievementRepository
+	enforcer        policy.Enforcer
+	units           academicUnitLinker
+	periodRepo      repository.AcademicPeriodRepository
 }
 
 func NewStudentService(
@@ -40,12 +51,18 @@ func NewStudentService(
 	userRepo repository.UserRepository,
 	lecturerRepo repository.LecturerRepository,
 	achievementRepo repository.AchievementRepository,
+	enforcer policy.Enforcer,
+	unitRepo repository.AcademicUnitRepository,
+	periodRepo repository.AcademicPeriodRepository,
 ) StudentService {
 	return &studentService{
 		studentRepo:     studentRepo,
 		userRepo:        userRepo,
 		lecturerRepo:    lecturerRepo,
 		achievementRepo: achievementRepo,
+		enforcer:        enforcer,
+		units:           academicUnitLinker{unitRepo: unitRepo},
+		periodRepo:      periodRepo,
 	}
 }
 
@@ -90,6 +107,11 @@ func (s *studentService) CreateStudent(c *gin.Context) {
 		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "student already exists"})
 		return
 	}
+	// Satu user hanya boleh punya satu jenis profil
+	if _, err := s.lecturerRepo.FindByUserID(userUUID); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "user already has a lecturer profile"})
+		return
+	}
 
 	student := model.Student{
 		UserID:       userUUID,
@@ -97,6 +119,9 @@ func (s *studentService) CreateStudent(c *gin.Context) {
 		ProgramStudy: req.ProgramStudy,
 		AcademicYear: req.AcademicYear,
 	}
+	if !s.units.linkStudent(c, &student, req.StudyProgramID) {
+		return
+	}
 
 	if err := s.studentRepo.Create(&student); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
@@ -114,16 +139,29 @@ func (s *studentService) CreateStudent(c *gin.Context) {
 // @Summary Get All Students
 // @Tags Students
 // @Security BearerAuth
-// @Success 200 {array} model.Student
+// @Param page query int false "Nomor halaman (default 1)"
+// @Param limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Param cursor query string false "Cursor dari meta.next_cursor (keyset pagination, mengabaikan page)"
+// @Param q query string false "Cari nama, NIM, atau email"
+// @Param sort query string false "nim | name | program_study | academic_year | created_at (awali - untuk descending)"
+// @Param program_study query string false "Program studi"
+// @Param study_program_id query string false "UUID program studi, atau none untuk yang belum terpetakan"
+// @Param academic_year query string false "Angkatan"
+// @Param advisor_id query string false "UUID dosen wali, atau none untuk yang belum punya"
+// @Param status query string false "Status studi: active, on_leave, graduated, dropped_out"
+// @Param active query string false "true | false | all"
+// @Success 200 {object} utils.Response{data=[]model.Student,meta=model.PageMeta}
+// @Failure 400 {object} map[string]string
 // @Router /students [get]
 func (s *studentService) GetAllStudents(c *gin.Context) {
-	students, err := s.studentRepo.FindAll()
+	query, err := utils.ParseListQuery(c, "program_study", "study_program_id", "academic_year", "advisor_id", "status", "active")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "data": students})
+	students, meta, err := s.studentRepo.List(query)
+	respondList(c, "students retrieved", students, meta, err)
 }
 
 //
@@ -135,6 +173,7 @@ func (s *studentService) GetAllStudents(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Student UUID"
 // @Success 200 {object} model.Student
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /students/{id} [get]
 func (s *studentService) GetStudentByID(c *gin.Context) {
@@ -150,6 +189,16 @@ func (s *studentService) GetStudentByID(c *gin.Context) {
 		return
 	}
 
+	_, ok, err := s.enforcer.Authorize(c, policy.ActionReadStudent, policy.StudentResource(student))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "forbidden: you are not allowed to access this student"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"status": "success", "data": student})
 }
 
@@ -183,8 +232,11 @@ func (s *studentService) UpdateStudent(c *gin.Context) {
 		return
 	}
 
-	if req.ProgramStudy != "" {
+	if req.ProgramStudy != "" || req.StudyProgramID != "" {
 		student.ProgramStudy = req.ProgramStudy
+		if !s.units.linkStudent(c, student, req.StudyProgramID) {
+			return
+		}
 	}
 	if req.AcademicYear != "" {
 		student.AcademicYear = req.AcademicYear
@@ -200,77 +252,151 @@ func (s *studentService) UpdateStudent(c *gin.Context) {
 
 //
 // =======================
-// DELETE STUDENT
+// STUDENT STATUS
 // =======================
-// @Summary Delete Student
+// @Summary Change Student Status
+// @Description Memindahkan status studi mahasiswa (active, on_leave, graduated, dropped_out) dan mencatat riwayatnya.
+// @Description Lulus bersifat final; mahasiswa keluar hanya bisa diaktifkan kembali. effective_date kosong = hari ini.
+// @Description Setelah masa tenggang (STUDENT_SUBMISSION_GRACE_DAYS), mahasiswa non-aktif tidak bisa lagi mengubah atau mengajukan prestasi.
 // @Tags Students
 // @Security BearerAuth
 // @Param id path string true "Student UUID"
-// @Success 200 {object} map[string]string
-// @Router /students/{id} [delete]
-func (s *studentService) DeleteStudent(c *gin.Context) {
+// @Param request body model.StudentStatusRequest true "Status Baru"
+// @Success 200 {object} model.Student
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /students/{id}/status [put]
+func (s *studentService) ChangeStudentStatus(c *gin.Context) {
 	studentUUID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid student id format"})
 		return
 	}
 
-	if err := s.studentRepo.Delete(studentUUID); err != nil {
+	var req model.StudentStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	effective := today
+	if req.EffectiveDate != "" {
+		effective, err = time.Parse("2006-01-02", req.EffectiveDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "effective_date must be formatted as YYYY-MM-DD"})
+			return
+		}
+		if effective.After(today) {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "effective_date cannot be in the future"})
+			return
+		}
+	}
+
+	student, err := s.studentRepo.FindByID(studentUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "student not found"})
+		return
+	}
+
+	current := student.CurrentStatus()
+	if !model.CanTransitionStudentStatus(current, req.Status) {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": fmt.Sprintf("cannot change student status from %s to %s", current, req.Status)})
+		return
+	}
+	if student.StatusSince != nil && effective.Before(*student.StatusSince) {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "effective_date is before the current status took effect"})
+		return
+	}
+
+	change := &model.StudentStatusChange{
+		StudentID:     student.ID,
+		FromStatus:    current,
+		ToStatus:      req.Status,
+		EffectiveDate: effective,
+		Reason:        req.Reason,
+	}
+	if userID, err := uuid.Parse(c.GetString("userID")); err == nil {
+		change.ChangedBy = &userID
+	}
+
+	if err := s.studentRepo.ChangeStatus(student, change); err != nil {
+		if errors.Is(err, repository.ErrStudentStatusChanged) {
+			c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "student status was changed by another request; reload and retry"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "student deleted"})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": student})
 }
 
-//
-// =======================
-// ASSIGN ADVISOR
-// =======================
-// @Summary Assign Advisor
+// @Summary Get Student Status History
+// @Description Riwayat perpindahan status studi mahasiswa (terbaru lebih dulu).
 // @Tags Students
 // @Security BearerAuth
 // @Param id path string true "Student UUID"
-// @Param request body object{advisor_id=string} true "Advisor UUID"
-// @Success 200 {object} map[string]string
-// @Router /students/{id}/advisor [put]
-func (s *studentService) AssignAdvisor(c *gin.Context) {
+// @Success 200 {array} model.StudentStatusChange
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /students/{id}/status-history [get]
+func (s *studentService) GetStudentStatusHistory(c *gin.Context) {
 	studentUUID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid student id format"})
 		return
 	}
 
-	var req struct {
-		AdvisorID string `json:"advisor_id" binding:"required"`
-	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+	student, err := s.studentRepo.FindByID(studentUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "student not found"})
 		return
 	}
-
-	advisorUUID, err := uuid.Parse(req.AdvisorID)
+	_, ok, err := s.enforcer.Authorize(c, policy.ActionReadStudent, policy.StudentResource(student))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid advisor id format"})
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "forbidden: you are not allowed to access this student"})
 		return
 	}
 
-	if _, err := s.lecturerRepo.FindByID(advisorUUID); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "advisor not found"})
+	history, err := s.studentRepo.FindStatusHistory(student.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
 		return
 	}
 
-	if _, err := s.studentRepo.FindByID(studentUUID); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "student not found"})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": history})
+}
+
+//
+// =======================
+// DELETE STUDENT
+// =======================
+// @Summary Delete Student
+// @Tags Students
+// @Security BearerAuth
+// @Param id path string true "Student UUID"
+// @Success 200 {object} map[string]string
+// @Router /students/{id} [delete]
+func (s *studentService) DeleteStudent(c *gin.Context) {
+	studentUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid student id format"})
 		return
 	}
 
-	if err := s.studentRepo.AssignAdvisor(studentUUID, advisorUUID); err != nil {
+	if err := s.studentRepo.Delete(studentUUID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "advisor assigned"})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "student deleted"})
 }
 
 //
@@ -282,7 +408,9 @@ func (s *studentService) AssignAdvisor(c *gin.Context) {
 // @Tags Students
 // @Security BearerAuth
 // @Param id path string true "Student UUID"
+// @Param period_id query string false "UUID periode akademik, atau active untuk periode aktif"
 // @Success 200 {array} model.AchievementReference
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /students/{id}/achievements [get]
@@ -293,15 +421,30 @@ func (s *studentService) GetAchievementsByStudentID(c *gin.Context) {
 		return
 	}
 
-	if _, err := s.studentRepo.FindByID(studentUUID); err != nil {
+	student, err := s.studentRepo.FindByID(studentUUID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "student not found"})
 		return
 	}
 
+	_, ok, err := s.enforcer.Authorize(c, policy.ActionReadAchievement, policy.StudentResource(student))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "forbidden: you are not allowed to access this student's achievements"})
+		return
+	}
+
 	// PERBAIKAN UTAMA: Mengganti FindByStudentID yang tidak ada
 	// dengan FindReferencesByStudentID yang kita definisikan di repository.
 	// Outputnya adalah []model.AchievementReference (data PGSQL).
-	achievements, err := s.achievementRepo.FindReferencesByStudentID(studentUUID)
+	period, ok := periodQuery(c, s.periodRepo)
+	if !ok {
+		return
+	}
+	achievements, err := s.achievementRepo.FindReferencesByStudentID(studentUUID, periodID(period))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
 		return
@@ -1,13 +1,18 @@
 package service
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
+	"github.com/fitrinovs/achievement_system/app/cache"
 	"github.com/fitrinovs/achievement_system/app/model"
 	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/fitrinovs/achievement_system/app/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type UserService interface {
@@ -19,15 +24,36 @@ type UserService interface {
 	// TAMBAHAN:
 	GetAllUsers(c *gin.Context)
 	UpdateUserRole(c *gin.Context)
+	UnlockUser(c *gin.Context)
+	ResetUserPassword(c *gin.Context)
+	DeactivateUser(c *gin.Context)
+	ReactivateUser(c *gin.Context)
 }
 
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo         repository.UserRepository
+	roleRepo         repository.RoleRepository
+	loginAttemptRepo repository.LoginAttemptRepository
+	accessCache      cache.AccessCache
+	lecturerRepo     repository.LecturerRepository
+	studentRepo      repository.StudentRepository
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
+func NewUserService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	loginAttemptRepo repository.LoginAttemptRepository,
+	accessCache cache.AccessCache,
+	lecturerRepo repository.LecturerRepository,
+	studentRepo repository.StudentRepository,
+) UserService {
 	return &userService{
-		userRepo: userRepo,
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		accessCache:      accessCache,
+		lecturerRepo:     lecturerRepo,
+		studentRepo:      studentRepo,
 	}
 }
 
@@ -45,16 +71,24 @@ func (s *userService) CreateUser(c *gin.Context) {
 		return
 	}
 
-	// 1. Cek Username Duplikat
-	existingUser, _ := s.userRepo.FindByUsername(req.Username)
-	if existingUser != nil {
+	// 1. Cek Username Duplikat (case-insensitive, termasuk user non-aktif)
+	usernameTaken, err := s.userRepo.IsUsernameTaken(req.Username, uuid.Nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if usernameTaken {
 		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "username already taken"})
 		return
 	}
 
-	// 2. Cek Email Duplikat
-	existingEmail, _ := s.userRepo.FindByEmail(req.Email)
-	if existingEmail != nil {
+	// 2. Cek Email Duplikat (case-insensitive, termasuk user non-aktif)
+	emailTaken, err := s.userRepo.IsEmailTaken(req.Email, uuid.Nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if emailTaken {
 		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "email already registered"})
 		return
 	}
@@ -96,30 +130,51 @@ func (s *userService) CreateUser(c *gin.Context) {
 // TAMBAHAN: GetAllUsers godoc
 // ===================================
 // @Summary      Get All Users
-// @Description  Mendapatkan daftar semua user yang aktif
+// @Description  Mendapatkan daftar user per halaman. Default hanya user aktif (active=all untuk semua).
 // @Tags         Users
 // @Security     BearerAuth
 // @Produce      json
-// @Success      200 {array} model.User
+// @Param        page query int false "Nomor halaman (default 1)"
+// @Param        limit query int false "Jumlah per halaman (default 20, maks 100)"
+// @Param        cursor query string false "Cursor dari meta.next_cursor (keyset pagination, mengabaikan page)"
+// @Param        q query string false "Cari nama, username, atau email"
+// @Param        sort query string false "name | username | email | role | created_at (awali - untuk descending)"
+// @Param        role query string false "Nama role atau UUID role"
+// @Param        active query string false "true | false | all (default true)"
+// @Success      200 {object} utils.Response{data=[]model.User,meta=model.PageMeta}
+// @Failure      400 {object} map[string]string
 // @Router       /users [get]
 func (s *userService) GetAllUsers(c *gin.Context) {
-	users, err := s.userRepo.FindAll()
+	query, err := utils.ParseListQuery(c, "role", "active")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
 		return
 	}
+	// Perilaku lama: daftar user hanya berisi user aktif
+	if _, ok := query.Filters["active"]; !ok {
+		query.Filters["active"] = "true"
+	}
+
+	users, meta, err := s.userRepo.List(query)
+	respondList(c, "users retrieved", users, meta, err)
+}
 
-	// Cek jika list kosong
-	if len(users) == 0 {
-		c.JSON(http.StatusOK, gin.H{"status": "success", "data": []model.User{}})
+// respondList menulis hasil List repository dalam envelope utils.Response; query tidak valid menjadi 400
+func respondList(c *gin.Context, message string, data interface{}, meta model.PageMeta, err error) {
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, repository.ErrInvalidListQuery) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"status": "error", "message": err.Error()})
 		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{"status": "success", "data": users})
+	utils.PaginatedResponse(c, message, data, meta)
 }
 
 // GetUserByID godoc
 // @Summary      Get User by ID
+// @Description  User non-aktif tetap bisa dilihat admin; user yang sudah dihapus tidak.
 // @Tags         Users
 // @Security     BearerAuth
 // @Param        id path string true "User UUID"
@@ -132,7 +187,7 @@ func (s *userService) GetUserByID(c *gin.Context) {
 		return
 	}
 
-	user, err := s.userRepo.FindByID(userUUID)
+	user, err := s.userRepo.FindByIDIncludingInactive(userUUID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "user not found"})
 		return
@@ -186,6 +241,15 @@ func (s *userService) UpdateUser(c *gin.Context) {
 		user.FullName = req.FullName
 	}
 	if req.Email != "" {
+		emailTaken, err := s.userRepo.IsEmailTaken(req.Email, user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		if emailTaken {
+			c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "email already registered"})
+			return
+		}
 		user.Email = req.Email
 	}
 
@@ -201,7 +265,7 @@ func (s *userService) UpdateUser(c *gin.Context) {
 // TAMBAHAN: UpdateUserRole godoc
 // ===================================
 // @Summary      Update User Role
-// @Description  Mengubah peran (Role) pengguna.
+// @Description  Mengubah peran (Role) pengguna. Ditolak 409 bila user adalah admin aktif terakhir dan role baru bukan Admin.
 // @Tags         Users
 // @Security     BearerAuth
 // @Param        id path string true "User UUID"
@@ -209,6 +273,7 @@ func (s *userService) UpdateUser(c *gin.Context) {
 // @Success      200 {object} map[string]string
 // @Failure      400 {object} map[string]string
 // @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
 // @Failure      500 {object} map[string]string
 // @Router       /users/{id}/role [put]
 func (s *userService) UpdateUserRole(c *gin.Context) {
@@ -232,29 +297,264 @@ func (s *userService) UpdateUserRole(c *gin.Context) {
 		return
 	}
 
-	// 2. Cek apakah user ada (Opsional, tapi baik untuk validasi)
-	_, err = s.userRepo.FindByID(userUUID)
+	// 2. Cek apakah user dan role tujuan ada
+	user, err := s.userRepo.FindByID(userUUID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "user not found"})
 		return
 	}
+	role, err := s.roleRepo.FindByID(roleUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "role not found"})
+		return
+	}
 
-	// 3. Update Role di database
+	// 3. Admin aktif terakhir tidak boleh diturunkan, sama seperti tidak boleh dinonaktifkan/dihapus
+	if !strings.EqualFold(role.Name, model.RoleAdmin) {
+		last, err := s.isLastActiveAdmin(user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		if last {
+			c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "cannot remove the last active administrator"})
+			return
+		}
+	}
+
+	// 4. Update Role di database (security version ikut naik, token lama langsung dicabut)
 	if err := s.userRepo.UpdateRole(userUUID, roleUUID); err != nil {
-		// Cek jika role ID tidak valid (jika database enforced FK constraint)
-		// atau error internal lainnya
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
 		return
 	}
+	s.accessCache.InvalidateUser(userUUID)
 
 	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "User role updated successfully"})
 }
 
+// UnlockUser godoc
+// @Summary      Unlock User Login
+// @Description  Menghapus lockout/progressive delay akibat percobaan login gagal.
+// @Tags         Users
+// @Security     BearerAuth
+// @Param        id path string true "User UUID"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /users/{id}/unlock [post]
+func (s *userService) UnlockUser(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid user id format"})
+		return
+	}
+
+	if _, err := s.userRepo.FindByID(userUUID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "user not found"})
+		return
+	}
+
+	if err := s.loginAttemptRepo.DeleteByUserID(userUUID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "User login unlocked successfully"})
+}
+
+// ResetUserPassword godoc
+// @Summary      Reset User Password (Admin)
+// @Description  Mengganti password lokal user. Semua token yang sudah diterbitkan untuk user ikut dicabut.
+// @Tags         Users
+// @Security     BearerAuth
+// @Accept       json
+// @Param        id path string true "User UUID"
+// @Param        request body model.UserPasswordResetRequest true "Password baru"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /users/{id}/password [put]
+func (s *userService) ResetUserPassword(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid user id format"})
+		return
+	}
+
+	var req model.UserPasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	user, err := s.userRepo.FindByIDIncludingInactive(userUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "user not found"})
+		return
+	}
+	if user.IsServiceAccount {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "service accounts authenticate with api keys only"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "failed to encrypt password"})
+		return
+	}
+	if err := s.userRepo.UpdatePassword(userUUID, string(hashedPassword)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	// Token lama (termasuk refresh token) tidak boleh bertahan setelah password diganti
+	if err := s.userRepo.BumpSecurityVersion(userUUID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	s.accessCache.InvalidateUser(userUUID)
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "User password reset successfully"})
+}
+
+// removalBlocker mengembalikan alasan user tidak boleh dinonaktifkan/dihapus ("" bila boleh):
+// akun sendiri, admin aktif terakhir, atau dosen yang masih punya mahasiswa bimbingan aktif
+func (s *userService) removalBlocker(c *gin.Context, user *model.User) (string, error) {
+	if c.GetString("userID") == user.ID.String() {
+		return "you cannot deactivate or delete your own account", nil
+	}
+
+	last, err := s.isLastActiveAdmin(user)
+	if err != nil {
+		return "", err
+	}
+	if last {
+		return "cannot remove the last active administrator", nil
+	}
+
+	if user.Role != nil && model.ProfileKindForRole(user.Role.Name) == model.ProfileKindLecturer {
+		lecturer, err := s.lecturerRepo.FindByUserID(user.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil // User dosen tanpa profil dosen: tidak punya bimbingan
+		}
+		if err != nil {
+			return "", err
+		}
+		return lecturerAdviseeBlocker(s.studentRepo, lecturer.ID)
+	}
+	return "", nil
+}
+
+// isLastActiveAdmin: true bila user adalah admin aktif dan tidak ada admin aktif lain
+func (s *userService) isLastActiveAdmin(user *model.User) (bool, error) {
+	if !user.IsActive || user.Role == nil || !strings.EqualFold(user.Role.Name, model.RoleAdmin) {
+		return false, nil
+	}
+	admins, err := s.userRepo.FindByRoleID(user.RoleID)
+	if err != nil {
+		return false, err
+	}
+	for _, admin := range admins {
+		if admin.IsActive && admin.ID != user.ID {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// DeactivateUser godoc
+// @Summary      Deactivate User
+// @Description  Menonaktifkan user (tidak bisa login, token lama dicabut) tanpa menghapus datanya. Ditolak 409 untuk akun sendiri, admin aktif terakhir, atau dosen yang masih punya mahasiswa bimbingan aktif.
+// @Tags         Users
+// @Security     BearerAuth
+// @Param        id path string true "User UUID"
+// @Success      200 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /users/{id}/deactivate [post]
+func (s *userService) DeactivateUser(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid user id format"})
+		return
+	}
+
+	user, err := s.userRepo.FindByIDIncludingInactive(userUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "user not found"})
+		return
+	}
+	if !user.IsActive {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "user is already inactive"})
+		return
+	}
+
+	reason, err := s.removalBlocker(c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if reason != "" {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": reason})
+		return
+	}
+
+	if err := s.userRepo.SetActive(userUUID, false); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	s.accessCache.InvalidateUser(userUUID)
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "User deactivated successfully"})
+}
+
+// ReactivateUser godoc
+// @Summary      Reactivate User
+// @Description  Mengaktifkan kembali user yang dinonaktifkan. User yang sudah dihapus tidak bisa diaktifkan kembali.
+// @Tags         Users
+// @Security     BearerAuth
+// @Param        id path string true "User UUID"
+// @Success      200 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /users/{id}/reactivate [post]
+func (s *userService) ReactivateUser(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid user id format"})
+		return
+	}
+
+	user, err := s.userRepo.FindByIDIncludingInactive(userUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "user not found"})
+		return
+	}
+	if user.IsActive {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "user is already active"})
+		return
+	}
+
+	if err := s.userRepo.SetActive(userUUID, true); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	s.accessCache.InvalidateUser(userUUID)
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "User reactivated successfully"})
+}
+
 // DeleteUser godoc
 // @Summary      Delete User
+// @Description  Soft delete: user disembunyikan dan dinonaktifkan, profil mahasiswa/dosennya ikut dihapus, tetapi referensi historis (mis. verified_by prestasi) tetap utuh. Aturan penolakan sama dengan deactivate.
 // @Tags         Users
 // @Security     BearerAuth
 // @Param        id path string true "User UUID"
+// @Success      200 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
 // @Router       /users/{id} [delete]
 func (s *userService) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
@@ -264,10 +564,26 @@ func (s *userService) DeleteUser(c *gin.Context) {
 		return
 	}
 
+	user, err := s.userRepo.FindByIDIncludingInactive(userUUID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "user not found"})
+		return
+	}
+	reason, err := s.removalBlocker(c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	if reason != "" {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": reason})
+		return
+	}
+
 	if err := s.userRepo.Delete(userUUID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
 		return
 	}
+	s.accessCache.InvalidateUser(userUUID)
 
 	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "User deleted successfully"})
-}
\ No newline at end of file
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strings"
+)
+
+// APIKeyPrefix menandai string sebagai API key service account
+const APIKeyPrefix = "sak_"
+
+// GenerateAPIKey menghasilkan key plaintext (sak_<8 hex>_<64 hex>), prefix tampilan, dan hash-nya
+func GenerateAPIKey() (key, displayPrefix, keyHash string, err error) {
+	idPart := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err = rand.Read(idPart); err != nil {
+		return "", "", "", err
+	}
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	displayPrefix = APIKeyPrefix + hex.EncodeToString(idPart)
+	key = displayPrefix + "_" + hex.EncodeToString(secret)
+	return key, displayPrefix, HashAPIKey(key), nil
+}
+
+// HashAPIKey: SHA-256 cukup karena key ber-entropi tinggi (tidak perlu bcrypt)
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidIPOrCIDR mengecek entri allowlist
+func ValidIPOrCIDR(entry string) bool {
+	if net.ParseIP(entry) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(entry)
+	return err == nil
+}
+
+// IPAllowed mengecek ip terhadap allowlist (IP atau CIDR); allowlist kosong = semua diizinkan
+func IPAllowed(ip string, allowlist []string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, entry := range allowlist {
+		if strings.Contains(entry, "/") {
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(parsed) {
+				return true
+			}
+			continue
+		}
+		if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(parsed) {
+			return true
+		}
+	}
+	return false
+}
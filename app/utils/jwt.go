@@ -7,19 +7,35 @@ import (
 
 	"github.com/fitrinovs/achievement_system/app/model"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// RefreshTokenTTL adalah masa berlaku refresh token, sekaligus masa berlaku sesi login
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+// sessionClaim mengubah uuid.Nil menjadi nil agar claim "sid" tidak ikut ditulis
+func sessionClaim(sessionID uuid.UUID) *uuid.UUID {
+	if sessionID == uuid.Nil {
+		return nil
+	}
+	return &sessionID
+}
+
 // GenerateToken membuat Access Token (short-lived: 1 jam)
-func GenerateToken(user model.User, role string, permissions []string) (string, error) {
+func GenerateToken(user model.User, role string, permissions []string, mfaVerified bool, sessionID uuid.UUID) (string, error) {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		jwtSecret = "your-secret-key-change-in-production"
 	}
 
 	claims := model.JwtCustomClaims{
-		UserID:      user.ID,
-		Role:        role,
-		Permissions: permissions,
+		UserID:          user.ID,
+		Role:            role,
+		Permissions:     permissions,
+		TokenType:       model.TokenTypeAccess,
+		MFAVerified:     mfaVerified,
+		SecurityVersion: user.SecurityVersion,
+		SessionID:       sessionClaim(sessionID),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)), // 1 jam
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -32,17 +48,70 @@ func GenerateToken(user model.User, role string, permissions []string) (string,
 }
 
 // GenerateRefreshToken membuat Refresh Token (long-lived: 7 hari)
-func GenerateRefreshToken(user model.User, role string) (string, error) {
+func GenerateRefreshToken(user model.User, role string, mfaVerified bool, sessionID uuid.UUID) (string, error) {
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		jwtSecret = "your-secret-key-change-in-production"
+	}
+
+	claims := model.JwtCustomClaims{
+		UserID:          user.ID,
+		Role:            role,
+		TokenType:       model.TokenTypeRefresh,
+		MFAVerified:     mfaVerified,
+		SecurityVersion: user.SecurityVersion,
+		SessionID:       sessionClaim(sessionID),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RefreshTokenTTL)), // 7 hari
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   user.Username,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(jwtSecret))
+}
+
+// GenerateMFAChallengeToken membuat token sementara antara langkah password dan langkah TOTP
+func GenerateMFAChallengeToken(user model.User, ttl time.Duration) (string, error) {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		jwtSecret = "your-secret-key-change-in-production"
 	}
 
 	claims := model.JwtCustomClaims{
-		UserID: user.ID,
-		Role:   role,
+		UserID:          user.ID,
+		TokenType:       model.TokenTypeMFAChallenge,
+		SecurityVersion: user.SecurityVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), // 7 hari
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   user.Username,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(jwtSecret))
+}
+
+// GenerateImpersonationToken membuat Access Token atas nama user lain.
+// Tidak ada refresh token: sesi impersonation berakhir saat token kedaluwarsa.
+func GenerateImpersonationToken(user model.User, role string, permissions []string, impersonatorID uuid.UUID, mfaVerified bool, ttl time.Duration) (string, error) {
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		jwtSecret = "your-secret-key-change-in-production"
+	}
+
+	claims := model.JwtCustomClaims{
+		UserID:          user.ID,
+		Role:            role,
+		Permissions:     permissions,
+		TokenType:       model.TokenTypeAccess,
+		MFAVerified:     mfaVerified,
+		SecurityVersion: user.SecurityVersion,
+		ImpersonatorID:  &impersonatorID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Subject:   user.Username,
 		},
@@ -54,6 +123,20 @@ func GenerateRefreshToken(user model.User, role string) (string, error) {
 
 // ValidateToken memvalidasi Access Token
 func ValidateToken(tokenString string) (*model.JwtCustomClaims, error) {
+	return validateTokenOfType(tokenString, model.TokenTypeAccess)
+}
+
+// ValidateRefreshToken memvalidasi Refresh Token
+func ValidateRefreshToken(tokenString string) (*model.JwtCustomClaims, error) {
+	return validateTokenOfType(tokenString, model.TokenTypeRefresh)
+}
+
+// ValidateMFAChallengeToken memvalidasi token challenge MFA
+func ValidateMFAChallengeToken(tokenString string) (*model.JwtCustomClaims, error) {
+	return validateTokenOfType(tokenString, model.TokenTypeMFAChallenge)
+}
+
+func validateTokenOfType(tokenString, tokenType string) (*model.JwtCustomClaims, error) {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		jwtSecret = "your-secret-key-change-in-production"
@@ -71,14 +154,58 @@ func ValidateToken(tokenString string) (*model.JwtCustomClaims, error) {
 	}
 
 	if claims, ok := token.Claims.(*model.JwtCustomClaims); ok && token.Valid {
+		if claims.TokenType != tokenType {
+			return nil, errors.New("invalid token type")
+		}
 		return claims, nil
 	}
 
 	return nil, errors.New("invalid token")
 }
 
-// ValidateRefreshToken memvalidasi Refresh Token
-func ValidateRefreshToken(tokenString string) (*model.JwtCustomClaims, error) {
-	// Sama seperti ValidateToken, tapi khusus untuk refresh token
-	return ValidateToken(tokenString)
+// GenerateOIDCStateToken menandatangani state, nonce, dan PKCE verifier yang disimpan
+// di cookie browser selama redirect ke identity provider
+func GenerateOIDCStateToken(state, nonce, codeVerifier string, ttl time.Duration) (string, error) {
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		jwtSecret = "your-secret-key-change-in-production"
+	}
+
+	claims := model.OIDCStateClaims{
+		TokenType:    model.TokenTypeOIDCState,
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(jwtSecret))
+}
+
+// ValidateOIDCStateToken memvalidasi cookie state OIDC
+func ValidateOIDCStateToken(tokenString string) (*model.OIDCStateClaims, error) {
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		jwtSecret = "your-secret-key-change-in-production"
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &model.OIDCStateClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(jwtSecret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*model.OIDCStateClaims)
+	if !ok || !token.Valid || claims.TokenType != model.TokenTypeOIDCState {
+		return nil, errors.New("invalid oidc state token")
+	}
+	return claims, nil
 }
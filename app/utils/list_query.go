@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/gin-gonic/gin"
+)
+
+// ParseListQuery membaca ?page, ?limit, ?cursor, ?q (atau ?search), ?sort, dan filter yang disebutkan
+// dari query string. Nilai sort/filter divalidasi oleh repository masing-masing.
+func ParseListQuery(c *gin.Context, filterNames ...string) (model.ListQuery, error) {
+	query := model.ListQuery{
+		Page:    1,
+		Limit:   model.DefaultListLimit,
+		Cursor:  strings.TrimSpace(c.Query("cursor")),
+		Search:  strings.TrimSpace(c.Query("q")),
+		Filters: make(map[string]string),
+	}
+	if query.Search == "" {
+		query.Search = strings.TrimSpace(c.Query("search"))
+	}
+
+	if raw := c.Query("page"); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return query, errors.New("page must be a positive number")
+		}
+		query.Page = page
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return query, errors.New("limit must be a positive number")
+		}
+		if limit > model.MaxListLimit {
+			limit = model.MaxListLimit
+		}
+		query.Limit = limit
+	}
+
+	if sort := strings.TrimSpace(c.Query("sort")); sort != "" {
+		query.SortDesc = strings.HasPrefix(sort, "-")
+		query.Sort = strings.TrimPrefix(sort, "-")
+	}
+
+	for _, name := range filterNames {
+		if value := strings.TrimSpace(c.Query(name)); value != "" {
+			query.Filters[name] = value
+		}
+	}
+	return query, nil
+}
@@ -0,0 +1,216 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testClientID = "achievement-system"
+
+// mockIdP adalah identity provider tiruan: discovery, JWKS, dan token endpoint
+type mockIdP struct {
+	server  *httptest.Server
+	key     *rsa.PrivateKey
+	kid     string
+	idToken string
+	// tokenForm menyimpan form terakhir yang diterima token endpoint
+	tokenForm url.Values
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key, kid: "test-key"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": idp.kid,
+				"kty": "RSA",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		idp.tokenForm = r.PostForm
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "id_token": idp.idToken})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *mockIdP) provider() *OIDCProvider {
+	return NewOIDCProvider(idp.server.URL, testClientID, "secret", "http://localhost/api/v1/auth/oidc/callback", nil)
+}
+
+// claims mengembalikan claim ID token yang valid; test mengubahnya sesuai skenario
+func (idp *mockIdP) claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"sub":   "subject-1",
+		"aud":   testClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": nonce,
+	}
+}
+
+func (idp *mockIdP) sign(t *testing.T, claims jwt.MapClaims, key *rsa.PrivateKey) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = idp.kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestOIDCVerifyIDToken(t *testing.T) {
+	idp := newMockIdP(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(jwt.MapClaims)
+		key     *rsa.PrivateKey
+		wantErr string
+	}{
+		{name: "valid", mutate: func(jwt.MapClaims) {}},
+		{name: "nonce mismatch", mutate: func(c jwt.MapClaims) { c["nonce"] = "other-nonce" }, wantErr: "nonce mismatch"},
+		{name: "missing nonce", mutate: func(c jwt.MapClaims) { delete(c, "nonce") }, wantErr: "nonce mismatch"},
+		{name: "bad audience", mutate: func(c jwt.MapClaims) { c["aud"] = "other-client" }, wantErr: "audience"},
+		{name: "bad issuer", mutate: func(c jwt.MapClaims) { c["iss"] = "https://evil.example" }, wantErr: "issuer"},
+		{name: "expired", mutate: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, wantErr: "expired"},
+		{name: "missing exp", mutate: func(c jwt.MapClaims) { delete(c, "exp") }, wantErr: "exp"},
+		{name: "wrong signing key", mutate: func(jwt.MapClaims) {}, key: otherKey, wantErr: "signature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := idp.claims("nonce-1")
+			tt.mutate(claims)
+			key := tt.key
+			if key == nil {
+				key = idp.key
+			}
+
+			got, err := idp.provider().VerifyIDToken(context.Background(), idp.sign(t, claims, key), "nonce-1")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got["sub"] != "subject-1" {
+					t.Fatalf("sub = %v, want subject-1", got["sub"])
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOIDCVerifyIDTokenRejectsHMAC(t *testing.T) {
+	idp := newMockIdP(t)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, idp.claims("nonce-1"))
+	signed, err := token.SignedString([]byte("shared"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := idp.provider().VerifyIDToken(context.Background(), signed, "nonce-1"); err == nil {
+		t.Fatal("expected HS256 id token to be rejected")
+	}
+}
+
+func TestOIDCAuthCodeURL(t *testing.T) {
+	idp := newMockIdP(t)
+
+	raw, err := idp.provider().AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authURL.Path != "/authorize" {
+		t.Fatalf("path = %s, want /authorize", authURL.Path)
+	}
+	query := authURL.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"scope":                 "openid",
+		"code_challenge":        PKCEChallengeS256("verifier-1"),
+		"code_challenge_method": "S256",
+	}
+	for param, value := range want {
+		if got := query.Get(param); got != value {
+			t.Errorf("%s = %q, want %q", param, got, value)
+		}
+	}
+}
+
+func TestOIDCExchange(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.idToken = idp.sign(t, idp.claims("nonce-1"), idp.key)
+
+	got, err := idp.provider().Exchange(context.Background(), "code-1", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != idp.idToken {
+		t.Fatal("exchange did not return the id_token from the token endpoint")
+	}
+	if idp.tokenForm.Get("code") != "code-1" || idp.tokenForm.Get("code_verifier") != "verifier-1" {
+		t.Fatalf("token request form = %v", idp.tokenForm)
+	}
+}
+
+func TestOIDCDiscoveryIssuerMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := NewOIDCProvider(idp.server.URL+"/other", testClientID, "", "", nil)
+
+	if _, err := provider.AuthCodeURL(context.Background(), "s", "n", "v"); err == nil {
+		t.Fatal("expected discovery to fail for a different issuer")
+	}
+}
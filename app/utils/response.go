@@ -11,6 +11,7 @@ type Response struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+	Meta    interface{} `json:"meta,omitempty"`
 	Error   interface{} `json:"error,omitempty"`
 }
 
@@ -22,6 +23,16 @@ func SuccessResponse(c *gin.Context, statusCode int, message string, data interf
 	})
 }
 
+// PaginatedResponse mengembalikan list beserta metadata paging di field "meta"
+func PaginatedResponse(c *gin.Context, message string, data interface{}, meta interface{}) {
+	c.JSON(http.StatusOK, Response{
+		Success: true,
+		Message: message,
+		Data:    data,
+		Meta:    meta,
+	})
+}
+
 func ErrorResponse(c *gin.Context, statusCode int, message string, err interface{}) {
 	c.JSON(statusCode, Response{
 		Success: false,
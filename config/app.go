@@ -4,21 +4,36 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	MongoDB  MongoDBConfig
-	JWT      JWTConfig
-	Upload   UploadConfig
+	Server        ServerConfig
+	Database      DatabaseConfig
+	MongoDB       MongoDBConfig
+	JWT           JWTConfig
+	Upload        UploadConfig
+	Login         LoginSecurityConfig
+	MFA           MFAConfig
+	Seed          BootstrapConfig
+	Impersonation ImpersonationConfig
+	OIDC          OIDCConfig
+	AuthChain     AuthChainConfig
+	LDAP          LDAPConfig
+	Import        ImportConfig
+	Advisor       AdvisorConfig
+	Period        AcademicPeriodConfig
+	Student       StudentConfig
 }
 
 type ServerConfig struct {
 	Port string
 	Env  string
+	// TrustedProxies: IP/CIDR reverse proxy yang header X-Forwarded-For-nya dipercaya.
+	// Kosong = tidak ada; ClientIP selalu alamat koneksi langsung.
+	TrustedProxies []string
 }
 
 type DatabaseConfig struct {
@@ -39,6 +54,8 @@ type JWTConfig struct {
 	Secret             string
 	ExpireHours        int
 	RefreshExpireHours int
+	// AccessCacheSeconds: TTL cache status user & permission role di AuthMiddleware
+	AccessCacheSeconds int
 }
 
 type UploadConfig struct {
@@ -46,6 +63,121 @@ type UploadConfig struct {
 	Path    string
 }
 
+// LoginSecurityConfig mengatur proteksi brute-force pada endpoint login
+type LoginSecurityConfig struct {
+	MaxAccountFailures int // Gagal berturut-turut sebelum akun dikunci
+	LockoutMinutes     int // Lama akun terkunci
+	MaxIPFailures      int // Batas gagal per IP dalam satu window
+	WindowMinutes      int // Window penghitungan percobaan gagal
+	DelayAfterFailures int // Progressive delay mulai berlaku setelah N kali gagal
+	BaseDelaySeconds   int
+	MaxDelaySeconds    int
+}
+
+// MFAConfig mengatur TOTP two-factor authentication
+type MFAConfig struct {
+	Issuer           string   // Nama yang tampil di authenticator app
+	RequiredRoles    []string // Role yang wajib MFA (kosong = MFA opsional untuk semua)
+	ChallengeMinutes int      // Masa berlaku MFA challenge token
+}
+
+// BootstrapConfig mengatur seeding katalog permission/role dan akun admin pertama
+type BootstrapConfig struct {
+	SeedOnStartup bool
+	AdminUsername string
+	AdminEmail    string
+	AdminFullName string
+	AdminPassword string // Kosong = bootstrap admin tidak dijalankan
+}
+
+// ImpersonationConfig mengatur fitur "view as user" untuk admin/support
+type ImpersonationConfig struct {
+	TokenMinutes int  // Masa berlaku token impersonation
+	AllowWrites  bool // Default false: request selain GET/HEAD/OPTIONS ditolak selama impersonation
+}
+
+// OIDCConfig mengatur single sign-on lewat identity provider kampus (authorization code + PKCE).
+// Endpoint IdP dibaca dari <IssuerURL>/.well-known/openid-configuration, sehingga untuk
+// pengujian lokal cukup arahkan OIDC_ISSUER_URL ke mock IdP (mis. mock-oauth2-server).
+type OIDCConfig struct {
+	Enabled      bool
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string   // Harus mengarah ke /api/v1/auth/oidc/callback
+	Scopes       []string // Selalu mencakup "openid"
+	StateMinutes int      // Masa berlaku state login (cookie) sebelum callback
+
+	// Nama claim di ID token yang dipetakan ke data lokal
+	NIMClaim          string
+	NIPClaim          string
+	EmailClaim        string
+	NameClaim         string
+	ProgramStudyClaim string
+	DepartmentClaim   string
+
+	// JITProvisioning membuat User + Student/Lecturer saat claim NIM/NIP belum terdaftar
+	JITProvisioning bool
+	// PostLoginRedirectURL (opsional): frontend tujuan setelah login; token dikirim di URL fragment.
+	// Kosong = callback mengembalikan JSON seperti /auth/login.
+	PostLoginRedirectURL string
+}
+
+// AuthChainConfig menentukan urutan authenticator untuk login password ("local", "ldap").
+// Aturan domain (bagian setelah @ pada identifier/email user) lebih diutamakan daripada aturan role.
+// Format env: AUTH_CHAIN_ROLES="Dosen Wali=ldap,local;Admin=local", AUTH_CHAIN_DOMAINS="staff.univ.ac.id=ldap".
+type AuthChainConfig struct {
+	Default  []string
+	ByRole   map[string][]string
+	ByDomain map[string][]string
+}
+
+// LDAPConfig mengatur bind authentication ke direktori LDAP fakultas.
+// Alur: bind sebagai service DN -> cari entry user (UserFilter) -> bind ulang sebagai DN user.
+// Untuk pengujian lokal cukup arahkan LDAP_URL ke server LDAP lokal (mis. container openldap).
+type LDAPConfig struct {
+	URL                string // ldap://host:389 atau ldaps://host:636
+	StartTLS           bool
+	InsecureSkipVerify bool
+	BindDN             string
+	BindPassword       string
+	BaseDN             string
+	UserFilter         string // %s diganti username (sudah di-escape), mis. (uid=%s)
+	NameAttribute      string
+	EmailAttribute     string
+	TimeoutSeconds     int
+}
+
+// ImportConfig mengatur import massal mahasiswa/dosen dari CSV/XLSX
+type ImportConfig struct {
+	MaxRows int
+	// ErrorDir menyimpan CSV baris gagal; jangan arahkan ke UPLOAD_PATH (disajikan publik)
+	ErrorDir       string
+	ErrorFileHours int
+}
+
+// AdvisorConfig mengatur penugasan dosen wali
+type AdvisorConfig struct {
+	// InFlightPolicy default untuk prestasi SUBMITTED saat dosen wali berganti: transfer | retain | reset
+	InFlightPolicy string
+	// MaxAdvisees adalah batas bimbingan per dosen untuk usulan penyeimbangan (0 = tanpa batas)
+	MaxAdvisees int
+}
+
+// AcademicPeriodConfig mengatur atribusi prestasi ke periode akademik
+type AcademicPeriodConfig struct {
+	// AttributeBy adalah tanggal acuan periode: event_date | submission_date.
+	// Bila tanggal acuan belum ada, tanggal lainnya dipakai sebagai cadangan.
+	AttributeBy string
+}
+
+// StudentConfig mengatur aturan status studi mahasiswa
+type StudentConfig struct {
+	// SubmissionGraceDays adalah masa tenggang (hari) sejak status non-aktif berlaku, selama itu
+	// mahasiswa masih boleh mengubah dan mengajukan prestasi. 0 = langsung hanya-baca.
+	SubmissionGraceDays int
+}
+
 func LoadConfig() *Config {
 	err := godotenv.Load()
 	if err != nil {
@@ -54,12 +186,35 @@ func LoadConfig() *Config {
 
 	jwtExpire, _ := strconv.Atoi(getEnv("JWT_EXPIRE_HOURS", "24"))
 	refreshExpire, _ := strconv.Atoi(getEnv("REFRESH_TOKEN_EXPIRE_HOURS", "168"))
+	accessCacheTTL, _ := strconv.Atoi(getEnv("AUTH_CACHE_TTL_SECONDS", "30"))
 	maxUploadSize, _ := strconv.ParseInt(getEnv("MAX_UPLOAD_SIZE", "10485760"), 10, 64)
+	loginMaxFailures, _ := strconv.Atoi(getEnv("LOGIN_MAX_FAILURES", "5"))
+	loginLockout, _ := strconv.Atoi(getEnv("LOGIN_LOCKOUT_MINUTES", "15"))
+	loginMaxIPFailures, _ := strconv.Atoi(getEnv("LOGIN_MAX_IP_FAILURES", "20"))
+	loginWindow, _ := strconv.Atoi(getEnv("LOGIN_WINDOW_MINUTES", "15"))
+	loginDelayAfter, _ := strconv.Atoi(getEnv("LOGIN_DELAY_AFTER_FAILURES", "3"))
+	loginBaseDelay, _ := strconv.Atoi(getEnv("LOGIN_BASE_DELAY_SECONDS", "1"))
+	loginMaxDelay, _ := strconv.Atoi(getEnv("LOGIN_MAX_DELAY_SECONDS", "30"))
+	mfaChallenge, _ := strconv.Atoi(getEnv("MFA_CHALLENGE_MINUTES", "5"))
+	seedOnStartup, _ := strconv.ParseBool(getEnv("SEED_ON_STARTUP", "true"))
+	impersonationMinutes, _ := strconv.Atoi(getEnv("IMPERSONATION_TOKEN_MINUTES", "30"))
+	impersonationWrites, _ := strconv.ParseBool(getEnv("IMPERSONATION_ALLOW_WRITES", "false"))
+	oidcEnabled, _ := strconv.ParseBool(getEnv("OIDC_ENABLED", "false"))
+	oidcStateMinutes, _ := strconv.Atoi(getEnv("OIDC_STATE_MINUTES", "10"))
+	oidcJIT, _ := strconv.ParseBool(getEnv("OIDC_JIT_PROVISIONING", "false"))
+	ldapStartTLS, _ := strconv.ParseBool(getEnv("LDAP_START_TLS", "false"))
+	ldapInsecure, _ := strconv.ParseBool(getEnv("LDAP_INSECURE_SKIP_VERIFY", "false"))
+	ldapTimeout, _ := strconv.Atoi(getEnv("LDAP_TIMEOUT_SECONDS", "5"))
+	importMaxRows, _ := strconv.Atoi(getEnv("IMPORT_MAX_ROWS", "5000"))
+	importErrorHours, _ := strconv.Atoi(getEnv("IMPORT_ERROR_FILE_HOURS", "24"))
+	advisorMaxAdvisees, _ := strconv.Atoi(getEnv("ADVISOR_MAX_ADVISEES", "30"))
+	studentGraceDays, _ := strconv.Atoi(getEnv("STUDENT_SUBMISSION_GRACE_DAYS", "30"))
 
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
-			Env:  getEnv("ENV", "development"),
+			Port:           getEnv("PORT", "8080"),
+			Env:            getEnv("ENV", "development"),
+			TrustedProxies: splitList(getEnv("TRUSTED_PROXIES", "")),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -77,12 +232,111 @@ func LoadConfig() *Config {
 			Secret:             getEnv("JWT_SECRET", "your-secret-key"),
 			ExpireHours:        jwtExpire,
 			RefreshExpireHours: refreshExpire,
+			AccessCacheSeconds: accessCacheTTL,
 		},
 		Upload: UploadConfig{
 			MaxSize: maxUploadSize,
 			Path:    getEnv("UPLOAD_PATH", "./uploads"),
 		},
+		Login: LoginSecurityConfig{
+			MaxAccountFailures: loginMaxFailures,
+			LockoutMinutes:     loginLockout,
+			MaxIPFailures:      loginMaxIPFailures,
+			WindowMinutes:      loginWindow,
+			DelayAfterFailures: loginDelayAfter,
+			BaseDelaySeconds:   loginBaseDelay,
+			MaxDelaySeconds:    loginMaxDelay,
+		},
+		MFA: MFAConfig{
+			Issuer:           getEnv("MFA_ISSUER", "Student Achievement System"),
+			RequiredRoles:    splitList(getEnv("MFA_REQUIRED_ROLES", "")),
+			ChallengeMinutes: mfaChallenge,
+		},
+		Seed: BootstrapConfig{
+			SeedOnStartup: seedOnStartup,
+			AdminUsername: getEnv("BOOTSTRAP_ADMIN_USERNAME", "admin"),
+			AdminEmail:    getEnv("BOOTSTRAP_ADMIN_EMAIL", "admin@localhost"),
+			AdminFullName: getEnv("BOOTSTRAP_ADMIN_FULL_NAME", "Administrator"),
+			AdminPassword: getEnv("BOOTSTRAP_ADMIN_PASSWORD", ""),
+		},
+		Impersonation: ImpersonationConfig{
+			TokenMinutes: impersonationMinutes,
+			AllowWrites:  impersonationWrites,
+		},
+		OIDC: OIDCConfig{
+			Enabled:              oidcEnabled,
+			IssuerURL:            strings.TrimSuffix(getEnv("OIDC_ISSUER_URL", ""), "/"),
+			ClientID:             getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret:         getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:          getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oidc/callback"),
+			Scopes:               splitList(getEnv("OIDC_SCOPES", "openid, profile, email")),
+			StateMinutes:         oidcStateMinutes,
+			NIMClaim:             getEnv("OIDC_NIM_CLAIM", "nim"),
+			NIPClaim:             getEnv("OIDC_NIP_CLAIM", "nip"),
+			EmailClaim:           getEnv("OIDC_EMAIL_CLAIM", "email"),
+			NameClaim:            getEnv("OIDC_NAME_CLAIM", "name"),
+			ProgramStudyClaim:    getEnv("OIDC_PROGRAM_STUDY_CLAIM", "program_study"),
+			DepartmentClaim:      getEnv("OIDC_DEPARTMENT_CLAIM", "department"),
+			JITProvisioning:      oidcJIT,
+			PostLoginRedirectURL: getEnv("OIDC_POST_LOGIN_REDIRECT_URL", ""),
+		},
+		AuthChain: AuthChainConfig{
+			Default:  splitList(getEnv("AUTH_CHAIN_DEFAULT", "local")),
+			ByRole:   splitRules(getEnv("AUTH_CHAIN_ROLES", "")),
+			ByDomain: splitRules(strings.ToLower(getEnv("AUTH_CHAIN_DOMAINS", ""))),
+		},
+		LDAP: LDAPConfig{
+			URL:                getEnv("LDAP_URL", ""),
+			StartTLS:           ldapStartTLS,
+			InsecureSkipVerify: ldapInsecure,
+			BindDN:             getEnv("LDAP_BIND_DN", ""),
+			BindPassword:       getEnv("LDAP_BIND_PASSWORD", ""),
+			BaseDN:             getEnv("LDAP_BASE_DN", ""),
+			UserFilter:         getEnv("LDAP_USER_FILTER", "(uid=%s)"),
+			NameAttribute:      getEnv("LDAP_NAME_ATTRIBUTE", "cn"),
+			EmailAttribute:     getEnv("LDAP_EMAIL_ATTRIBUTE", "mail"),
+			TimeoutSeconds:     ldapTimeout,
+		},
+		Import: ImportConfig{
+			MaxRows:        importMaxRows,
+			ErrorDir:       getEnv("IMPORT_ERROR_DIR", "./storage/import-errors"),
+			ErrorFileHours: importErrorHours,
+		},
+		Advisor: AdvisorConfig{
+			InFlightPolicy: strings.ToLower(getEnv("ADVISOR_IN_FLIGHT_POLICY", "transfer")),
+			MaxAdvisees:    advisorMaxAdvisees,
+		},
+		Period: AcademicPeriodConfig{
+			AttributeBy: strings.ToLower(getEnv("ACADEMIC_PERIOD_ATTRIBUTION", "event_date")),
+		},
+		Student: StudentConfig{
+			SubmissionGraceDays: studentGraceDays,
+		},
+	}
+}
+
+// splitList memecah nilai env berformat "a, b, c" menjadi slice (elemen kosong diabaikan)
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitRules memecah "kunci=a,b;kunci2=c" menjadi map kunci -> daftar nilai
+func splitRules(value string) map[string][]string {
+	rules := make(map[string][]string)
+	for _, rule := range strings.Split(value, ";") {
+		key, list, found := strings.Cut(rule, "=")
+		if key = strings.TrimSpace(key); !found || key == "" {
+			continue
+		}
+		rules[key] = splitList(list)
 	}
+	return rules
 }
 
 func getEnv(key, defaultValue string) string {
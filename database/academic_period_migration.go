@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// eventDateBatchSize membatasi jumlah _id per query $in ke MongoDB
+const eventDateBatchSize = 500
+
+// MigrateAchievementPeriods menyalin details.eventDate dari MongoDB ke achievement_references.event_date
+// untuk prestasi yang belum punya salinannya, lalu mengatribusi ulang semua prestasi ke periode
+// akademik sesuai basis. Aman dijalankan berulang.
+func MigrateAchievementPeriods(basis string) {
+	copied, err := backfillEventDates()
+	if err != nil {
+		log.Fatalf("Failed to backfill achievement event dates: %v", err)
+	}
+	if copied > 0 {
+		log.Printf("Backfilled event date for %d achievements", copied)
+	}
+
+	changed, err := repository.NewAcademicPeriodRepository(DB).Reattribute(basis)
+	if err != nil {
+		log.Fatalf("Failed to attribute achievements to academic periods: %v", err)
+	}
+	if changed > 0 {
+		log.Printf("Attributed %d achievements to academic periods (basis: %s)", changed, basis)
+	}
+}
+
+func backfillEventDates() (int, error) {
+	var refs []model.AchievementReference
+	if err := DB.Unscoped().Select("id", "mongo_achievement_id").
+		Where("event_date IS NULL").Find(&refs).Error; err != nil {
+		return 0, err
+	}
+
+	coll := MongoDB.Collection("achievements")
+	copied := 0
+	for start := 0; start < len(refs); start += eventDateBatchSize {
+		end := start + eventDateBatchSize
+		if end > len(refs) {
+			end = len(refs)
+		}
+		refByMongoID := make(map[primitive.ObjectID]uuid.UUID)
+		objectIDs := make([]primitive.ObjectID, 0, end-start)
+		for _, ref := range refs[start:end] {
+			objectID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+			if err != nil {
+				log.Printf("⚠️  Skipping achievement %s: invalid Mongo ID %q", ref.ID, ref.MongoAchievementID)
+				continue
+			}
+			refByMongoID[objectID] = ref.ID
+			objectIDs = append(objectIDs, objectID)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		cursor, err := coll.Find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}},
+			options.Find().SetProjection(bson.M{"details.eventDate": 1}))
+		if err != nil {
+			cancel()
+			return copied, err
+		}
+		var docs []struct {
+			ID      primitive.ObjectID     `bson:"_id"`
+			Details map[string]interface{} `bson:"details"`
+		}
+		err = cursor.All(ctx, &docs)
+		cancel()
+		if err != nil {
+			return copied, err
+		}
+
+		for _, doc := range docs {
+			eventDate := model.DetailsEventDate(doc.Details)
+			if eventDate == nil {
+				continue
+			}
+			if err := DB.Model(&model.AchievementReference{}).Unscoped().
+				Where("id = ?", refByMongoID[doc.ID]).
+				UpdateColumn("event_date", *eventDate).Error; err != nil {
+				return copied, err
+			}
+			copied++
+		}
+	}
+	return copied, nil
+}
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"log"
+
+	"github.com/fitrinovs/achievement_system/app/repository"
+)
+
+// MigrateAcademicUnits memetakan teks bebas students.program_study dan lecturers.department yang
+// belum punya FK ke master data (lewat nama, kode, atau alias). Nilai yang belum cocok tidak fatal:
+// dilaporkan di log dan dapat dipetakan kemudian lewat alias + POST /academic-units/mapping.
+func MigrateAcademicUnits() {
+	report, err := repository.NewAcademicUnitRepository(DB).MapFreeText(true)
+	if err != nil {
+		log.Fatalf("Failed to map academic units: %v", err)
+	}
+
+	if report.StudyPrograms.MappedRows > 0 || report.Departments.MappedRows > 0 {
+		log.Printf("Mapped %d students and %d lecturers to academic master data",
+			report.StudyPrograms.MappedRows, report.Departments.MappedRows)
+	}
+	for _, v := range report.StudyPrograms.Unmapped {
+		log.Printf("⚠️  Unmapped program_study %q (%d students)", v.Value, v.Rows)
+	}
+	for _, v := range report.Departments.Unmapped {
+		log.Printf("⚠️  Unmapped department %q (%d lecturers)", v.Value, v.Rows)
+	}
+}
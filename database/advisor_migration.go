@@ -0,0 +1,27 @@
+package database
+
+import (
+	"log"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+)
+
+// MigrateAdvisorHistory membuat baris riwayat awal untuk mahasiswa yang sudah punya dosen wali
+// sebelum tabel advisor_assignments ada, sehingga pergantian berikutnya punya baris untuk ditutup.
+// Aman dijalankan berulang: mahasiswa yang sudah punya riwayat dilewati.
+func MigrateAdvisorHistory() {
+	result := DB.Exec(`
+		INSERT INTO advisor_assignments (student_id, advisor_id, effective_from, reason, in_flight_policy, in_flight_count, created_at)
+		SELECT s.id, s.advisor_id, s.updated_at, 'initial assignment (backfilled)', ?, 0, NOW()
+		FROM students s
+		WHERE s.advisor_id IS NOT NULL
+		  AND s.deleted_at IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM advisor_assignments a WHERE a.student_id = s.id)
+	`, model.InFlightTransfer)
+	if result.Error != nil {
+		log.Fatalf("Failed to backfill advisor history: %v", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Backfilled advisor history for %d students", result.RowsAffected)
+	}
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"fmt"
+	"log"
+)
+
+// IdentityCollision adalah sekumpulan user yang username/email-nya hanya berbeda huruf besar/kecil
+type IdentityCollision struct {
+	Column  string
+	Value   string
+	Count   int
+	UserIDs string
+}
+
+// identityColumns adalah kolom users yang keunikannya tidak case-sensitive
+var identityColumns = []string{"username", "email"}
+
+// DetectIdentityCollisions mencari username/email yang bentrok jika dibandingkan secara case-insensitive
+func DetectIdentityCollisions() ([]IdentityCollision, error) {
+	var collisions []IdentityCollision
+
+	for _, column := range identityColumns {
+		var rows []IdentityCollision
+		query := fmt.Sprintf(`
+			SELECT '%[1]s' AS column, LOWER(TRIM(%[1]s)) AS value, COUNT(*) AS count,
+			       STRING_AGG(id::text, ', ') AS user_ids
+			FROM users
+			GROUP BY LOWER(TRIM(%[1]s))
+			HAVING COUNT(*) > 1
+		`, column)
+
+		if err := DB.Raw(query).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		collisions = append(collisions, rows...)
+	}
+
+	return collisions, nil
+}
+
+// MigrateCaseInsensitiveIdentity menormalisasi username/email yang sudah ada lalu memasang
+// unique index LOWER(...). Bila masih ada bentrokan, startup dihentikan dengan laporannya:
+// User.BeforeSave selalu menormalisasi identitas, sehingga menyimpan user yang bentrok akan
+// menabrak unique constraint atau diam-diam membuat duplikat. Admin harus menggabungkan/merename
+// akun tersebut secara manual terlebih dahulu.
+func MigrateCaseInsensitiveIdentity() {
+	collisions, err := DetectIdentityCollisions()
+	if err != nil {
+		log.Fatal("Failed to detect identity collisions:", err)
+	}
+
+	for _, collision := range collisions {
+		log.Printf("❌ Case-insensitive %s collision on %q (%d users: %s)",
+			collision.Column, collision.Value, collision.Count, collision.UserIDs)
+	}
+	if len(collisions) > 0 {
+		log.Fatalf("Found %d case-insensitive username/email collision(s); rename or merge the users listed above before starting", len(collisions))
+	}
+
+	for _, column := range identityColumns {
+		normalize := fmt.Sprintf(`UPDATE users SET %[1]s = LOWER(TRIM(%[1]s)) WHERE %[1]s <> LOWER(TRIM(%[1]s))`, column)
+		if err := DB.Exec(normalize).Error; err != nil {
+			log.Fatalf("Failed to normalize users.%s: %v", column, err)
+		}
+
+		index := fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_%[1]s_lower ON users (LOWER(%[1]s))`, column)
+		if err := DB.Exec(index).Error; err != nil {
+			log.Fatalf("Failed to create case-insensitive index on users.%s: %v", column, err)
+		}
+	}
+
+	log.Println("✅ Case-insensitive identity migration completed!")
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// profileIndexes: satu user maksimal punya satu profil mahasiswa dan satu profil dosen.
+// Profil yang sudah di-soft-delete tidak dihitung.
+var profileIndexes = []struct {
+	Table string
+	Index string
+	Where string
+}{
+	{Table: "students", Index: "idx_students_user_id_unique", Where: "WHERE deleted_at IS NULL"},
+	{Table: "lecturers", Index: "idx_lecturers_user_id_unique", Where: "WHERE deleted_at IS NULL"},
+}
+
+// MigrateUniqueProfiles memasang unique index user_id pada tabel profil. Tabel yang masih
+// memiliki user dengan lebih dari satu profil dilewati (tidak fatal) dan dilaporkan di log.
+func MigrateUniqueProfiles() {
+	for _, idx := range profileIndexes {
+		var duplicates []struct {
+			UserID string
+			Count  int
+		}
+		query := fmt.Sprintf(`
+			SELECT user_id::text AS user_id, COUNT(*) AS count
+			FROM %s %s
+			GROUP BY user_id
+			HAVING COUNT(*) > 1
+		`, idx.Table, idx.Where)
+		if err := DB.Raw(query).Scan(&duplicates).Error; err != nil {
+			log.Fatalf("Failed to detect duplicate %s profiles: %v", idx.Table, err)
+		}
+
+		if len(duplicates) > 0 {
+			for _, dup := range duplicates {
+				log.Printf("⚠️  User %s has %d rows in %s", dup.UserID, dup.Count, idx.Table)
+			}
+			log.Printf("⚠️  Skipping unique index on %s.user_id until duplicates are resolved", idx.Table)
+			continue
+		}
+
+		// Index versi lama (dibuat sebelum profil bisa di-soft-delete) tidak punya predikat: buat ulang
+		if idx.Where != "" {
+			var definition string
+			if err := DB.Raw(`SELECT indexdef FROM pg_indexes WHERE indexname = ?`, idx.Index).Scan(&definition).Error; err != nil {
+				log.Fatalf("Failed to inspect index %s: %v", idx.Index, err)
+			}
+			if definition != "" && !strings.Contains(strings.ToLower(definition), " where ") {
+				if err := DB.Exec(fmt.Sprintf(`DROP INDEX IF EXISTS %s`, idx.Index)).Error; err != nil {
+					log.Fatalf("Failed to drop index %s: %v", idx.Index, err)
+				}
+			}
+		}
+
+		index := fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (user_id) %s`, idx.Index, idx.Table, idx.Where)
+		if err := DB.Exec(index).Error; err != nil {
+			log.Fatalf("Failed to create unique index on %s.user_id: %v", idx.Table, err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/utils"
+	"github.com/fitrinovs/achievement_system/config"
+
+	"gorm.io/gorm"
+)
+
+// SeedCatalog menyinkronkan model.PermissionCatalog dan model.DefaultRoles ke database.
+// Aman dijalankan berulang kali: hanya membuat yang belum ada. Permission role default diberikan
+// sekali per pasangan role-permission (dicatat di seeded_role_permissions), jadi relasi yang
+// dicabut admin tidak diberikan ulang saat restart.
+func SeedCatalog() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		permissionIDs := make(map[string]model.Permission, len(model.PermissionCatalog))
+
+		// 1. Permission: upsert berdasarkan nama
+		for _, def := range model.PermissionCatalog {
+			var permission model.Permission
+			err := tx.Where("name = ?", def.Name()).First(&permission).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				permission = model.Permission{
+					Name:        def.Name(),
+					Resource:    def.Resource,
+					Action:      def.Action,
+					Description: def.Description,
+				}
+				if err := tx.Create(&permission).Error; err != nil {
+					return fmt.Errorf("create permission %s: %w", def.Name(), err)
+				}
+				log.Printf("🌱 Permission created: %s", def.Name())
+			case err != nil:
+				return err
+			case permission.Resource != def.Resource || permission.Action != def.Action || permission.Description != def.Description:
+				permission.Resource = def.Resource
+				permission.Action = def.Action
+				permission.Description = def.Description
+				if err := tx.Save(&permission).Error; err != nil {
+					return fmt.Errorf("update permission %s: %w", def.Name(), err)
+				}
+			}
+			permissionIDs[def.Name()] = permission
+		}
+
+		// 2. Role default: buat jika belum ada, lalu berikan permission yang belum pernah di-seed
+		for _, def := range model.DefaultRoles {
+			var role model.Role
+			err := tx.Where("name = ?", def.Name).First(&role).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				role = model.Role{Name: def.Name, Description: def.Description}
+				if err := tx.Create(&role).Error; err != nil {
+					return fmt.Errorf("create role %s: %w", def.Name, err)
+				}
+				log.Printf("🌱 Role created: %s", def.Name)
+			} else if err != nil {
+				return err
+			}
+
+			names := def.Permissions
+			if def.AllPermissions {
+				names = make([]string, 0, len(model.PermissionCatalog))
+				for _, permDef := range model.PermissionCatalog {
+					names = append(names, permDef.Name())
+				}
+			}
+
+			var seeded []string
+			if err := tx.Model(&model.SeededRolePermission{}).
+				Where("role_name = ?", def.Name).
+				Pluck("permission_name", &seeded).Error; err != nil {
+				return err
+			}
+			alreadySeeded := make(map[string]bool, len(seeded))
+			for _, name := range seeded {
+				alreadySeeded[name] = true
+			}
+
+			for _, name := range names {
+				permission, ok := permissionIDs[name]
+				if !ok {
+					return fmt.Errorf("role %s references unknown permission %s", def.Name, name)
+				}
+				if alreadySeeded[name] {
+					continue
+				}
+				link := model.RolePermission{RoleID: role.ID, PermissionID: permission.ID}
+				if err := tx.Where(&link).FirstOrCreate(&link).Error; err != nil {
+					return fmt.Errorf("assign %s to %s: %w", name, def.Name, err)
+				}
+				if err := tx.Create(&model.SeededRolePermission{RoleName: def.Name, PermissionName: name}).Error; err != nil {
+					return fmt.Errorf("record seeded %s for %s: %w", name, def.Name, err)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// BootstrapAdmin membuat akun admin pertama bila belum ada satu pun user dengan role Admin.
+// Hanya berjalan jika BOOTSTRAP_ADMIN_PASSWORD di-set, sehingga tidak ada password default.
+func BootstrapAdmin(cfg config.BootstrapConfig) error {
+	if cfg.AdminPassword == "" {
+		return nil
+	}
+
+	var adminRole model.Role
+	if err := DB.Where("name = ?", model.RoleAdmin).First(&adminRole).Error; err != nil {
+		return fmt.Errorf("admin role not found (run catalog seeding first): %w", err)
+	}
+
+	var adminCount int64
+	if err := DB.Model(&model.User{}).Where("role_id = ?", adminRole.ID).Count(&adminCount).Error; err != nil {
+		return err
+	}
+	if adminCount > 0 {
+		return nil
+	}
+
+	if len(cfg.AdminPassword) < 8 {
+		return errors.New("BOOTSTRAP_ADMIN_PASSWORD must be at least 8 characters")
+	}
+
+	hash, err := utils.HashPassword(cfg.AdminPassword)
+	if err != nil {
+		return err
+	}
+
+	admin := model.User{
+		Username:     cfg.AdminUsername,
+		Email:        cfg.AdminEmail,
+		FullName:     cfg.AdminFullName,
+		PasswordHash: hash,
+		RoleID:       adminRole.ID,
+		IsActive:     true,
+	}
+	if err := DB.Create(&admin).Error; err != nil {
+		return fmt.Errorf("create bootstrap admin: %w", err)
+	}
+
+	log.Printf("🌱 Bootstrap admin created: %s (remove BOOTSTRAP_ADMIN_PASSWORD from the environment now)", admin.Username)
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fitrinovs/achievement_system/app/importer"
+	"github.com/fitrinovs/achievement_system/app/model"
+)
+
+const importUsage = "usage: go run . import <students|lecturers> <file.csv|file.xlsx> [-dry-run] [-upsert] [-errors path]"
+
+// runImportCommand adalah versi CLI dari POST /students/import dan /lecturers/import.
+// Exit code non-nol bila file ditolak, sehingga bisa dipakai di skrip onboarding angkatan.
+func runImportCommand(args []string, imp *importer.Importer, maxRows int) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "validasi saja, tanpa menulis ke database")
+	upsert := flags.Bool("upsert", false, "perbarui data yang NIM/NIP-nya sudah ada")
+	errorsPath := flags.String("errors", "", "lokasi CSV baris gagal (default <file>.errors.csv)")
+
+	// Flag boleh diletakkan sebelum maupun sesudah argumen posisi
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) != 2 {
+		return errors.New(importUsage)
+	}
+	kind, path := positional[0], positional[1]
+	if kind != model.ImportKindStudents && kind != model.ImportKindLecturers {
+		return errors.New(importUsage)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	table, err := importer.ReadTable(path, file, maxRows)
+	if err != nil {
+		return err
+	}
+
+	report, err := imp.Run(kind, table, importer.Options{DryRun: *dryRun, Upsert: *upsert})
+	if err != nil {
+		return err
+	}
+
+	for _, row := range report.Rows {
+		if row.Action != model.ImportActionError {
+			continue
+		}
+		messages := make([]string, 0, len(row.Errors))
+		for _, e := range row.Errors {
+			messages = append(messages, strings.TrimPrefix(e.Field+": "+e.Message, ": "))
+		}
+		fmt.Printf("row %d (%s): %s\n", row.Row, row.Key, strings.Join(messages, "; "))
+	}
+	fmt.Printf("%s: %d rows, %d to create, %d to update, %d failed (dry-run=%t, committed=%t)\n",
+		kind, report.TotalRows, report.Created, report.Updated, report.Failed, report.DryRun, report.Committed)
+
+	if report.Failed == 0 {
+		return nil
+	}
+
+	if *errorsPath == "" {
+		*errorsPath = path + ".errors.csv"
+	}
+	out, err := os.Create(*errorsPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if err := importer.WriteErrorCSV(out, table, report); err != nil {
+		return err
+	}
+	fmt.Printf("failed rows written to %s\n", *errorsPath)
+
+	if !report.DryRun {
+		return fmt.Errorf("import rejected: %d rows failed validation, nothing was written", report.Failed)
+	}
+	return nil
+}
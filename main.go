@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
+	"github.com/fitrinovs/achievement_system/app/authn"
+	"github.com/fitrinovs/achievement_system/app/cache"
+	"github.com/fitrinovs/achievement_system/app/importer"
 	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/policy"
 	"github.com/fitrinovs/achievement_system/app/repository"
 	"github.com/fitrinovs/achievement_system/app/service"
 	"github.com/fitrinovs/achievement_system/config"
@@ -63,13 +68,54 @@ func main() {
 		&model.Role{},
 		&model.Permission{},
 		&model.RolePermission{},
+		&model.SeededRolePermission{},
 		&model.User{},
+		&model.Faculty{},
+		&model.Department{},
+		&model.StudyProgram{},
+		&model.AcademicUnitAlias{},
 		&model.Lecturer{},
 		&model.Student{},
+		&model.AcademicPeriod{},
 		&model.AchievementReference{},
+		&model.LoginAttempt{},
+		&model.UserMFA{},
+		&model.MFARecoveryCode{},
+		&model.AuditLog{},
+		&model.VerificationDelegation{},
+		&model.APIKey{},
+		&model.UserIdentity{},
+		&model.UserSession{},
+		&model.AdvisorAssignment{},
+		&model.StudentStatusChange{},
 	)
+	database.MigrateCaseInsensitiveIdentity()
+	database.MigrateUniqueProfiles()
+	database.MigrateAdvisorHistory()
+	database.MigrateAcademicUnits()
+	if !model.IsValidPeriodAttribution(cfg.Period.AttributeBy) {
+		log.Fatal("❌ ACADEMIC_PERIOD_ATTRIBUTION must be one of event_date, submission_date")
+	}
+	database.MigrateAchievementPeriods(cfg.Period.AttributeBy)
 	logger.Info("✅ Database migration completed!")
 
+	// 5b. Seed katalog permission/role default + admin pertama.
+	// `go run . seed` menjalankan seeding saja lalu keluar.
+	seedOnly := len(os.Args) > 1 && os.Args[1] == "seed"
+	if cfg.Seed.SeedOnStartup || seedOnly {
+		logger.Info("Seeding permission catalog...")
+		if err := database.SeedCatalog(); err != nil {
+			log.Fatal("❌ Failed to seed permission catalog:", err)
+		}
+		if err := database.BootstrapAdmin(cfg.Seed); err != nil {
+			log.Fatal("❌ Failed to bootstrap admin:", err)
+		}
+		logger.Info("✅ Permission catalog is in sync!")
+	}
+	if seedOnly {
+		return
+	}
+
 	// 6. Create uploads directory
 	uploadPath := cfg.Upload.Path
 	if err := os.MkdirAll(uploadPath, 0755); err != nil {
@@ -88,25 +134,100 @@ func main() {
 	userRepo := repository.NewUserRepository(database.DB)
 	lecturerRepo := repository.NewLecturerRepository(database.DB)
 	studentRepo := repository.NewStudentRepository(database.DB)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(database.DB)
+	mfaRepo := repository.NewMFARepository(database.DB)
+	roleRepo := repository.NewRoleRepository(database.DB)
+	permissionRepo := repository.NewPermissionRepository(database.DB)
+	auditLogRepo := repository.NewAuditLogRepository(database.DB)
+	delegationRepo := repository.NewDelegationRepository(database.DB)
+	apiKeyRepo := repository.NewAPIKeyRepository(database.DB)
+	userIdentityRepo := repository.NewUserIdentityRepository(database.DB)
+	sessionRepo := repository.NewSessionRepository(database.DB)
+	provisioningRepo := repository.NewProvisioningRepository(database.DB)
+	advisorAssignmentRepo := repository.NewAdvisorAssignmentRepository(database.DB)
+	academicUnitRepo := repository.NewAcademicUnitRepository(database.DB)
+	academicPeriodRepo := repository.NewAcademicPeriodRepository(database.DB)
 	
 	// AchievementRepo memerlukan Gorm DB dan Mongo Database
 	achievementRepo := repository.NewAchievementRepository(database.DB, database.MongoDB)
+
+	// Import massal mahasiswa/dosen; dipakai endpoint import dan perintah CLI
+	// `go run . import <students|lecturers> <file> [-dry-run] [-upsert]`
+	studentImporter := importer.New(userRepo, studentRepo, lecturerRepo, roleRepo, provisioningRepo)
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImportCommand(os.Args[2:], studentImporter, cfg.Import.MaxRows); err != nil {
+			log.Fatal("❌ Import failed: ", err)
+		}
+		return
+	}
+
+	// Cache status user & permission role untuk AuthMiddleware
+	accessCache := cache.NewAccessCache(userRepo, roleRepo, sessionRepo, time.Duration(cfg.JWT.AccessCacheSeconds)*time.Second)
+
+	// Policy (ABAC) dipakai bersama oleh service achievement, student, dan report
+	enforcer := policy.NewEnforcer(studentRepo, lecturerRepo, delegationRepo)
 	
 	// B. Services
-	authService := service.NewAuthService(userRepo, studentRepo, lecturerRepo)
+	// Chain authenticator login password (local bcrypt, LDAP) per domain/role
+	authRouter, err := authn.NewRouter(cfg.AuthChain,
+		authn.NewLocalAuthenticator(),
+		authn.NewLDAPAuthenticator(cfg.LDAP, userRepo),
+	)
+	if err != nil {
+		log.Fatal("❌ Invalid authentication chain: ", err)
+	}
+
+	authService := service.NewAuthService(userRepo, studentRepo, lecturerRepo, loginAttemptRepo, mfaRepo, cfg.Login, cfg.MFA, authRouter, sessionRepo, accessCache)
+
+	mfaService := service.NewMFAService(mfaRepo, userRepo, sessionRepo, loginAttemptRepo, cfg.Login, cfg.MFA, accessCache)
+
+	roleService := service.NewRoleService(roleRepo, permissionRepo, userRepo, accessCache)
+	permissionService := service.NewPermissionService(permissionRepo, accessCache)
 	
-	studentService := service.NewStudentService(studentRepo, userRepo, lecturerRepo, achievementRepo) 
+	studentService := service.NewStudentService(studentRepo, userRepo, lecturerRepo, achievementRepo, enforcer, academicUnitRepo, academicPeriodRepo) 
 	
-	lecturerService := service.NewLecturerService(lecturerRepo, userRepo, studentRepo) 
+	lecturerService := service.NewLecturerService(lecturerRepo, userRepo, studentRepo, academicUnitRepo) 
 	
-	userService := service.NewUserService(userRepo) 
+	userService := service.NewUserService(userRepo, roleRepo, loginAttemptRepo, accessCache, lecturerRepo, studentRepo)
+
+	impersonationService := service.NewImpersonationService(userRepo, auditLogRepo, accessCache, cfg.Impersonation)
 
-	achievementService := service.NewAchievementService(achievementRepo, studentRepo, lecturerRepo)
+	delegationService := service.NewDelegationService(delegationRepo, lecturerRepo)
 
-	// ReportService hanya memerlukan AchievementRepo dan StudentRepo
+	serviceAccountService := service.NewServiceAccountService(userRepo, apiKeyRepo, accessCache)
+
+	sessionService := service.NewSessionService(sessionRepo, userRepo, accessCache)
+
+	importErrorFiles := importer.NewErrorFileStore(cfg.Import.ErrorDir, time.Duration(cfg.Import.ErrorFileHours)*time.Hour)
+	provisioningService := service.NewProvisioningService(userRepo, roleRepo, studentRepo, lecturerRepo, provisioningRepo, academicUnitRepo)
+
+	if !model.IsValidInFlightPolicy(cfg.Advisor.InFlightPolicy) {
+		log.Fatal("❌ ADVISOR_IN_FLIGHT_POLICY must be one of transfer, retain, reset")
+	}
+	advisorService := service.NewAdvisorService(studentRepo, lecturerRepo, advisorAssignmentRepo, enforcer, cfg.Advisor)
+
+	academicUnitService := service.NewAcademicUnitService(academicUnitRepo)
+
+	academicPeriodService := service.NewAcademicPeriodService(academicPeriodRepo, cfg.Period)
+
+	profileService := service.NewProfileService(userRepo, studentRepo, lecturerRepo, cfg.Upload)
+
+	importService := service.NewImportService(studentImporter, importErrorFiles, cfg.Import.MaxRows, cfg.Upload.MaxSize)
+
+	if cfg.OIDC.Enabled && (cfg.OIDC.IssuerURL == "" || cfg.OIDC.ClientID == "") {
+		log.Fatal("❌ OIDC_ENABLED=true requires OIDC_ISSUER_URL and OIDC_CLIENT_ID")
+	}
+	// SSO: cookie state hanya dikirim lewat HTTPS di production
+	oidcService := service.NewOIDCService(userRepo, studentRepo, lecturerRepo, roleRepo, mfaRepo, userIdentityRepo, sessionRepo, provisioningRepo, cfg.OIDC, cfg.MFA, cfg.Server.Env == "production")
+
+	achievementService := service.NewAchievementService(achievementRepo, studentRepo, lecturerRepo, enforcer, academicPeriodRepo, cfg.Period, cfg.Student)
+
+	// ReportService memerlukan AchievementRepo, StudentRepo, policy enforcer, dan PeriodRepo (filter periode)
 	reportService := service.NewReportService(
 		achievementRepo, 
 		studentRepo,     
+		enforcer,
+		academicPeriodRepo,
 	)
 
 	// ========================================================
@@ -118,6 +239,12 @@ func main() {
 
 	router := gin.Default()
 
+	// ClientIP dipakai limit login per IP dan allowlist IP API key: X-Forwarded-For
+	// hanya dipercaya bila koneksi datang dari proxy yang terdaftar di TRUSTED_PROXIES
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal("❌ Invalid TRUSTED_PROXIES: ", err)
+	}
+
 	// Panic Recovery Middleware
 	router.Use(func(c *gin.Context) {
 		defer func() {
@@ -151,16 +278,41 @@ func main() {
 	// 9. SETUP ROUTES
 	// ========================================================
 
+	routeRegistry := route.NewRouteRegistry()
 	route.SetupRoutes(
-		router, 
+		router,
+		routeRegistry,
+		middleware.AuthMiddleware(accessCache, apiKeyRepo, sessionRepo),
 		authService, 
 		studentService, 
 		lecturerService, 
 		userService, 
 		achievementService, 
 		reportService, 
+		mfaService,
+		roleService,
+		permissionService,
+		impersonationService,
+		delegationService,
+		serviceAccountService,
+		oidcService,
+		sessionService,
+		importService,
+		provisioningService,
+		advisorService,
+		academicUnitService,
+		academicPeriodService,
+		profileService,
+		auditLogRepo,
+		cfg.MFA.RequiredRoles,
+		cfg.Impersonation.AllowWrites,
 	)
 
+	// Gagal start bila ada route yang memakai permission di luar katalog (mis. salah ketik)
+	if err := routeRegistry.Validate(); err != nil {
+		log.Fatal("❌ Invalid route permissions: ", err)
+	}
+
 	// Serve static files
 	router.Static("/uploads", uploadPath)
 
@@ -6,14 +6,32 @@ import (
 	"fmt" // DIBUTUHKAN untuk error message yang lebih baik
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/fitrinovs/achievement_system/app/cache"
+	"github.com/fitrinovs/achievement_system/app/repository"
 	"github.com/fitrinovs/achievement_system/app/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
-// AuthMiddleware memeriksa validitas JWT token di header Authorization
-func AuthMiddleware() gin.HandlerFunc {
+// apiKeyTouchInterval / sessionTouchInterval membatasi seberapa sering waktu pemakaian terakhir ditulis ulang
+const (
+	apiKeyTouchInterval  = time.Minute
+	sessionTouchInterval = time.Minute
+)
+
+// AuthMiddleware memeriksa validitas JWT token di header Authorization,
+// memastikan pemilik token masih aktif dan security version token masih berlaku,
+// lalu me-resolve role & permission terkini dari cache (bukan dari claim token).
+// Service account dapat memakai API key lewat header X-API-Key atau "Authorization: ApiKey <key>".
+func AuthMiddleware(accessCache cache.AccessCache, apiKeyRepo repository.APIKeyRepository, sessionRepo repository.SessionRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := extractAPIKey(c); apiKey != "" {
+			authenticateAPIKey(c, accessCache, apiKeyRepo, apiKey)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
@@ -37,10 +55,174 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// PERBAIKAN: Gunakan "userID" agar konsisten dengan c.GetString("userID") di service layer
-		c.Set("userID", claims.UserID) 
-		c.Set("role", claims.Role)
-		c.Set("permissions", claims.Permissions)
+		state, err := accessCache.UserState(claims.UserID)
+		if err != nil || !state.IsActive {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User is inactive or no longer exists"})
+			c.Abort()
+			return
+		}
+
+		// Role diganti, user dinonaktifkan, atau password diubah setelah token terbit
+		if claims.SecurityVersion != state.SecurityVersion {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked, please log in again"})
+			c.Abort()
+			return
+		}
+
+		// Sesi dicabut (logout, revoke dari /auth/sessions atau oleh admin)
+		if claims.SessionID != nil {
+			session, err := accessCache.Session(*claims.SessionID)
+			if err != nil || session.UserID != claims.UserID || !session.IsActiveAt(time.Now()) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked, please log in again"})
+				c.Abort()
+				return
+			}
+			if err := sessionRepo.TouchLastSeen(session.ID, c.ClientIP(), time.Now(), sessionTouchInterval); err != nil {
+				fmt.Printf("Warning: failed to record activity of session %s: %v\n", session.ID, err)
+			}
+			c.Set("sessionID", session.ID.String())
+		}
+
+		roleAccess, err := accessCache.RoleAccess(state.RoleID)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User role no longer exists"})
+			c.Abort()
+			return
+		}
+
+		// Token impersonation: admin aslinya harus tetap aktif dan masih berhak impersonate
+		if claims.ImpersonatorID != nil {
+			if !canImpersonate(accessCache, *claims.ImpersonatorID) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Impersonation session is no longer valid"})
+				c.Abort()
+				return
+			}
+			c.Set("impersonatorID", claims.ImpersonatorID.String())
+			c.Set("impersonating", true)
+		}
+
+		// PERBAIKAN: Simpan sebagai string agar c.GetString("userID") di service layer berfungsi
+		c.Set("userID", claims.UserID.String())
+		c.Set("role", roleAccess.Name)
+		c.Set("permissions", roleAccess.Permissions)
+		c.Set("mfaVerified", claims.MFAVerified)
+		c.Set("authMethod", "jwt")
+
+		c.Next()
+	}
+}
+
+func extractAPIKey(c *gin.Context) string {
+	if key := strings.TrimSpace(c.GetHeader("X-API-Key")); key != "" {
+		return key
+	}
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) == 2 && strings.EqualFold(parts[0], "ApiKey") {
+		return strings.TrimSpace(parts[1])
+	}
+	return ""
+}
+
+// authenticateAPIKey memvalidasi API key service account. Permission efektif adalah
+// irisan scope key dengan permission role service account saat ini, sehingga mencabut
+// permission dari role juga langsung membatasi semua key-nya.
+func authenticateAPIKey(c *gin.Context, accessCache cache.AccessCache, apiKeyRepo repository.APIKeyRepository, rawKey string) {
+	key, err := apiKeyRepo.FindByHash(utils.HashAPIKey(rawKey))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return
+	}
+
+	now := time.Now()
+	if !key.IsUsableAt(now) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "API key has been revoked or has expired"})
+		c.Abort()
+		return
+	}
+
+	clientIP := c.ClientIP()
+	if !utils.IPAllowed(clientIP, key.AllowedIPs) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API key is not allowed from this IP address"})
+		c.Abort()
+		return
+	}
+
+	state, err := accessCache.UserState(key.ServiceAccountID)
+	if err != nil || !state.IsActive {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Service account is inactive or no longer exists"})
+		c.Abort()
+		return
+	}
+
+	roleAccess, err := accessCache.RoleAccess(state.RoleID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Service account role no longer exists"})
+		c.Abort()
+		return
+	}
+
+	if err := apiKeyRepo.TouchLastUsed(key.ID, clientIP, now, apiKeyTouchInterval); err != nil {
+		fmt.Printf("Warning: failed to record usage of api key %s: %v\n", key.ID, err)
+	}
+
+	c.Set("userID", key.ServiceAccountID.String())
+	c.Set("role", roleAccess.Name)
+	c.Set("permissions", intersectPermissions(roleAccess.Permissions, key.Scopes))
+	// Key tidak bisa melewati TOTP; MFA_REQUIRED_ROLES tidak berlaku untuk service account
+	c.Set("mfaVerified", true)
+	c.Set("authMethod", "api_key")
+	c.Set("apiKeyID", key.ID.String())
+
+	c.Next()
+}
+
+func intersectPermissions(granted, scopes []string) []string {
+	allowed := make(map[string]bool, len(scopes))
+	for _, s := range scopes {
+		allowed[s] = true
+	}
+	result := make([]string, 0, len(scopes))
+	for _, p := range granted {
+		if allowed[p] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func canImpersonate(accessCache cache.AccessCache, impersonatorID uuid.UUID) bool {
+	state, err := accessCache.UserState(impersonatorID)
+	if err != nil || !state.IsActive {
+		return false
+	}
+	roleAccess, err := accessCache.RoleAccess(state.RoleID)
+	if err != nil {
+		return false
+	}
+	for _, p := range roleAccess.Permissions {
+		if p == "user:impersonate" {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireMFA menolak request dari role yang wajib MFA bila token belum melewati verifikasi TOTP.
+// Endpoint enrollment (/auth/mfa/*) sengaja didaftarkan di luar middleware ini.
+func RequireMFA(requiredRoles []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, r := range requiredRoles {
+			if strings.EqualFold(r, role) && !c.GetBool("mfaVerified") {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":   "Multi-factor authentication is required for your role",
+					"details": "Enroll via POST /api/v1/auth/mfa/enroll, then log in again",
+				})
+				c.Abort()
+				return
+			}
+		}
 
 		c.Next()
 	}
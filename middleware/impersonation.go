@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/app/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ImpersonationGuard dipasang setelah AuthMiddleware. Selama impersonation:
+// request tulis (selain GET/HEAD/OPTIONS) ditolak kecuali allowWrites, dan
+// setiap request dicatat ke audit log dengan identitas admin asli dan user yang ditiru.
+func ImpersonationGuard(auditRepo repository.AuditLogRepository, allowWrites bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool("impersonating") {
+			c.Next()
+			return
+		}
+
+		if !allowWrites && !isReadOnlyMethod(c.Request.Method) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Write operations are disabled while impersonating",
+				"details": "Log in as yourself to make changes",
+			})
+			c.Abort()
+		} else {
+			c.Next()
+		}
+
+		actorID, _ := uuid.Parse(c.GetString("impersonatorID"))
+		subjectID, _ := uuid.Parse(c.GetString("userID"))
+		entry := &model.AuditLog{
+			Action:        model.AuditActionImpersonationRequest,
+			ActorID:       actorID,
+			SubjectUserID: &subjectID,
+			Method:        c.Request.Method,
+			Path:          c.Request.URL.Path,
+			StatusCode:    c.Writer.Status(),
+			IPAddress:     c.ClientIP(),
+		}
+		if err := auditRepo.Create(entry); err != nil {
+			fmt.Printf("Warning: failed to write impersonation audit log: %v\n", err)
+		}
+	}
+}
+
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
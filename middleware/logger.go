@@ -14,12 +14,23 @@ func LoggerMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 		c.Next()
 
 		duration := time.Since(startTime)
-		logger.WithFields(logrus.Fields{
+		fields := logrus.Fields{
 			"method":   c.Request.Method,
 			"path":     c.Request.URL.Path,
 			"status":   c.Writer.Status(),
 			"duration": duration.String(),
 			"ip":       c.ClientIP(),
-		}).Info("HTTP Request")
+		}
+		// Identitas diisi AuthMiddleware; saat impersonation kedua identitas ikut dicatat
+		if userID := c.GetString("userID"); userID != "" {
+			fields["user_id"] = userID
+		}
+		if impersonatorID := c.GetString("impersonatorID"); impersonatorID != "" {
+			fields["impersonator_id"] = impersonatorID
+		}
+		if apiKeyID := c.GetString("apiKeyID"); apiKeyID != "" {
+			fields["api_key_id"] = apiKeyID
+		}
+		logger.WithFields(fields).Info("HTTP Request")
 	}
 }
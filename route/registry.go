@@ -0,0 +1,133 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/fitrinovs/achievement_system/app/model"
+	"github.com/fitrinovs/achievement_system/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteMeta adalah metadata satu endpoint: method, path lengkap, dan permission yang menjaganya
+type RouteMeta struct {
+	Method        string   `json:"method"`
+	Path          string   `json:"path"`
+	Authenticated bool     `json:"authenticated"`
+	Permissions   []string `json:"permissions"` // OR logic, kosong = cukup login (atau publik)
+}
+
+// RouteRegistry mencatat setiap route yang didaftarkan lewat routeGroup
+type RouteRegistry struct {
+	routes []RouteMeta
+	// wrapHandler (opsional) membungkus handler setiap route sebelum didaftarkan;
+	// test route memakainya untuk mengganti handler dengan stub
+	wrapHandler func(gin.HandlerFunc) gin.HandlerFunc
+}
+
+func NewRouteRegistry() *RouteRegistry {
+	return &RouteRegistry{}
+}
+
+// Routes mengembalikan salinan metadata route, diurutkan berdasarkan path lalu method
+func (reg *RouteRegistry) Routes() []RouteMeta {
+	routes := make([]RouteMeta, len(reg.routes))
+	copy(routes, reg.routes)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Validate memastikan semua permission yang direferensikan route ada di katalog.
+// Salah ketik di string permission akan mengunci semua user, jadi server harus gagal start.
+func (reg *RouteRegistry) Validate() error {
+	var problems []string
+	for _, rt := range reg.routes {
+		for _, p := range rt.Permissions {
+			if !model.IsCatalogPermission(p) {
+				problems = append(problems, fmt.Sprintf("%s %s -> %q", rt.Method, rt.Path, p))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("routes reference permissions missing from the catalog: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ListRoutes godoc
+// @Summary      List API Routes
+// @Description  Daftar semua endpoint beserta permission yang dibutuhkan
+// @Tags         Admin
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} object{status=string,data=[]route.RouteMeta}
+// @Router       /admin/routes [get]
+func (reg *RouteRegistry) ListRoutes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": reg.Routes()})
+}
+
+// routeGroup membungkus gin.RouterGroup sehingga setiap route tercatat di registry
+// dan otomatis dijaga middleware.CheckPermission bila permission diberikan.
+type routeGroup struct {
+	group         *gin.RouterGroup
+	registry      *RouteRegistry
+	authenticated bool
+}
+
+func (reg *RouteRegistry) wrap(group *gin.RouterGroup, authenticated bool) *routeGroup {
+	return &routeGroup{group: group, registry: reg, authenticated: authenticated}
+}
+
+// Group membuat sub-group dengan registry dan status autentikasi yang sama
+func (g *routeGroup) Group(relativePath string) *routeGroup {
+	return &routeGroup{group: g.group.Group(relativePath), registry: g.registry, authenticated: g.authenticated}
+}
+
+func (g *routeGroup) handle(method, relativePath string, handler gin.HandlerFunc, permissions []string) {
+	if g.registry.wrapHandler != nil {
+		handler = g.registry.wrapHandler(handler)
+	}
+	handlers := []gin.HandlerFunc{handler}
+	if len(permissions) > 0 {
+		handlers = []gin.HandlerFunc{middleware.CheckPermission(permissions...), handler}
+	}
+	g.group.Handle(method, relativePath, handlers...)
+
+	g.registry.routes = append(g.registry.routes, RouteMeta{
+		Method:        method,
+		Path:          joinPaths(g.group.BasePath(), relativePath),
+		Authenticated: g.authenticated,
+		Permissions:   append([]string{}, permissions...),
+	})
+}
+
+func (g *routeGroup) GET(relativePath string, handler gin.HandlerFunc, permissions ...string) {
+	g.handle(http.MethodGet, relativePath, handler, permissions)
+}
+
+func (g *routeGroup) POST(relativePath string, handler gin.HandlerFunc, permissions ...string) {
+	g.handle(http.MethodPost, relativePath, handler, permissions)
+}
+
+func (g *routeGroup) PUT(relativePath string, handler gin.HandlerFunc, permissions ...string) {
+	g.handle(http.MethodPut, relativePath, handler, permissions)
+}
+
+func (g *routeGroup) DELETE(relativePath string, handler gin.HandlerFunc, permissions ...string) {
+	g.handle(http.MethodDelete, relativePath, handler, permissions)
+}
+
+func joinPaths(base, relative string) string {
+	if relative == "" {
+		return base
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(relative, "/")
+}
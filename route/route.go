@@ -1,6 +1,7 @@
 package route
 
 import (
+	"github.com/fitrinovs/achievement_system/app/repository"
 	"github.com/fitrinovs/achievement_system/app/service"
 	"github.com/fitrinovs/achievement_system/middleware"
 
@@ -12,13 +13,34 @@ import (
 // FIX: Menambahkan reportService sebagai parameter
 func SetupRoutes(
 	r *gin.Engine,
+	registry *RouteRegistry, // Mencatat setiap route beserta permission-nya untuk /admin/routes
+	authMiddleware gin.HandlerFunc, // JWT atau API key service account (di main: middleware.AuthMiddleware)
 	authService service.AuthService,
 	studentService service.StudentService,
 	lecturerService service.LecturerService,
 	userService service.UserService,
 	achievementService service.AchievementService,
 	reportService service.ReportService, // PARAMETER BARU: ReportService
+	mfaService service.MFAService,
+	roleService service.RoleService,
+	permissionService service.PermissionService,
+	impersonationService service.ImpersonationService,
+	delegationService service.DelegationService,
+	serviceAccountService service.ServiceAccountService,
+	oidcService service.OIDCService,
+	sessionService service.SessionService,
+	importService service.ImportService,
+	provisioningService service.ProvisioningService,
+	advisorService service.AdvisorService,
+	academicUnitService service.AcademicUnitService,
+	academicPeriodService service.AcademicPeriodService,
+	profileService service.ProfileService,
+	auditRepo repository.AuditLogRepository, // Dipakai ImpersonationGuard untuk audit trail
+	mfaRequiredRoles []string,
+	impersonationAllowWrites bool,
 ) {
+	// Semua route didaftarkan lewat registry: group.METHOD(path, handler, permission...).
+	// Permission (OR logic) dipasang sebagai CheckPermission dan tercatat untuk /admin/routes.
 
 	// =========================
 	// SWAGGER
@@ -26,128 +48,307 @@ func SetupRoutes(
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	api := r.Group("/api/v1")
+	public := registry.wrap(api, false)
 
 	// =========================
 	// AUTH (PUBLIC)
 	// =========================
-	authPublic := api.Group("/auth")
+	authPublic := public.Group("/auth")
 	{
 		authPublic.POST("/login", authService.Login)
 		authPublic.POST("/refresh", authService.RefreshToken)
+		authPublic.POST("/mfa/verify", authService.VerifyMFA)
+
+		// SSO via identity provider kampus (OIDC authorization code + PKCE)
+		authPublic.GET("/oidc/login", oidcService.OIDCLogin)
+		authPublic.GET("/oidc/callback", oidcService.OIDCCallback)
 	}
 
 	// =========================
 	// AUTHENTICATED ROUTES
 	// =========================
 	protected := api.Group("")
-	// Middleware autentikasi
-	protected.Use(middleware.AuthMiddleware()) 
+	// Middleware autentikasi (JWT atau API key service account)
+	protected.Use(authMiddleware)
+	// Blokir request tulis & catat audit selama sesi impersonation
+	protected.Use(middleware.ImpersonationGuard(auditRepo, impersonationAllowWrites))
+	authenticated := registry.wrap(protected, true)
 
 	// =========================
 	// AUTH (PROTECTED)
 	// =========================
-	authProtected := protected.Group("/auth")
+	authProtected := authenticated.Group("/auth")
 	{
 		authProtected.GET("/profile", authService.GetProfile)
 		authProtected.POST("/logout", authService.Logout)
+
+		// Sesi login (perangkat) milik user sendiri
+		authProtected.GET("/sessions", sessionService.GetMySessions)
+		authProtected.DELETE("/sessions", sessionService.RevokeMyOtherSessions)
+		authProtected.DELETE("/sessions/:id", sessionService.RevokeMySession)
+
+		// MFA enrollment tetap bisa diakses walau MFA wajib tapi belum aktif
+		authProtected.GET("/mfa", mfaService.GetMFAStatus)
+		authProtected.POST("/mfa/enroll", mfaService.EnrollMFA)
+		authProtected.POST("/mfa/enable", mfaService.EnableMFA)
+		authProtected.POST("/mfa/disable", mfaService.DisableMFA)
+		authProtected.POST("/mfa/recovery-codes", mfaService.RegenerateRecoveryCodes)
+	}
+
+	// =========================
+	// ROUTES YANG WAJIB MFA (untuk role di MFA_REQUIRED_ROLES)
+	// =========================
+	secured := protected.Group("")
+	secured.Use(middleware.RequireMFA(mfaRequiredRoles))
+	guarded := registry.wrap(secured, true)
+
+	// =========================
+	// SELF-SERVICE PROFILE (tanpa permission: selalu milik user yang login)
+	// =========================
+	meGroup := guarded.Group("/me")
+	{
+		meGroup.GET("", profileService.GetMyProfile)
+		meGroup.PUT("", profileService.UpdateMyProfile)
+		meGroup.POST("/photo", profileService.UploadMyPhoto)
+		meGroup.DELETE("/photo", profileService.DeleteMyPhoto)
+	}
+
+	// =========================
+	// ADMIN: ROUTE INTROSPECTION, IMPERSONATION & AUDIT
+	// =========================
+	adminGroup := guarded.Group("/admin")
+	{
+		adminGroup.GET("/routes", registry.ListRoutes, "route:read")
+		adminGroup.POST("/impersonate/:id", impersonationService.StartImpersonation, "user:impersonate")
+		adminGroup.GET("/audit-logs", impersonationService.GetAuditLogs, "audit:read")
 	}
 
 	// =========================
 	// USERS (ADMIN)
 	// =========================
-	userGroup := protected.Group("/users")
+	userGroup := guarded.Group("/users")
 	{
-		userGroup.GET("", userService.GetAllUsers)
-		userGroup.POST("", userService.CreateUser)
-		userGroup.GET("/:id", userService.GetUserByID)
-		userGroup.PUT("/:id", userService.UpdateUser)
-		userGroup.PUT("/:id/role", userService.UpdateUserRole)
-		userGroup.DELETE("/:id", userService.DeleteUser)
+		userGroup.GET("", userService.GetAllUsers, "user:read")
+		userGroup.POST("", userService.CreateUser, "user:create")
+		userGroup.POST("/provision", provisioningService.ProvisionUser, "user:provision")
+		userGroup.GET("/:id", userService.GetUserByID, "user:read")
+		userGroup.PUT("/:id", userService.UpdateUser, "user:update")
+		userGroup.PUT("/:id/role", userService.UpdateUserRole, "user:assign_role")
+		userGroup.POST("/:id/unlock", userService.UnlockUser, "user:manage_security")
+		userGroup.PUT("/:id/password", userService.ResetUserPassword, "user:manage_security")
+		userGroup.DELETE("/:id/mfa", mfaService.ResetUserMFA, "user:manage_security")
+		userGroup.GET("/:id/sessions", sessionService.GetUserSessions, "user:manage_security")
+		userGroup.DELETE("/:id/sessions", sessionService.RevokeAllUserSessions, "user:manage_security")
+		userGroup.DELETE("/:id/sessions/:sessionId", sessionService.RevokeUserSession, "user:manage_security")
+		userGroup.POST("/:id/deactivate", userService.DeactivateUser, "user:deactivate")
+		userGroup.POST("/:id/reactivate", userService.ReactivateUser, "user:deactivate")
+		userGroup.DELETE("/:id", userService.DeleteUser, "user:delete")
+	}
+
+	// =================================================
+	// SERVICE ACCOUNTS & API KEYS (ADMIN)
+	// =================================================
+	serviceAccountGroup := guarded.Group("/service-accounts")
+	{
+		serviceAccountGroup.GET("", serviceAccountService.GetServiceAccounts, "service_account:manage")
+		serviceAccountGroup.POST("", serviceAccountService.CreateServiceAccount, "service_account:manage")
+		serviceAccountGroup.GET("/:id/keys", serviceAccountService.GetAPIKeys, "service_account:manage")
+		serviceAccountGroup.POST("/:id/keys", serviceAccountService.CreateAPIKey, "service_account:manage")
+		serviceAccountGroup.DELETE("/:id/keys/:keyId", serviceAccountService.RevokeAPIKey, "service_account:manage")
+	}
+
+	// =================================================
+	// ROLES & PERMISSIONS (ADMIN)
+	// =================================================
+	roleGroup := guarded.Group("/roles")
+	{
+		roleGroup.GET("", roleService.GetAllRoles, "role:read", "role:manage")
+		roleGroup.POST("", roleService.CreateRole, "role:manage")
+		roleGroup.GET("/:id", roleService.GetRoleByID, "role:read", "role:manage")
+		roleGroup.PUT("/:id", roleService.UpdateRole, "role:manage")
+		roleGroup.DELETE("/:id", roleService.DeleteRole, "role:manage")
+		roleGroup.PUT("/:id/permissions", roleService.AssignRolePermissions, "role:manage")
+		roleGroup.GET("/:id/users", roleService.GetRoleUsers, "role:read", "role:manage")
+	}
+
+	permissionGroup := guarded.Group("/permissions")
+	{
+		permissionGroup.GET("", permissionService.GetAllPermissions, "permission:read", "permission:manage")
+		permissionGroup.POST("", permissionService.CreatePermission, "permission:manage")
+		permissionGroup.GET("/:id", permissionService.GetPermissionByID, "permission:read", "permission:manage")
+		permissionGroup.PUT("/:id", permissionService.UpdatePermission, "permission:manage")
+		permissionGroup.DELETE("/:id", permissionService.DeletePermission, "permission:manage")
 	}
 
 	// =================================================
 	// STUDENTS
 	// =================================================
-	studentGroup := protected.Group("/students")
+	studentGroup := guarded.Group("/students")
 	{
 		// CRUD
-		studentGroup.POST("", studentService.CreateStudent)
-		studentGroup.GET("", studentService.GetAllStudents)
-		studentGroup.GET("/:id", studentService.GetStudentByID)
-		studentGroup.PUT("/:id", studentService.UpdateStudent)
-		studentGroup.DELETE("/:id", studentService.DeleteStudent)
+		studentGroup.POST("", studentService.CreateStudent, "student:create")
+		studentGroup.POST("/import", importService.ImportStudents, "student:import")
+		studentGroup.GET("", studentService.GetAllStudents, "student:read")
+		studentGroup.GET("/:id", studentService.GetStudentByID, "student:read")
+		studentGroup.PUT("/:id", studentService.UpdateStudent, "student:update")
+		studentGroup.DELETE("/:id", studentService.DeleteStudent, "student:delete")
 
 		// SRS
-		studentGroup.PUT("/:id/advisor", studentService.AssignAdvisor)
-		studentGroup.GET("/:id/achievements", studentService.GetAchievementsByStudentID)
+		studentGroup.PUT("/:id/advisor", advisorService.AssignAdvisor, "student:assign_advisor")
+		studentGroup.GET("/:id/advisor-history", advisorService.GetAdvisorHistory, "student:read")
+		studentGroup.PUT("/:id/status", studentService.ChangeStudentStatus, "student:manage_status")
+		studentGroup.GET("/:id/status-history", studentService.GetStudentStatusHistory, "student:read")
+		studentGroup.GET("/:id/achievements", studentService.GetAchievementsByStudentID, "achievement:read_own", "achievement:read_list")
+	}
+
+	// =================================================
+	// ADVISOR ASSIGNMENTS
+	// =================================================
+	advisorGroup := guarded.Group("/advisor-assignments")
+	{
+		advisorGroup.POST("/bulk", advisorService.BulkAssignAdvisors, "student:assign_advisor")
+		advisorGroup.POST("/proposals", advisorService.ProposeAdvisors, "student:assign_advisor")
+		advisorGroup.POST("/proposals/apply", advisorService.ApplyAdvisorProposal, "student:assign_advisor")
+	}
+
+	// =================================================
+	// ACADEMIC UNITS (master data fakultas/departemen/program studi)
+	// =================================================
+	facultyGroup := guarded.Group("/faculties")
+	{
+		facultyGroup.GET("", academicUnitService.GetFaculties, "academic_unit:read", "academic_unit:manage")
+		facultyGroup.POST("", academicUnitService.CreateFaculty, "academic_unit:manage")
+		facultyGroup.GET("/:id", academicUnitService.GetFacultyByID, "academic_unit:read", "academic_unit:manage")
+		facultyGroup.PUT("/:id", academicUnitService.UpdateFaculty, "academic_unit:manage")
+		facultyGroup.DELETE("/:id", academicUnitService.DeleteFaculty, "academic_unit:manage")
+	}
+
+	departmentGroup := guarded.Group("/departments")
+	{
+		departmentGroup.GET("", academicUnitService.GetDepartments, "academic_unit:read", "academic_unit:manage")
+		departmentGroup.POST("", academicUnitService.CreateDepartment, "academic_unit:manage")
+		departmentGroup.GET("/:id", academicUnitService.GetDepartmentByID, "academic_unit:read", "academic_unit:manage")
+		departmentGroup.PUT("/:id", academicUnitService.UpdateDepartment, "academic_unit:manage")
+		departmentGroup.DELETE("/:id", academicUnitService.DeleteDepartment, "academic_unit:manage")
+	}
+
+	studyProgramGroup := guarded.Group("/study-programs")
+	{
+		studyProgramGroup.GET("", academicUnitService.GetStudyPrograms, "academic_unit:read", "academic_unit:manage")
+		studyProgramGroup.POST("", academicUnitService.CreateStudyProgram, "academic_unit:manage")
+		studyProgramGroup.GET("/:id", academicUnitService.GetStudyProgramByID, "academic_unit:read", "academic_unit:manage")
+		studyProgramGroup.PUT("/:id", academicUnitService.UpdateStudyProgram, "academic_unit:manage")
+		studyProgramGroup.DELETE("/:id", academicUnitService.DeleteStudyProgram, "academic_unit:manage")
+	}
+
+	academicUnitGroup := guarded.Group("/academic-units")
+	{
+		academicUnitGroup.GET("/aliases", academicUnitService.GetAliases, "academic_unit:read", "academic_unit:manage")
+		academicUnitGroup.POST("/aliases", academicUnitService.CreateAlias, "academic_unit:manage")
+		academicUnitGroup.DELETE("/aliases/:id", academicUnitService.DeleteAlias, "academic_unit:manage")
+		academicUnitGroup.GET("/mapping", academicUnitService.GetMappingReport, "academic_unit:manage")
+		academicUnitGroup.POST("/mapping", academicUnitService.ApplyMapping, "academic_unit:manage")
+	}
+
+	// =================================================
+	// ACADEMIC PERIODS (semester Ganjil/Genap)
+	// =================================================
+	periodGroup := guarded.Group("/academic-periods")
+	{
+		periodGroup.GET("", academicPeriodService.GetAcademicPeriods, "academic_period:read", "academic_period:manage")
+		periodGroup.POST("", academicPeriodService.CreateAcademicPeriod, "academic_period:manage")
+		periodGroup.GET("/active", academicPeriodService.GetActiveAcademicPeriod, "academic_period:read", "academic_period:manage")
+		periodGroup.GET("/:id", academicPeriodService.GetAcademicPeriodByID, "academic_period:read", "academic_period:manage")
+		periodGroup.PUT("/:id", academicPeriodService.UpdateAcademicPeriod, "academic_period:manage")
+		periodGroup.DELETE("/:id", academicPeriodService.DeleteAcademicPeriod, "academic_period:manage")
+		periodGroup.POST("/:id/activate", academicPeriodService.ActivateAcademicPeriod, "academic_period:manage")
 	}
 
 	// =================================================
 	// LECTURERS
 	// =================================================
-	lecturerGroup := protected.Group("/lecturers")
+	lecturerGroup := guarded.Group("/lecturers")
 	{
 		// CRUD
-		lecturerGroup.POST("", lecturerService.CreateLecturer)
-		lecturerGroup.GET("", lecturerService.GetAllLecturers)
-		lecturerGroup.GET("/:id", lecturerService.GetLecturerByID)
-		lecturerGroup.PUT("/:id", lecturerService.UpdateLecturer)
-		lecturerGroup.DELETE("/:id", lecturerService.DeleteLecturer)
+		lecturerGroup.POST("", lecturerService.CreateLecturer, "lecturer:create")
+		lecturerGroup.POST("/import", importService.ImportLecturers, "lecturer:import")
+		lecturerGroup.GET("", lecturerService.GetAllLecturers, "lecturer:read")
+		lecturerGroup.GET("/:id", lecturerService.GetLecturerByID, "lecturer:read")
+		lecturerGroup.PUT("/:id", lecturerService.UpdateLecturer, "lecturer:update")
+		lecturerGroup.DELETE("/:id", lecturerService.DeleteLecturer, "lecturer:delete")
 
 		// SRS
-		lecturerGroup.GET("/:id/advisees", lecturerService.GetAdviseesByLecturerID)
+		lecturerGroup.GET("/:id/advisees", lecturerService.GetAdviseesByLecturerID, "lecturer:read_advisees")
+	}
+
+	// =================================================
+	// IMPORTS (file baris gagal dari import massal)
+	// =================================================
+	importGroup := guarded.Group("/imports")
+	{
+		importGroup.GET("/errors/:id", importService.DownloadErrorFile, "student:import", "lecturer:import")
+	}
+
+	// =================================================
+	// VERIFICATION DELEGATIONS (Dosen Wali cuti)
+	// =================================================
+	delegationGroup := guarded.Group("/delegations")
+	{
+		delegationGroup.GET("", delegationService.GetDelegations, "delegation:manage")
+		delegationGroup.POST("", delegationService.CreateDelegation, "delegation:manage")
+		delegationGroup.DELETE("/:id", delegationService.RevokeDelegation, "delegation:manage")
 	}
 
 	// =================================================
 	// ACHIEVEMENTS
 	// =================================================
-	achievementGroup := protected.Group("/achievements")
+	achievementGroup := guarded.Group("/achievements")
 	{
 		// 1. GET /api/v1/achievements (List)
-		achievementGroup.GET("/", middleware.CheckPermission("achievement:read_own", "achievement:read_list"), achievementService.GetAchievementsList)
+		achievementGroup.GET("/", achievementService.GetAchievementsList, "achievement:read_own", "achievement:read_list")
 
 		// 2. GET /api/v1/achievements/:id (Detail)
-		achievementGroup.GET("/:id", middleware.CheckPermission("achievement:read_own", "achievement:read_list"), achievementService.GetAchievementByID)
+		achievementGroup.GET("/:id", achievementService.GetAchievementByID, "achievement:read_own", "achievement:read_list")
 
 		// 3. POST /api/v1/achievements (Create) - Mahasiswa
-		achievementGroup.POST("/", middleware.CheckPermission("achievement:create"), achievementService.CreateAchievement)
+		achievementGroup.POST("/", achievementService.CreateAchievement, "achievement:create")
 
 		// 4. PUT /api/v1/achievements/:id (Update) - Mahasiswa
-		achievementGroup.PUT("/:id", middleware.CheckPermission("achievement:update"), achievementService.UpdateAchievement)
+		achievementGroup.PUT("/:id", achievementService.UpdateAchievement, "achievement:update")
 
 		// 5. DELETE /api/v1/achievements/:id (Delete) - Mahasiswa
-		achievementGroup.DELETE("/:id", middleware.CheckPermission("achievement:delete"), achievementService.DeleteAchievement)
+		achievementGroup.DELETE("/:id", achievementService.DeleteAchievement, "achievement:delete")
 
 		// 6. POST /api/v1/achievements/:id/submit - Mahasiswa
-		achievementGroup.POST("/:id/submit", middleware.CheckPermission("achievement:submit"), achievementService.SubmitAchievement)
+		achievementGroup.POST("/:id/submit", achievementService.SubmitAchievement, "achievement:submit")
 
 		// 7. POST /api/v1/achievements/:id/verify - Dosen Wali/Admin
-		achievementGroup.POST("/:id/verify", middleware.CheckPermission("achievement:verify"), achievementService.VerifyAchievement)
+		achievementGroup.POST("/:id/verify", achievementService.VerifyAchievement, "achievement:verify")
 
 		// 8. POST /api/v1/achievements/:id/reject - Dosen Wali/Admin
-		achievementGroup.POST("/:id/reject", middleware.CheckPermission("achievement:reject"), achievementService.RejectAchievement)
+		achievementGroup.POST("/:id/reject", achievementService.RejectAchievement, "achievement:reject")
 
 		// 9. GET /api/v1/achievements/:id/history
-		achievementGroup.GET("/:id/history", middleware.CheckPermission("achievement:read_history"), achievementService.GetAchievementHistory)
+		achievementGroup.GET("/:id/history", achievementService.GetAchievementHistory, "achievement:read_history")
 		
 		// 10. POST /api/v1/achievements/:id/attachments - Mahasiswa
-		achievementGroup.POST("/:id/attachments", middleware.CheckPermission("achievement:upload_attachment"), achievementService.UploadAttachment)
+		achievementGroup.POST("/:id/attachments", achievementService.UploadAttachment, "achievement:upload_attachment")
 	}
 
 	// =================================================
 	// 8. REPORTS & ANALYTICS
 	// =================================================
-	SetupReportRoutes(protected, reportService)
+	SetupReportRoutes(guarded, reportService)
 }
 
 // Helper function untuk mendaftarkan route report di grup protected
-func SetupReportRoutes(router *gin.RouterGroup, reportService service.ReportService) {
+func SetupReportRoutes(router *routeGroup, reportService service.ReportService) {
 	reports := router.Group("/reports")
 	{
 		// GET /api/v1/reports/statistics
-		reports.GET("/statistics", reportService.GetAchievementStatistics)
+		reports.GET("/statistics", reportService.GetAchievementStatistics, "report:read_statistics")
 		
 		// GET /api/v1/reports/student/:id
-		reports.GET("/student/:id", reportService.GetStudentAchievementReport)
+		reports.GET("/student/:id", reportService.GetStudentAchievementReport, "report:read_student", "achievement:read_own")
 	}
 }
\ No newline at end of file
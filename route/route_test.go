@@ -0,0 +1,146 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fitrinovs/achievement_system/app/importer"
+	"github.com/fitrinovs/achievement_system/app/policy"
+	"github.com/fitrinovs/achievement_system/app/service"
+	"github.com/fitrinovs/achievement_system/config"
+	"github.com/gin-gonic/gin"
+)
+
+// testPermissionsHeader dibaca stub AuthMiddleware sebagai daftar permission user (dipisah koma)
+const testPermissionsHeader = "X-Test-Permissions"
+
+const testPathParam = "3f1c2d4e-5a6b-4c7d-8e9f-0a1b2c3d4e5f"
+
+// setupTestRouter membangun router lengkap dengan AuthMiddleware stub dan handler pengganti,
+// sehingga status yang diterima hanya ditentukan oleh middleware permission
+func setupTestRouter(t *testing.T) (*gin.Engine, *RouteRegistry) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	authMiddleware := func(c *gin.Context) {
+		permissions := []string{}
+		if raw := c.GetHeader(testPermissionsHeader); raw != "" {
+			permissions = strings.Split(raw, ",")
+		}
+		c.Set("userID", testPathParam)
+		c.Set("role", "Tester")
+		c.Set("permissions", permissions)
+		c.Next()
+	}
+	registry := NewRouteRegistry()
+	registry.wrapHandler = func(gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) { c.Status(http.StatusOK) }
+	}
+
+	enforcer := policy.NewEnforcer(nil, nil, nil)
+	r := gin.New()
+	SetupRoutes(
+		r,
+		registry,
+		authMiddleware,
+		service.NewAuthService(nil, nil, nil, nil, nil, config.LoginSecurityConfig{}, config.MFAConfig{}, nil, nil, nil),
+		service.NewStudentService(nil, nil, nil, nil, enforcer, nil, nil),
+		service.NewLecturerService(nil, nil, nil, nil),
+		service.NewUserService(nil, nil, nil, nil, nil, nil),
+		service.NewAchievementService(nil, nil, nil, enforcer, nil, config.AcademicPeriodConfig{}, config.StudentConfig{}),
+		service.NewReportService(nil, nil, enforcer, nil),
+		service.NewMFAService(nil, nil, nil, nil, config.LoginSecurityConfig{}, config.MFAConfig{}, nil),
+		service.NewRoleService(nil, nil, nil, nil),
+		service.NewPermissionService(nil, nil),
+		service.NewImpersonationService(nil, nil, nil, config.ImpersonationConfig{}),
+		service.NewDelegationService(nil, nil),
+		service.NewServiceAccountService(nil, nil, nil),
+		service.NewOIDCService(nil, nil, nil, nil, nil, nil, nil, nil, config.OIDCConfig{}, config.MFAConfig{}, false),
+		service.NewSessionService(nil, nil, nil),
+		service.NewImportService(&importer.Importer{}, importer.NewErrorFileStore(t.TempDir(), 0), 0, 0),
+		service.NewProvisioningService(nil, nil, nil, nil, nil, nil),
+		service.NewAdvisorService(nil, nil, nil, enforcer, config.AdvisorConfig{}),
+		service.NewAcademicUnitService(nil),
+		service.NewAcademicPeriodService(nil, config.AcademicPeriodConfig{}),
+		service.NewProfileService(nil, nil, nil, config.UploadConfig{}),
+		nil,
+		nil,
+		false,
+	)
+	return r, registry
+}
+
+// concretePath mengisi parameter path (:id, *any) agar route bisa dipanggil langsung
+func concretePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			segments[i] = testPathParam
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func serveRoute(r *gin.Engine, rt RouteMeta, permissions string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(rt.Method, concretePath(rt.Path), nil)
+	if permissions != "" {
+		req.Header.Set(testPermissionsHeader, permissions)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRoutesWithPermissionRejectUserWithoutPermission(t *testing.T) {
+	r, registry := setupTestRouter(t)
+
+	checked := 0
+	for _, rt := range registry.Routes() {
+		if len(rt.Permissions) == 0 {
+			continue
+		}
+		checked++
+		if w := serveRoute(r, rt, ""); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s without permission: got %d, want %d", rt.Method, rt.Path, w.Code, http.StatusForbidden)
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no permission-guarded routes registered")
+	}
+}
+
+func TestRoutesWithPermissionAllowEachRequiredPermission(t *testing.T) {
+	r, registry := setupTestRouter(t)
+
+	for _, rt := range registry.Routes() {
+		// Permission route bersifat OR: setiap permission saja sudah cukup
+		for _, perm := range rt.Permissions {
+			if w := serveRoute(r, rt, perm); w.Code != http.StatusOK {
+				t.Errorf("%s %s with %q: got %d, want %d", rt.Method, rt.Path, perm, w.Code, http.StatusOK)
+			}
+		}
+	}
+}
+
+func TestRoutesWithPermissionRejectUnrelatedPermission(t *testing.T) {
+	r, registry := setupTestRouter(t)
+
+	for _, rt := range registry.Routes() {
+		if len(rt.Permissions) == 0 {
+			continue
+		}
+		if w := serveRoute(r, rt, "unrelated:permission"); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s with unrelated permission: got %d, want %d", rt.Method, rt.Path, w.Code, http.StatusForbidden)
+		}
+	}
+}
+
+func TestRoutesReferenceCatalogPermissions(t *testing.T) {
+	_, registry := setupTestRouter(t)
+
+	if err := registry.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}